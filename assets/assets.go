@@ -0,0 +1,73 @@
+// Ressources du jeu (images, cartes, jeux de tiles), intégrées au programme pour qu'il fonctionne
+// quel que soit le dossier depuis lequel il est lancé
+package assets
+
+import (
+	"embed"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Préfixe des chemins des ressources, relatifs à la racine du projet
+const Prefix = "assets/"
+
+//go:embed images/*.png objects/*.png maps/*.json maps/tilesets/*.json
+var embedded embed.FS
+
+// Fonction qui renvoie le système de fichiers et le nom sous lesquels lire un fichier: le disque si le chemin y existe,
+// sinon les ressources intégrées pour un chemin commençant par "assets/"
+func Resolve(name string) (fs.FS, string, error) {
+	if _, err := os.Stat(name); err == nil {
+		return diskFS(name)
+	}
+
+	slashed := path.Clean(filepath.ToSlash(name))
+	if embeddedName, ok := strings.CutPrefix(slashed, Prefix); ok {
+		if _, err := fs.Stat(embedded, embeddedName); err == nil {
+			return embedded, embeddedName, nil
+		}
+	}
+	return nil, "", &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// Fonction qui ouvre une ressource, depuis le disque ou les ressources intégrées
+func Open(name string) (fs.File, error) {
+	fsys, name, err := Resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return fsys.Open(name)
+}
+
+// Fonction qui renvoie les ressources correspondant au motif (par exemple "assets/maps/*.json"),
+// sur le disque et parmi les ressources intégrées, sans doublon et triées
+func Glob(pattern string) []string {
+	matches, _ := filepath.Glob(pattern)
+	for i, match := range matches {
+		matches[i] = filepath.ToSlash(match)
+	}
+	if embeddedPattern, ok := strings.CutPrefix(filepath.ToSlash(pattern), Prefix); ok {
+		embeddedMatches, _ := fs.Glob(embedded, embeddedPattern)
+		for _, match := range embeddedMatches {
+			matches = append(matches, Prefix+match)
+		}
+	}
+	slices.Sort(matches)
+	return slices.Compact(matches)
+}
+
+// Fonction qui renvoie un système de fichiers enraciné à la racine du disque et le nom du fichier dans celui-ci,
+// pour que les chemins relatifs au fichier (y compris avec "..") restent lisibles
+func diskFS(name string) (fs.FS, string, error) {
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return nil, "", err
+	}
+	volume := filepath.VolumeName(abs)
+	rooted := strings.TrimPrefix(filepath.ToSlash(abs[len(volume):]), "/")
+	return os.DirFS(volume + "/"), rooted, nil
+}
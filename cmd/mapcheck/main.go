@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	mapcheck "github.com/Tmegaa/The-Gophecy/pkg/Mapcheck"
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [options] <carte>\n", os.Args[0])
+		fmt.Fprintln(flag.CommandLine.Output(), "La carte est un nom du dossier assets/maps, un chemin vers un fichier TILED ou une description generate:...")
+		flag.PrintDefaults()
+	}
+	output := flag.String("png", "mapcheck.png", "image annotée des obstacles, cases d'apparition, zones et problèmes (vide pour ne pas l'écrire)")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	mapFile := flag.Arg(0)
+
+	in, report, err := mapcheck.CheckFile(mapFile)
+	if err != nil {
+		log.Fatalf("Failed to load map: %v", err)
+	}
+
+	fmt.Printf("%s: %d obstacles, %d objets, %d cases d'apparition, %d zones\n",
+		mapFile, len(in.Colliders), len(in.Objects), len(in.Spawns), len(in.Zones))
+	fmt.Printf("%d cases praticables, dont %d accessibles depuis les cases d'apparition\n", report.Free, report.Reachable)
+	errors, warnings := 0, 0
+	for _, issue := range report.Issues {
+		severity := "erreur"
+		if issue.Warning {
+			severity = "attention"
+			warnings++
+		} else {
+			errors++
+		}
+		fmt.Printf("%s [%s] %s\n", severity, issue.Kind, issue.Message)
+	}
+	fmt.Printf("%d erreurs, %d avertissements\n", errors, warnings)
+
+	if *output != "" {
+		if err := mapcheck.RenderFile(mapFile, in, report, *output); err != nil {
+			log.Fatalf("Failed to render map: %v", err)
+		}
+		fmt.Printf("Image annotée enregistrée dans %s\n", *output)
+	}
+	if !report.OK() {
+		os.Exit(1)
+	}
+}
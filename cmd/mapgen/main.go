@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	mapgen "github.com/Tmegaa/The-Gophecy/pkg/Mapgen"
+)
+
+// Dossier des jeux de tiles fournis, que la carte enregistrée référence
+const tilesetsDir = "assets/maps/tilesets"
+
+func main() {
+	defaults := mapgen.DefaultParams()
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [options]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	seed := flag.Uint64("seed", defaults.Seed, "graine du générateur")
+	width := flag.Int("width", defaults.Width, "largeur de la carte (en cases)")
+	height := flag.Int("height", defaults.Height, "hauteur de la carte (en cases)")
+	rooms := flag.Int("rooms", defaults.Rooms, "nombre de salles")
+	computers := flag.Float64("computers", defaults.ComputerDensity, "proportion des postes équipés d'un ordinateur dans les salles informatiques (0 à 1)")
+	statues := flag.Int("statues", defaults.Statues, "nombre de statues")
+	output := flag.String("out", "assets/maps/generated.json", "fichier JSON de TILED où la carte est enregistrée")
+	flag.Parse()
+
+	params := mapgen.Params{
+		Seed:            *seed,
+		Width:           *width,
+		Height:          *height,
+		Rooms:           *rooms,
+		ComputerDensity: *computers,
+		Statues:         *statues,
+	}
+	if err := params.Validate(); err != nil {
+		log.Fatalf("Invalid option: %v", err)
+	}
+
+	generated, err := mapgen.Generate(params)
+	if err != nil {
+		log.Fatalf("Generation failed: %v", err)
+	}
+
+	// Les jeux de tiles sont référencés relativement au fichier de la carte
+	tilesets, err := relativeTilesetsDir(*output)
+	if err != nil {
+		log.Fatalf("Failed to locate tilesets: %v", err)
+	}
+	if err := generated.Save(*output, tilesets); err != nil {
+		log.Fatalf("Failed to save map: %v", err)
+	}
+
+	fmt.Printf("Carte %dx%d enregistrée dans %s: %d salles, %d ordinateurs, %d statues\n",
+		params.Width, params.Height, *output, len(generated.Rooms), generated.Computers, generated.Statues)
+	fmt.Printf("Même carte sans fichier: -map %s%s\n", mapgen.Prefix, params)
+}
+
+// Fonction qui renvoie le chemin des jeux de tiles fournis, relatif au dossier du fichier de sortie
+func relativeTilesetsDir(output string) (string, error) {
+	outputDir, err := filepath.Abs(filepath.Dir(output))
+	if err != nil {
+		return "", err
+	}
+	tilesets, err := filepath.Abs(tilesetsDir)
+	if err != nil {
+		return "", err
+	}
+	relative, err := filepath.Rel(outputDir, tilesets)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(relative), nil
+}
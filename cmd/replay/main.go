@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	capture "github.com/Tmegaa/The-Gophecy/pkg/Capture"
+	sim "github.com/Tmegaa/The-Gophecy/pkg/Simulation"
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [options] <trace.jsonl[.gz]>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	captureFormat := flag.String("capture", "", "rend la trace sans fenêtre en images PNG (png) ou en animation GIF (gif, 600 images au plus)")
+	captureEvery := flag.Int("capture-every", sim.CaptureEvery, "période des captures (en ticks)")
+	outputDir := flag.String("out", ".", "dossier où sont écrites les captures")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	options := sim.CaptureOptions{Every: *captureEvery, Dir: *outputDir}
+	if *captureFormat != "" {
+		format, err := capture.ParseFormat(*captureFormat)
+		if err != nil {
+			log.Fatalf("Invalid option: %v", err)
+		}
+		options.Format = format
+
+		// Rendu des images sans ouvrir de fenêtre
+		if err := sim.RenderTrace(flag.Arg(0), options); err != nil {
+			log.Fatalf("Rendering failed: %v", err)
+		}
+		return
+	}
+
+	// On charge la trace enregistrée
+	replay, err := sim.NewReplay(flag.Arg(0), options)
+	if err != nil {
+		log.Fatalf("Failed to load trace: %v", err)
+	}
+
+	// On rejoue la trace dans la fenêtre d'affichage
+	if err := replay.Run(); err != nil {
+		log.Fatalf("Replay failed: %v", err)
+	}
+}
@@ -4,7 +4,7 @@ go 1.23.1
 
 require github.com/hajimehoshi/ebiten/v2 v2.8.5
 
-require github.com/wcharczuk/go-chart/v2 v2.1.2 // indirect
+require github.com/wcharczuk/go-chart/v2 v2.1.2
 
 require (
 	github.com/ebitengine/gomobile v0.0.0-20240911145611-4856209ac325 // indirect
@@ -12,7 +12,7 @@ require (
 	github.com/ebitengine/purego v0.8.0 // indirect
 	github.com/golang/freetype v0.0.0-20170609003504-e2365dfdc4a0
 	github.com/jezek/xgb v1.1.1 // indirect
-	golang.org/x/image v0.20.0 // indirect
+	golang.org/x/image v0.20.0
 	golang.org/x/sync v0.8.0 // indirect
 	golang.org/x/sys v0.25.0 // indirect
 )
@@ -1,19 +1,67 @@
 package main
 
 import (
-	sim "github.com/Tmegaa/The-Gophecy/pkg/Simulation"
+	"flag"
 	"log"
+
+	capture "github.com/Tmegaa/The-Gophecy/pkg/Capture"
+	sim "github.com/Tmegaa/The-Gophecy/pkg/Simulation"
 )
 
 func main() {
-	// On récupère les données de configuration
-	config := sim.ShowMenu()
+	// Options de la ligne de commande
+	eventLog := flag.String("events", "", "chemin du journal d'évènements (.jsonl ou .csv)")
+	outputDir := flag.String("out", ".", "dossier où sont écrits les résultats de la simulation")
+	metricsEvery := flag.Int("metrics-every", 1, "période d'échantillonnage des mesures (en ticks)")
+	seed := flag.Int64("seed", 0, "graine du générateur aléatoire (0 pour une graine tirée au hasard)")
+	tracePath := flag.String("trace", "", "chemin de la trace à enregistrer pour la rejouer (.jsonl ou .jsonl.gz)")
+	headless := flag.Bool("headless", false, "fait tourner la simulation sans fenêtre d'affichage (configuration dans le terminal)")
+	captureFormat := flag.String("capture", "", "enregistre la simulation dès son lancement: images PNG (png) ou animation GIF (gif, 600 images au plus)")
+	captureEvery := flag.Int("capture-every", sim.CaptureEvery, "période des captures (en ticks)")
+	mapFile := flag.String("map", "", "carte: nom d'une carte fournie (spawn.json, small_room.json, campus.json, plaza.json) ou chemin d'un fichier TILED (.json, .tmx)")
+	flag.Parse()
+
+	var format capture.Format
+	if *captureFormat != "" {
+		var err error
+		if format, err = capture.ParseFormat(*captureFormat); err != nil {
+			log.Fatalf("Invalid option: %v", err)
+		}
+	}
+
+	// Sans fenêtre, les données de configuration sont récupérées dans le terminal
+	config := sim.DefaultConfig()
+	if *headless {
+		config = sim.ShowMenu()
+	}
+	config.EventLogPath = *eventLog
+	config.OutputDir = *outputDir
+	config.MetricsEvery = *metricsEvery
+	config.Seed = *seed
+	config.TracePath = *tracePath
+	config.Headless = *headless
+	config.CaptureFormat = format
+	config.CaptureEvery = *captureEvery
+	if *mapFile != "" {
+		config.MapFile = *mapFile
+	}
+
+	// Avec fenêtre, la configuration se fait dans l'écran de démarrage
+	if !config.Headless {
+		if err := sim.NewApp(config).Run(); err != nil {
+			log.Fatalf("Simulation failed: %v", err)
+		}
+		return
+	}
 
 	// On génère la simulation
-	simulation := sim.NewSimulation(config)
+	simulation, err := sim.NewSimulation(config)
+	if err != nil {
+		log.Fatalf("Failed to create simulation: %v", err)
+	}
 
 	// On fait tourner la simulation jusqu'à rencontrer une erreur
-	if err := simulation.Run(); err != nil {
+	if err := simulation.RunHeadless(); err != nil {
 		log.Fatalf("Simulation failed: %v", err)
 	}
 }
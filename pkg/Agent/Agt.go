@@ -1,16 +1,11 @@
 package pkg
 
 import (
+	events "github.com/Tmegaa/The-Gophecy/pkg/Events"
 	ut "github.com/Tmegaa/The-Gophecy/pkg/Utilitaries"
 	"log"
-	"time"
 
-	"image"
 	"math"
-	"math/rand"
-
-	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 )
 
 // Interface qui regroupe les méthodes de tous les types d'agents
@@ -57,6 +52,12 @@ const (
 	// EatAct      ActionType = "Mange"
 )
 
+// Temps minimum (en ticks) avant qu'un agent ne retourne prier à la même statue
+const (
+	BelieverPrayerCooldown = 360
+	NeutralPrayerCooldown  = 210
+)
+
 type Agent struct {
 	Env               *Environnement      // pointeur vers l'environnement
 	Id                IdAgent             // identifiant agent
@@ -73,7 +74,6 @@ type Agent struct {
 	TypeAgt           TypeAgent           // agent sceptique, neutre ou croyant
 	SubType           SubTypeAgent        // agent de sous-type pirate, évangéliste ou sans sous-type
 	SyncChan          chan Message        // channel propre à l'agent pour communiquer avec l'environnement
-	Img               *ebiten.Image       // image à afficher sur l'interface graphique
 	MoveTimer         int                 // Temps de mouvement d'un agent
 	CurrentAction     ActionType          // action qui est en train d'être réalisée (soit dernière décision prise)
 	DialogTimer       int                 // Temps de dialogue d'un agent
@@ -83,7 +83,7 @@ type Agent struct {
 	UseComputer       *Computer           // Ordinateur en cours d'utilisation
 	LastComputer      *Computer           // Dernier ordinateur utilisé
 	LastStatue        *Statue             // Dernière statue utilisée
-	TimeLastStatue    time.Time           // Date-time de la dernière utilisation d'une statue
+	TickLastStatue    int64               // Tick de la dernière utilisation d'une statue
 	HeatMap           *VisitationMap      // Carte des endroits visités
 	CurrentWaypoint   *ut.Position        // Point actuel de patrouille pour les agents Neutral
 	MovementStrategy  MovementStrategy    // Stratégie de mouvement de l'agent
@@ -93,28 +93,28 @@ type Agent struct {
 }
 
 // Fonction qui renvoie un sous-type par rapport au type de l'agent
-func getRandomSubType(typeAgt TypeAgent) SubTypeAgent {
+func getRandomSubType(rng *ut.Rng, typeAgt TypeAgent) SubTypeAgent {
 	// Un agent neutre n'a pas de sous-type
 	if typeAgt == Neutral {
 		return None
 	}
 
 	// Probabilité d'avoir un sous-type (70 % de chance)
-	if rand.Float64() > 0.7 {
+	if rng.Float64() > 0.7 {
 		return None
 	}
 
 	switch typeAgt {
 	case Believer:
 		// Pour les croyants : 60 % convertisseur, 40 % pirate
-		if rand.Float64() < 0.6 {
+		if rng.Float64() < 0.6 {
 			return Converter
 		}
 		return Pirate
 
 	case Sceptic:
 		// Pour les sceptiques : 60% pirate, 40% convertisseur
-		if rand.Float64() < 0.6 {
+		if rng.Float64() < 0.6 {
 			return Pirate
 		}
 		return Converter
@@ -126,17 +126,14 @@ func getRandomSubType(typeAgt TypeAgent) SubTypeAgent {
 // Création d'un nouvel agent
 func NewAgent(env *Environnement, id IdAgent, velocite float64, acuite float64, position ut.Position,
 	opinion float64, charisme map[IdAgent]float64, relation map[IdAgent]float64, personalParameter float64,
-	typeAgt TypeAgent, syncChan chan Message, img *ebiten.Image) *Agent {
+	typeAgt TypeAgent, syncChan chan Message) *Agent {
 
 	// Calcul des poids relatifs du nouvel agent par rapport à chaque autre agent
 	poids_rel := make(map[IdAgent]ut.Pair, 0)
 	poids_abs := make(map[IdAgent]float64, 0)
 
 	// Détermine le sous-type en fonction du type d'agent
-	subType := getRandomSubType(typeAgt)
-
-	// Log pour debug
-	log.Printf("New agent created - ID: %s, Type: %s, SubType: %s", id, typeAgt, subType)
+	subType := getRandomSubType(env.Rng, typeAgt)
 
 	return &Agent{
 		Env:               env,
@@ -154,7 +151,6 @@ func NewAgent(env *Environnement, id IdAgent, velocite float64, acuite float64,
 		TypeAgt:           typeAgt,
 		SubType:           subType, // En utilisant le sous-type donné
 		SyncChan:          syncChan,
-		Img:               img,
 		MoveTimer:         60,
 		CurrentAction:     RunAct,
 		DialogTimer:       10,
@@ -164,7 +160,7 @@ func NewAgent(env *Environnement, id IdAgent, velocite float64, acuite float64,
 		UseComputer:       nil,
 		LastComputer:      nil,
 		LastStatue:        nil,
-		TimeLastStatue:    time.Now(),
+		TickLastStatue:    env.Tick(),
 		CurrentWaypoint:   nil,
 		LastTalkedTo:      make([]*Agent, 0),
 		MaxLastTalked:     3,
@@ -185,19 +181,35 @@ func (ag *Agent) AgtPosition() ut.Position {
 func (ag *Agent) Start() {
 	log.Printf("%s lancement...\n", ag.Id)
 
-	go func() {
-		env := ag.Env
-		for {
-			// Perception
-			nearby, obj := ag.Percept(env)
-			// Délibération
-			choice := ag.Deliberate(env, nearby, obj)
-			// Action
-			ag.Act(env, choice)
-			// Temps d'attente entre actions
-			time.Sleep(20 * time.Millisecond)
+	ag.Env.Register()
+	go ag.run()
+}
+
+// Fonction qui exécute la boucle de l'agent, une fois qu'il est inscrit auprès de l'horloge
+func (ag *Agent) run() {
+	env := ag.Env
+	last := env.Tick() - 1
+	for {
+		// Attente du tick suivant de l'horloge de la simulation
+		var running bool
+		last, running = env.WaitTick(last)
+		if !running {
+			return
+		}
+		// Un agent retiré de l'environnement quitte l'horloge et s'arrête
+		if !ag.Vivant {
+			env.Unregister()
+			return
 		}
-	}()
+		// Perception
+		nearby, obj := ag.Percept(env)
+		// Délibération
+		choice := ag.Deliberate(env, nearby, obj)
+		// Action
+		ag.Act(env, choice)
+		// L'agent a fini d'agir pour ce tick
+		env.TickDone()
+	}
 }
 
 // Fonction de perception d'un agent
@@ -210,8 +222,13 @@ func (ag *Agent) Percept(env *Environnement) ([]*Agent, []*InterfaceObjet) {
 	msg := Message{Type: PerceptionMsg, Agent: ag}
 	ag.SendToEnv(msg)
 
-	// Réception des agents à proximité dans le channel de l'agent
-	receive := <-ag.SyncChan
+	// Réception des agents à proximité dans le channel de l'agent; si l'environnement est arrêté, l'agent garde sa perception
+	var receive Message
+	select {
+	case receive = <-ag.SyncChan:
+	case <-env.done:
+		return ag.AgentProximity, ag.ObjsProximity
+	}
 
 	// Message d'erreur en cas de mauvais type de message
 	if receive.Type != NearbyMsg {
@@ -274,7 +291,7 @@ func (ag *Agent) Deliberate(env *Environnement, nearbyAgents []*Agent, obj []*In
 
 	default: // Aucun ou autres sous-types
 		// Comportement par défaut : choisit aléatoirement entre les objets et les agents
-		if rand.Float64() < 0.5 && hasObjects {
+		if env.Rng.Float64() < 0.5 && hasObjects {
 			return ag.tryUseObjects(obj)
 		} else if hasAgents {
 			return ag.tryInteractWithAgents(env, nearbyAgents)
@@ -311,12 +328,12 @@ func (ag *Agent) tryUseObjects(obj []*InterfaceObjet) ActionType {
 			case Sceptic:
 				continue
 			case Believer:
-				if ag.LastStatue == nil || ag.LastStatue.ID() != concrete.ID() || time.Since(ag.TimeLastStatue) > 6000*time.Millisecond {
+				if ag.LastStatue == nil || ag.LastStatue.ID() != concrete.ID() || ag.Env.Tick()-ag.TickLastStatue > BelieverPrayerCooldown {
 					ag.LastStatue = concrete
 					return PrayAct
 				}
 			case Neutral:
-				if rand.Float64() < 0.5 && (ag.LastStatue == nil || ag.LastStatue.ID() != concrete.ID() || time.Since(ag.TimeLastStatue) > 3500*time.Millisecond) {
+				if ag.Env.Rng.Float64() < 0.5 && (ag.LastStatue == nil || ag.LastStatue.ID() != concrete.ID() || ag.Env.Tick()-ag.TickLastStatue > NeutralPrayerCooldown) {
 					ag.LastStatue = concrete
 					return PrayAct
 				}
@@ -363,6 +380,11 @@ func (ag *Agent) shouldInteract(other *Agent) bool {
 		return false
 	}
 
+	// Certaines zones (une bibliothèque par exemple) interdisent les discussions
+	if !ag.zoneRules().Discussion || !other.zoneRules().Discussion {
+		return false
+	}
+
 	// Vérifiez si vous avez récemment parlé à cet agent
 	for _, lastTalked := range ag.LastTalkedTo {
 		if lastTalked.Id == other.Id {
@@ -379,6 +401,31 @@ func (ag *Agent) shouldInteract(other *Agent) bool {
 
 // Fonction qui fait évoluer les opinions de deux agents en discussion
 func (ag *Agent) setOpinion(ag2 *Agent) {
+	oldOpinionAg, oldOpinionAg2 := ag.Opinion, ag2.Opinion
+
+	switch ag.Env.OpinionModel {
+	case DeGrootModel:
+		ag.Opinion, ag2.Opinion = degrootOpinions(ag, ag2)
+	case BoundedConfidenceModel:
+		ag.Opinion, ag2.Opinion = boundedConfidenceOpinions(ag, ag2)
+	default:
+		ag.gophecyOpinions(ag2)
+	}
+	// La zone où a lieu la discussion atténue ou amplifie son effet (une salle bruyante par exemple)
+	influence := ag.zoneRules().Influence
+	ag.Opinion = oldOpinionAg + influence*(ag.Opinion-oldOpinionAg)
+	ag2.Opinion = oldOpinionAg2 + influence*(ag2.Opinion-oldOpinionAg2)
+	ag.Opinion = math.Max(0, math.Min(1, ag.Opinion))
+	ag2.Opinion = math.Max(0, math.Min(1, ag2.Opinion))
+	ag.emitOpinionChange(oldOpinionAg, ag2.Id, "")
+	ag2.emitOpinionChange(oldOpinionAg2, ag.Id, "")
+	// On met à jour les types des agents
+	ag.CheckType()
+	ag2.CheckType()
+}
+
+// Fonction qui applique le modèle d'origine de la Gophétie à deux agents en discussion
+func (ag *Agent) gophecyOpinions(ag2 *Agent) {
 	// Un agent sceptique qui parle à un croyant va diminuer sa croyance et à l'inverse
 	if ag.TypeAgt == Sceptic && ag2.TypeAgt == Believer {
 		ag.Opinion = ag.Opinion - 0.05
@@ -393,11 +440,21 @@ func (ag *Agent) setOpinion(ag2 *Agent) {
 		ag.Opinion = newOpinionAg
 		ag2.Opinion = newOpinionAg2
 	}
-	ag.Opinion = math.Max(0, math.Min(1, ag.Opinion))
-	ag2.Opinion = math.Max(0, math.Min(1, ag2.Opinion))
-	// On met à jour les types des agents
-	ag.CheckType()
-	ag2.CheckType()
+}
+
+// Fonction qui émet un évènement de variation d'opinion si l'opinion a changé depuis "before"
+func (ag *Agent) emitOpinionChange(before float64, other IdAgent, object IdObjet) {
+	if before == ag.Opinion {
+		return
+	}
+	ag.Env.Emit(events.Event{
+		Kind:   events.OpinionChange,
+		Agent:  string(ag.Id),
+		Other:  string(other),
+		Object: string(object),
+		Before: before,
+		After:  ag.Opinion,
+	})
 }
 
 // Fonction d'action où l'agent s'engage dans une discussion
@@ -455,7 +512,7 @@ func (ag *Agent) Act(env *Environnement, choice ActionType) {
 		if ag.LastStatue != nil {
 			ag.SetAction(PrayAct)
 			ag.Occupied = true
-			ag.TimeLastStatue = time.Now()
+			ag.TickLastStatue = env.Tick()
 		}
 
 	case DiscussAct:
@@ -469,6 +526,14 @@ func (ag *Agent) Act(env *Environnement, choice ActionType) {
 			ag.DiscussingWith.DiscussingWith = ag
 			ag.addToTalkHistory(ag.DiscussingWith)
 			ag.DiscussingWith.addToTalkHistory(ag)
+
+			env.Emit(events.Event{
+				Kind:   events.DiscussionStart,
+				Agent:  string(ag.Id),
+				Other:  string(ag.DiscussingWith.Id),
+				Before: ag.Opinion,
+				After:  ag.Opinion,
+			})
 		} else {
 			ag.DiscussingWith = nil
 			ag.ClearAction()
@@ -491,27 +556,46 @@ func (ag *Agent) ClearAction() {
 	case DiscussAct:
 		if ag.DiscussingWith != nil {
 			// Efface également l'état de l'autre agent
+			before := ag.Opinion
 			ag.setOpinion(ag.DiscussingWith)
+			ag.Env.Emit(events.Event{
+				Kind:   events.DiscussionEnd,
+				Agent:  string(ag.Id),
+				Other:  string(ag.DiscussingWith.Id),
+				Before: before,
+				After:  ag.Opinion,
+			})
 			ag.DiscussingWith.CurrentAction = RunAct
 			ag.DiscussingWith.Occupied = false
 			ag.DiscussingWith.DiscussingWith = nil
 		}
 
 	case PrayAct:
-		log.Printf("Agent %v finished praying", ag.Id)
-		log.Printf("Agent Opinion before: %v", ag.Opinion)
+		before := ag.Opinion
+		// La zone (une chapelle par exemple) peut renforcer l'effet de la prière
+		prayer := ag.zoneRules().Prayer
 		if ag.TypeAgt == Believer {
-			ag.Opinion = math.Min(1.0, ag.Opinion+0.05)
+			ag.Opinion = math.Min(1.0, ag.Opinion+0.05*prayer)
 		} else {
-			ag.Opinion = math.Min(1.0, ag.Opinion+0.1)
+			ag.Opinion = math.Min(1.0, ag.Opinion+0.1*prayer)
 		}
+
+		var statue IdObjet
+		if ag.LastStatue != nil {
+			statue = ag.LastStatue.ID()
+		}
+		ag.Env.Emit(events.Event{
+			Kind:   events.Prayer,
+			Agent:  string(ag.Id),
+			Object: string(statue),
+			Before: before,
+			After:  ag.Opinion,
+		})
+		ag.emitOpinionChange(before, "", statue)
 		ag.CheckType()
-		log.Printf("Agent Opinion after: %v", ag.Opinion)
 
 	case ComputerAct:
-		log.Printf("Agent %v finished using computer", ag.Id)
-		log.Printf("Agent Opinion before: %v", ag.Opinion)
-
+		before := ag.Opinion
 		currentProgram := ag.UseComputer.GetProgramm()
 
 		switch ag.TypeAgt {
@@ -539,9 +623,19 @@ func (ag *Agent) ClearAction() {
 			}
 		}
 
+		if newProgram := ag.UseComputer.GetProgramm(); newProgram != currentProgram {
+			ag.Env.Emit(events.Event{
+				Kind:   events.ProgramChange,
+				Agent:  string(ag.Id),
+				Object: string(ag.UseComputer.ID()),
+				Before: before,
+				After:  ag.Opinion,
+				From:   string(currentProgram),
+				To:     string(newProgram),
+			})
+		}
+		ag.emitOpinionChange(before, "", ag.UseComputer.ID())
 		ag.CheckType()
-		log.Printf("Agent Opinion after: %v", ag.Opinion)
-		log.Printf("Computer program: %v", ag.UseComputer.GetProgramm())
 
 		if ag.UseComputer != nil {
 			ag.UseComputer.Release()
@@ -560,9 +654,13 @@ func (ag *Agent) ClearAction() {
 // 	ag.SetAction(EatAct)
 // }
 
-// Fonction qui envoie un message à l'environnement via le channel de communication l'environnement
+// Fonction qui envoie un message à l'environnement via le channel de communication l'environnement,
+// sauf si l'environnement est arrêté
 func (ag *Agent) SendToEnv(msg Message) {
-	ag.Env.Communication <- msg
+	select {
+	case ag.Env.Communication <- msg:
+	case <-ag.Env.done:
+	}
 }
 
 // Fonction qui renvoie un pointeur vers un agent donné à partir de son identifiant
@@ -578,70 +676,47 @@ func (env *Environnement) GetAgentById(id IdAgent) *Agent {
 // Fonction qui vérifie la cohérence entre le type d'un agent et sa croyance et la met à jour si besoin
 func (ag *Agent) CheckType() {
 	oldType := ag.TypeAgt
+	oldSubType := ag.SubType
 
 	// Mise à jour du type de l'agent par rapport à son opinion
 	if ag.Opinion > 2./3. {
 		ag.TypeAgt = Believer
-		ag.Img = loadImageAgt(ut.AssetsPath + ut.AgentBelieverImageFile)
 	} else if ag.Opinion > 1./3. {
 		ag.TypeAgt = Neutral
-		ag.Img = loadImageAgt(ut.AssetsPath + ut.AgentNeutralImageFile)
 	} else {
 		ag.TypeAgt = Sceptic
-		ag.Img = loadImageAgt(ut.AssetsPath + ut.AgentScepticImageFile)
 	}
 
-	// Si le type a changé, on recalcule le sous-type
+	// Si le type a changé, on met à jour les compteurs et on recalcule le sous-type
 	if oldType != ag.TypeAgt {
-		// Log pour debug
-		log.Printf("Agent %v changed type from %v to %v", ag.Id, oldType, ag.TypeAgt)
-		log.Printf("Old subtype: %v", ag.SubType)
+		ag.Env.countAgent(oldType, -1)
+		ag.Env.countAgent(ag.TypeAgt, 1)
+		ag.Env.Emit(events.Event{
+			Kind:   events.TypeChange,
+			Agent:  string(ag.Id),
+			Before: ag.Opinion,
+			After:  ag.Opinion,
+			From:   string(oldType),
+			To:     string(ag.TypeAgt),
+		})
 
 		// Si l'agent devient neutre, il perd son sous-type
 		if ag.TypeAgt == Neutral {
 			ag.SubType = None
 		} else {
 			// S'il était Neutre et est devenu un autre type, ou s'il a changé entre Croyant/Sceptique
-			ag.SubType = getRandomSubType(ag.TypeAgt)
+			ag.SubType = getRandomSubType(ag.Env.Rng, ag.TypeAgt)
 		}
 
-		log.Printf("New subtype: %v", ag.SubType)
-	}
-}
-
-// Fonction qui affiche une image sur la fenêtre d'affichage
-func loadImageAgt(path string) *ebiten.Image {
-	img, _, err := ebitenutil.NewImageFromFile(path)
-	if err != nil {
-		log.Fatalf("Failed to load image: %s, error: %v", path, err)
-	}
-	return img
-}
-
-// Fonction qui vérifie s'il y a une collision entre un objet à la position x,y et les objets (horizontal)
-func CheckCollisionHorizontal(x, y float64, coliders []image.Rectangle) bool {
-	for _, colider := range coliders {
-		if colider.Overlaps(image.Rect(int(x), int(y), int(x)+16, int(y)+16)) {
-			if x > 0 {
-				return true
-			} else if x < 0 {
-				return true
-			}
-		}
-	}
-	return false
-}
-
-// Fonction qui vérifie s'il y a une collision entre un objet à la position x,y et les objets (vertical)
-func CheckCollisionVertical(x, y float64, coliders []image.Rectangle) bool {
-	for _, colider := range coliders {
-		if colider.Overlaps(image.Rect(int(x), int(y), int(x)+16, int(y)+16)) {
-			if y > 0 {
-				return true
-			} else if y < 0 {
-				return true
-			}
+		if oldSubType != ag.SubType {
+			ag.Env.Emit(events.Event{
+				Kind:   events.SubTypeChange,
+				Agent:  string(ag.Id),
+				Before: ag.Opinion,
+				After:  ag.Opinion,
+				From:   string(oldSubType),
+				To:     string(ag.SubType),
+			})
 		}
 	}
-	return false
 }
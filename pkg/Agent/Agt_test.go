@@ -0,0 +1,229 @@
+package pkg
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	carte "github.com/Tmegaa/The-Gophecy/pkg/Carte"
+	events "github.com/Tmegaa/The-Gophecy/pkg/Events"
+	ut "github.com/Tmegaa/The-Gophecy/pkg/Utilitaries"
+)
+
+// Tolérance des comparaisons d'opinions
+const epsilon = 1e-9
+
+func TestSetOpinionScepticBeliever(t *testing.T) {
+	cases := []struct {
+		name                  string
+		first, second         float64
+		wantFirst, wantSecond float64
+	}{
+		{"sceptique puis croyant", 0.2, 0.8, 0.15, 0.85},
+		{"croyant puis sceptique", 0.8, 0.2, 0.85, 0.15},
+		{"bornes de l'opinion", 0.02, 0.99, 0, 1},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			env := newTestEnv(1)
+			a := addTestAgent(env, "A", c.first, 0, 0)
+			b := addTestAgent(env, "B", c.second, 0, 0)
+
+			a.setOpinion(b)
+			if math.Abs(a.Opinion-c.wantFirst) > epsilon || math.Abs(b.Opinion-c.wantSecond) > epsilon {
+				t.Errorf("opinions %.3f et %.3f, attendu %.3f et %.3f", a.Opinion, b.Opinion, c.wantFirst, c.wantSecond)
+			}
+		})
+	}
+}
+
+func TestSetOpinionNeutrals(t *testing.T) {
+	env := newTestEnv(1)
+	a := addTestAgent(env, "A", 0.4, 0, 0)
+	b := addTestAgent(env, "B", 0.6, 0, 0)
+	a.PersonalParameter, b.PersonalParameter = 2, 3
+	a.Poids_rel[b.Id] = ut.Pair{First: 0.7, Second: 0.3}
+	b.Poids_rel[a.Id] = ut.Pair{First: 0.4, Second: 0.6}
+
+	// Équations du modèle: poids propre * paramètre personnel * x(1-x) + poids de l'autre * opinion de l'autre
+	wantA := 0.7*2*0.4*0.6 + 0.3*0.6
+	wantB := 0.6*0.4 + 0.4*3*0.6*0.4
+	a.setOpinion(b)
+	if math.Abs(a.Opinion-wantA) > epsilon || math.Abs(b.Opinion-wantB) > epsilon {
+		t.Errorf("opinions %.4f et %.4f, attendu %.4f et %.4f", a.Opinion, b.Opinion, wantA, wantB)
+	}
+}
+
+func TestSetOpinionZoneInfluence(t *testing.T) {
+	env := newTestEnv(1)
+	rules := carte.DefaultRules(carte.CafeteriaZone)
+	env.Carte.Zones = []carte.Zone{carte.NewZone("Cafétéria", carte.CafeteriaZone, rules, []image.Rectangle{image.Rect(0, 0, 96, 96)})}
+	a := addTestAgent(env, "A", 0.2, 24, 24)
+	b := addTestAgent(env, "B", 0.8, 48, 24)
+
+	// Dans une cafétéria, la variation de 0.05 est atténuée par le facteur d'influence de la zone
+	a.setOpinion(b)
+	want := 0.05 * rules.Influence
+	if math.Abs(a.Opinion-(0.2-want)) > epsilon || math.Abs(b.Opinion-(0.8+want)) > epsilon {
+		t.Errorf("opinions %.4f et %.4f, attendu une variation de %.4f", a.Opinion, b.Opinion, want)
+	}
+}
+
+func TestSetOpinionEmitsEvents(t *testing.T) {
+	env := newTestEnv(1)
+	sink := recordEvents(env)
+	a := addTestAgent(env, "A", 0.2, 0, 0)
+	b := addTestAgent(env, "B", 0.8, 0, 0)
+
+	a.setOpinion(b)
+	changes := sink.ofKind(events.OpinionChange)
+	if len(changes) != 2 {
+		t.Fatalf("%d évènements de variation d'opinion, attendu 2", len(changes))
+	}
+	if changes[0].Agent != "A" || changes[0].Other != "B" || math.Abs(changes[0].Delta()+0.05) > epsilon {
+		t.Errorf("évènement inattendu: %v", changes[0])
+	}
+}
+
+func TestCheckTypeTransitions(t *testing.T) {
+	cases := []struct {
+		from    float64
+		to      float64
+		want    TypeAgent
+		changed bool
+	}{
+		{0.8, 0.9, Believer, false},
+		{0.8, 2. / 3., Neutral, true},
+		{0.5, 0.7, Believer, true},
+		{0.5, 1. / 3., Sceptic, true},
+		{0.2, 0.34, Neutral, true},
+		{0.2, 0.9, Believer, true},
+		{0.9, 0.1, Sceptic, true},
+	}
+	for _, c := range cases {
+		env := newTestEnv(1)
+		sink := recordEvents(env)
+		agent := addTestAgent(env, "A", c.from, 0, 0)
+		before := agent.TypeAgt
+
+		agent.Opinion = c.to
+		agent.CheckType()
+		if agent.TypeAgt != c.want {
+			t.Errorf("%.3f -> %.3f: type %s, attendu %s", c.from, c.to, agent.TypeAgt, c.want)
+		}
+		if got := len(sink.ofKind(events.TypeChange)); got != map[bool]int{false: 0, true: 1}[c.changed] {
+			t.Errorf("%.3f -> %.3f: %d changements de type émis", c.from, c.to, got)
+		}
+
+		// Les compteurs par type suivent le changement
+		count := func(typeAgt TypeAgent) int {
+			value, _ := env.NbrAgents.Load(typeAgt)
+			return value.(int)
+		}
+		if count(c.want) != 1 || (before != c.want && count(before) != 0) {
+			t.Errorf("%.3f -> %.3f: compteurs %d %s, %d %s", c.from, c.to, count(before), before, count(c.want), c.want)
+		}
+		if c.want == Neutral && agent.SubType != None {
+			t.Errorf("%.3f -> %.3f: un agent neutre garde le sous-type %s", c.from, c.to, agent.SubType)
+		}
+	}
+}
+
+func TestGetRandomSubTypeDistribution(t *testing.T) {
+	const draws = 100000
+	cases := []struct {
+		typeAgt TypeAgent
+		want    map[SubTypeAgent]float64
+	}{
+		// 70 % des croyants et des sceptiques ont un sous-type, réparti 60/40
+		{Believer, map[SubTypeAgent]float64{None: 0.3, Converter: 0.42, Pirate: 0.28}},
+		{Sceptic, map[SubTypeAgent]float64{None: 0.3, Pirate: 0.42, Converter: 0.28}},
+		{Neutral, map[SubTypeAgent]float64{None: 1}},
+	}
+	for _, c := range cases {
+		rng := ut.NewRng(42)
+		counts := make(map[SubTypeAgent]int)
+		for i := 0; i < draws; i++ {
+			counts[getRandomSubType(rng, c.typeAgt)]++
+		}
+		for subType, share := range c.want {
+			if got := float64(counts[subType]) / draws; math.Abs(got-share) > 0.01 {
+				t.Errorf("%s: %.3f de %s, attendu %.2f", c.typeAgt, got, subType, share)
+			}
+		}
+		if len(counts) != len(c.want) {
+			t.Errorf("%s: sous-types inattendus %v", c.typeAgt, counts)
+		}
+	}
+}
+
+func TestShouldInteract(t *testing.T) {
+	cases := []struct {
+		name  string
+		setup func(a, b *Agent)
+		want  bool
+	}{
+		{"types différents", func(a, b *Agent) { b.Opinion, b.TypeAgt = 0.2, Sceptic }, true},
+		{"même type, opinions différentes", func(a, b *Agent) { b.Opinion = 0.85 }, true},
+		{"même opinion", func(a, b *Agent) {}, false},
+		{"agent occupé", func(a, b *Agent) { b.Opinion, b.Occupied = 0.2, true }, false},
+		{"déjà en discussion", func(a, b *Agent) { b.Opinion, b.CurrentAction = 0.2, DiscussAct }, false},
+		{"discussion récente", func(a, b *Agent) { b.Opinion = 0.2; a.LastTalkedTo = []*Agent{b} }, false},
+		{"bibliothèque", func(a, b *Agent) {
+			b.Opinion = 0.2
+			a.Env.Carte.Zones = []carte.Zone{carte.NewZone("Bibliothèque", carte.LibraryZone,
+				carte.DefaultRules(carte.LibraryZone), []image.Rectangle{image.Rect(0, 0, 48, 48)})}
+		}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			env := newTestEnv(1)
+			a := addTestAgent(env, "A", 0.8, 10, 10)
+			b := addTestAgent(env, "B", 0.8, 30, 10)
+			c.setup(a, b)
+			if got := a.shouldInteract(b); got != c.want {
+				t.Errorf("shouldInteract = %v, attendu %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestDiscussionPairing(t *testing.T) {
+	env := newTestEnv(1)
+	sink := recordEvents(env)
+	a := addTestAgent(env, "A", 0.2, 100, 100)
+	b := addTestAgent(env, "B", 0.8, 120, 100)
+	a.SubType, b.SubType = Converter, Converter
+	testStep(env)
+
+	// Le premier agent engage la discussion: les deux sont occupés et liés l'un à l'autre
+	if a.CurrentAction != DiscussAct || b.CurrentAction != DiscussAct {
+		t.Fatalf("actions %s et %s, attendu une discussion", a.CurrentAction, b.CurrentAction)
+	}
+	if a.DiscussingWith != b || b.DiscussingWith != a || !a.Occupied || !b.Occupied {
+		t.Fatalf("les agents ne sont pas appariés")
+	}
+	if len(a.LastTalkedTo) != 1 || a.LastTalkedTo[0] != b || len(b.LastTalkedTo) != 1 || b.LastTalkedTo[0] != a {
+		t.Errorf("historiques de discussion inattendus")
+	}
+
+	// La discussion dure le temps du dialogue, puis les opinions sont mises à jour et les agents libérés
+	for i := 0; i < 180 && a.CurrentAction == DiscussAct; i++ {
+		testStep(env)
+	}
+	if a.Occupied || b.Occupied || a.DiscussingWith != nil || b.DiscussingWith != nil {
+		t.Fatalf("les agents sont encore en discussion")
+	}
+	if math.Abs(a.Opinion-0.15) > epsilon || math.Abs(b.Opinion-0.85) > epsilon {
+		t.Errorf("opinions %.3f et %.3f après la discussion, attendu 0.15 et 0.85", a.Opinion, b.Opinion)
+	}
+	if len(sink.ofKind(events.DiscussionStart)) != 1 || len(sink.ofKind(events.DiscussionEnd)) != 1 {
+		t.Errorf("%d débuts et %d fins de discussion émis", len(sink.ofKind(events.DiscussionStart)), len(sink.ofKind(events.DiscussionEnd)))
+	}
+
+	// Ils viennent de se parler: ils ne recommencent pas aussitôt
+	testStep(env)
+	if a.CurrentAction == DiscussAct || b.CurrentAction == DiscussAct {
+		t.Errorf("les agents reprennent aussitôt leur discussion")
+	}
+}
@@ -0,0 +1,120 @@
+package pkg
+
+import (
+	"image"
+	"math"
+)
+
+// Taille de l'emprise d'un agent pour les collisions (en pixels)
+const AgentSize = 16
+
+// Fonction qui renvoie l'emprise d'un agent dont l'image a son coin supérieur gauche en (x, y)
+func agentBounds(x, y float64) image.Rectangle {
+	left, top := int(math.Floor(x)), int(math.Floor(y))
+	return image.Rect(left, top, left+AgentSize, top+AgentSize)
+}
+
+// Fonction qui vérifie s'il y a une collision entre un agent à la position x,y et les obstacles
+func CheckCollision(x, y float64, coliders []image.Rectangle) bool {
+	rect := agentBounds(x, y)
+	for _, colider := range coliders {
+		if colider.Overlaps(rect) {
+			return true
+		}
+	}
+	return false
+}
+
+// Fonction qui ramène la position x,y d'un agent à l'intérieur de la carte (sans effet si l'étendue est vide)
+func clampToBounds(x, y float64, bounds image.Rectangle) (float64, float64) {
+	if bounds.Empty() {
+		return x, y
+	}
+	x = math.Max(float64(bounds.Min.X), math.Min(x, float64(bounds.Max.X-AgentSize)))
+	y = math.Max(float64(bounds.Min.Y), math.Min(y, float64(bounds.Max.Y-AgentSize)))
+	return x, y
+}
+
+// Fonction qui déplace l'agent de (dx, dy) si la nouvelle position est libre et dans la carte. Un agent déjà
+// coincé dans un obstacle (apparu dessus par exemple) peut toujours en sortir. Renvoie faux si l'agent n'a pas bougé
+func (env *Environnement) tryMove(ag *Agent, dx, dy float64) bool {
+	var coliders []image.Rectangle
+	var bounds image.Rectangle
+	if env.Carte != nil {
+		coliders = env.Carte.Coliders
+		bounds = env.Carte.Bounds
+	}
+
+	x, y := clampToBounds(ag.Position.X+dx, ag.Position.Y+dy, bounds)
+	if x == ag.Position.X && y == ag.Position.Y {
+		return false
+	}
+	if CheckCollision(x, y, coliders) && !CheckCollision(ag.Position.X, ag.Position.Y, coliders) {
+		return false
+	}
+	ag.Position.X, ag.Position.Y = x, y
+	return true
+}
+
+// Fonction qui avance l'agent dans sa direction, un axe après l'autre: bloqué sur un axe, il glisse le long
+// de l'obstacle sur l'autre et garde cette seule composante. Renvoie faux s'il est bloqué sur les deux axes
+func (env *Environnement) slide(ag *Agent) bool {
+	movedX := ag.Position.Dx != 0 && env.tryMove(ag, ag.Position.Dx, 0)
+	if !movedX {
+		ag.Position.Dx = 0
+	}
+	movedY := ag.Position.Dy != 0 && env.tryMove(ag, 0, ag.Position.Dy)
+	if !movedY {
+		ag.Position.Dy = 0
+	}
+	return movedX || movedY
+}
+
+// Fonction qui écarte l'agent des agents dont l'emprise chevauche la sienne, pour que leurs images ne s'empilent pas.
+// L'agent fait la moitié du chemin selon l'axe le moins engagé (l'autre fera le reste en bougeant), sans dépasser sa
+// vitesse maximale ni traverser d'obstacle
+func (env *Environnement) separate(ag *Agent, maxSpeed float64) {
+	var pushX, pushY float64
+	for _, other := range env.Ags {
+		if other == ag {
+			continue
+		}
+		dx := ag.Position.X - other.Position.X
+		dy := ag.Position.Y - other.Position.Y
+		overlapX := AgentSize - math.Abs(dx)
+		overlapY := AgentSize - math.Abs(dy)
+		if overlapX <= 0 || overlapY <= 0 {
+			continue
+		}
+		// Deux agents exactement superposés s'écartent dans des sens opposés, selon leur identifiant
+		side := 1.0
+		if ag.Id < other.Id {
+			side = -1
+		}
+		if overlapX <= overlapY {
+			pushX += direction(dx, side) * overlapX / 2
+		} else {
+			pushY += direction(dy, side) * overlapY / 2
+		}
+	}
+
+	pushX = math.Max(-maxSpeed, math.Min(pushX, maxSpeed))
+	pushY = math.Max(-maxSpeed, math.Min(pushY, maxSpeed))
+	if pushX != 0 {
+		env.tryMove(ag, pushX, 0)
+	}
+	if pushY != 0 {
+		env.tryMove(ag, 0, pushY)
+	}
+}
+
+// Fonction qui renvoie le signe de d, ou side si d est nul
+func direction(d, side float64) float64 {
+	switch {
+	case d > 0:
+		return 1
+	case d < 0:
+		return -1
+	}
+	return side
+}
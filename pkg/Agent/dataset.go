@@ -0,0 +1,97 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+// Structure pour contenir les données des agents à partir d'un fichier
+type AgentData struct {
+	Id                string             `json:"id"`
+	Opinion           float64            `json:"opinion"`
+	Charisme          map[string]float64 `json:"charisme"`
+	Relation          map[string]float64 `json:"relation"`
+	PersonalParameter float64            `json:"personalParameter"`
+	SubType           string             `json:"subType"`
+}
+
+// Fonction pour créer des agents à partir d'un fichier JSON (jeux de données du dossier tests),
+// placés au hasard sur les cases d'apparition de la carte
+func CreateAgentsFromFile(env *Environnement, filePath string) ([]*Agent, error) {
+	// Lire le contenu du fichier
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("échec de la lecture du fichier: %v", err)
+	}
+
+	// Analyser les données JSON
+	var agentsData []AgentData
+	err = json.Unmarshal(data, &agentsData)
+	if err != nil {
+		return nil, fmt.Errorf("échec de l'analyse des données JSON: %v", err)
+	}
+
+	// Générer des positions valides
+	carte := env.Carte
+	validPositions := carte.SpawnPositions()
+	if len(validPositions) < len(agentsData) {
+		return nil, fmt.Errorf("pas assez de positions de spawn valides pour tous les agents")
+	}
+
+	env.Rng.Shuffle(len(validPositions), func(i, j int) {
+		validPositions[i], validPositions[j] = validPositions[j], validPositions[i]
+	})
+
+	// Créer des agents à partir des données analysées
+	agents := make([]*Agent, len(agentsData))
+	for i, agentData := range agentsData {
+		id := IdAgent(agentData.Id)
+		charisme := make(map[IdAgent]float64)
+		for k, v := range agentData.Charisme {
+			charisme[IdAgent(k)] = v
+		}
+		relation := make(map[IdAgent]float64)
+		for k, v := range agentData.Relation {
+			relation[IdAgent(k)] = v
+		}
+
+		// Déterminer le type en fonction de l'opinion
+		var typeAgt TypeAgent
+		if agentData.Opinion > 2.0/3.0 {
+			typeAgt = Believer
+		} else if agentData.Opinion > 1.0/3.0 {
+			typeAgt = Neutral
+		} else {
+			typeAgt = Sceptic
+		}
+
+		subType := SubTypeAgent(agentData.SubType)
+
+		// Générer la vélocité, l'acuité et la position
+		velocite := env.Rng.Float64()
+		acuite := 50.0
+		position := validPositions[i]
+
+		// Créer l'agent
+		agent := NewAgent(
+			env,
+			id,
+			velocite,
+			acuite,
+			position,
+			agentData.Opinion,
+			charisme,
+			relation,
+			agentData.PersonalParameter,
+			typeAgt,
+			make(chan Message),
+		)
+		agent.SubType = subType
+
+		agents[i] = agent
+		env.AddAgent(agent)
+	}
+	env.SetPoids()
+	return agents, nil
+}
@@ -0,0 +1,153 @@
+package pkg
+
+import (
+	"slices"
+
+	events "github.com/Tmegaa/The-Gophecy/pkg/Events"
+	ut "github.com/Tmegaa/The-Gophecy/pkg/Utilitaries"
+)
+
+// Fonction qui relie un agent qui vient d'être ajouté (avec AddAgent) aux autres agents de l'environnement:
+// elle tire ses relations dans les deux sens, ses poids, et insère son poids dans ceux des autres agents
+func (env *Environnement) ConnectAgent(ag *Agent) {
+	for _, ag2 := range env.Ags {
+		if ag2.Id == ag.Id {
+			ag.Relation[ag.Id] = 1
+			continue
+		}
+		ag.Relation[ag2.Id] = env.randomRelation()
+		ag2.Relation[ag.Id] = env.randomRelation()
+	}
+
+	env.setAgentPoids(ag)
+
+	// Le poids du nouvel agent est tiré comme les autres, puis ramené à l'échelle des poids déjà normalisés
+	expected := MinPoids + (MaxPoids-MinPoids)/2
+	for _, ag2 := range env.Ags {
+		if ag2.Id == ag.Id || len(ag2.Poids_abs) == 0 {
+			continue
+		}
+		mean := 1 / float64(len(ag2.Poids_abs))
+		ag2.Poids_abs[ag.Id] = env.randomPoids(ag2.Relation[ag.Id]) / expected * mean
+		ag2.normalizePoids()
+	}
+}
+
+// Fonction qui lance un agent ajouté pendant la fonction update d'AdvanceTick ou de BetweenTicks: l'horloge est alors
+// verrouillée, l'agent y est inscrit directement et agit dès le tick courant
+func (env *Environnement) StartAgent(ag *Agent) {
+	env.running++
+	go ag.run()
+}
+
+// Fonction qui retire un agent de l'environnement: ses liens avec les autres agents sont supprimés et sa boucle s'arrête
+func (env *Environnement) RemoveAgent(ag *Agent) {
+	idx := slices.Index(env.Ags, ag)
+	if idx < 0 {
+		return
+	}
+	env.Ags = slices.Concat(env.Ags[:idx], env.Ags[idx+1:])
+	env.countAgent(ag.TypeAgt, -1)
+
+	// L'agent libère ce qu'il utilisait et son interlocuteur éventuel
+	if ag.DiscussingWith != nil {
+		ag.DiscussingWith.interrupt()
+	}
+	if ag.UseComputer != nil {
+		ag.UseComputer.Release()
+	}
+	ag.interrupt()
+	ag.Vivant = false
+
+	// Suppression des liens des autres agents vers l'agent retiré
+	for _, ag2 := range env.Ags {
+		if ag2.DiscussingWith == ag {
+			ag2.interrupt()
+		}
+		ag2.LastTalkedTo = slices.DeleteFunc(ag2.LastTalkedTo, func(other *Agent) bool { return other == ag })
+		delete(ag2.Relation, ag.Id)
+		delete(ag2.Poids_rel, ag.Id)
+		if _, ok := ag2.Poids_abs[ag.Id]; ok {
+			delete(ag2.Poids_abs, ag.Id)
+			ag2.normalizePoids()
+		}
+	}
+
+	env.Emit(events.Event{
+		Kind:   events.Death,
+		Agent:  string(ag.Id),
+		Before: ag.Opinion,
+		After:  ag.Opinion,
+		From:   string(ag.TypeAgt),
+	})
+}
+
+// Fonction qui ajoute un objet (ordinateur ou statue) dans l'environnement
+func (env *Environnement) AddObject(obj InterfaceObjet) {
+	env.Objs = append(env.Objs, obj)
+}
+
+// Fonction qui retire un objet de l'environnement: les agents qui l'utilisaient ou s'en souvenaient l'oublient
+func (env *Environnement) RemoveObject(obj InterfaceObjet) {
+	idx := slices.Index(env.Objs, obj)
+	if idx < 0 {
+		return
+	}
+	env.Objs = slices.Concat(env.Objs[:idx], env.Objs[idx+1:])
+
+	for _, ag := range env.Ags {
+		if ag.UseComputer != nil && InterfaceObjet(ag.UseComputer) == obj {
+			ag.UseComputer.Release()
+			ag.UseComputer = nil
+			ag.interrupt()
+		}
+		if ag.LastComputer != nil && InterfaceObjet(ag.LastComputer) == obj {
+			ag.LastComputer = nil
+		}
+		if ag.LastStatue != nil && InterfaceObjet(ag.LastStatue) == obj {
+			if ag.CurrentAction == PrayAct {
+				ag.interrupt()
+			}
+			ag.LastStatue = nil
+		}
+	}
+}
+
+// Fonction qui modifie directement l'opinion d'un agent (par exemple depuis l'éditeur) et met à jour son type
+func (ag *Agent) EditOpinion(opinion float64) {
+	before := ag.Opinion
+	ag.Opinion = max(0, min(1, opinion))
+	ag.emitOpinionChange(before, "", "")
+	ag.CheckType()
+}
+
+// Fonction qui interrompt l'action en cours d'un agent sans appliquer ses effets
+func (ag *Agent) interrupt() {
+	ag.CurrentAction = RunAct
+	ag.DialogTimer = 0
+	ag.Occupied = false
+	ag.DiscussingWith = nil
+}
+
+// Fonction qui normalise les poids absolus d'un agent et recalcule ses poids relatifs
+func (ag *Agent) normalizePoids() {
+	sum := 0.0
+	for _, w := range ag.Poids_abs {
+		sum += w
+	}
+	for id, w := range ag.Poids_abs {
+		if sum > 0 {
+			ag.Poids_abs[id] = w / sum
+		} else {
+			ag.Poids_abs[id] = 0
+		}
+	}
+	for id, w := range ag.Poids_abs {
+		pairAg := ut.Pair{}
+		if id != ag.Id && w+ag.Poids_abs[ag.Id] > 0 {
+			pairAg.Second = w / (w + ag.Poids_abs[ag.Id])
+			pairAg.First = ag.Poids_abs[ag.Id] / (w + ag.Poids_abs[ag.Id])
+		}
+		ag.Poids_rel[id] = pairAg
+	}
+}
@@ -3,15 +3,23 @@ package pkg
 import (
 	"image"
 	"math"
-	"math/rand"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	carte "github.com/Tmegaa/The-Gophecy/pkg/Carte"
+	events "github.com/Tmegaa/The-Gophecy/pkg/Events"
 	ut "github.com/Tmegaa/The-Gophecy/pkg/Utilitaries"
 )
 
 var lsType = []TypeAgent{Sceptic, Believer, Neutral}
 
+// Bornes des poids absolus tirés entre deux agents, avant normalisation
+const (
+	MinPoids = 0.01
+	MaxPoids = 1.0
+)
+
 type MovementStrategy int
 
 const (
@@ -46,10 +54,19 @@ type Environnement struct {
 	Ags             []*Agent
 	Carte           *carte.Carte
 	Objs            []InterfaceObjet
-	Communication   chan Message //key = IDAgent et value = []*Message -> Liste des messages reçus par l'agent
-	NbrAgents       *sync.Map    //key = typeAgent et value = int  -> Compteur d'agents par types
-	AgentProximity  *sync.Map    //key = IDAgent et value = []*Agent -> Liste des agents proches
-	ObjectProximity *sync.Map    //key = IDAgent et value = []*Objet -> Liste des objets proches
+	Communication   chan Message  //key = IDAgent et value = []*Message -> Liste des messages reçus par l'agent
+	NbrAgents       *sync.Map     //key = typeAgent et value = int  -> Compteur d'agents par types
+	AgentProximity  *sync.Map     //key = IDAgent et value = []*Agent -> Liste des agents proches
+	ObjectProximity *sync.Map     //key = IDAgent et value = []*Objet -> Liste des objets proches
+	Events          *events.Bus   // bus qui reçoit les évènements de la simulation (peut être nil)
+	Rng             *ut.Rng       // générateur de nombres aléatoires de la simulation
+	OpinionModel    OpinionModel  // modèle de dynamique d'opinion (celui de la Gophétie si vide)
+	tick            atomic.Int64  // tick courant de la simulation
+	clock           *sync.Cond    // réveille les agents et la simulation à chaque changement de l'horloge
+	running         int           // nombre d'agents synchronisés sur l'horloge
+	acted           int           // nombre d'agents ayant agi pendant le tick courant
+	stopped         bool          // l'horloge est arrêtée: les agents terminent leur boucle
+	done            chan struct{} // fermé par Stop: l'écoute des messages se termine
 }
 
 // Fonction d'initialisation d'un nouvel environnement
@@ -61,27 +78,139 @@ func NewEnvironment(ags []*Agent, carte *carte.Carte, objs []InterfaceObjet) *En
 		counter.Store(val, 0)
 	}
 
-	return &Environnement{Ags: ags, Objs: objs, Communication: make(chan Message, 100), NbrAgents: counter, Carte: carte, AgentProximity: &sync.Map{}}
+	return &Environnement{Ags: ags, Objs: objs, Communication: make(chan Message, 100), NbrAgents: counter, Carte: carte, AgentProximity: &sync.Map{}, Rng: ut.NewRng(uint64(time.Now().UnixNano())), clock: sync.NewCond(&sync.Mutex{}), done: make(chan struct{})}
+}
+
+// Fonction qui renvoie le tick courant de la simulation
+func (env *Environnement) Tick() int64 {
+	return env.tick.Load()
+}
+
+// Fonction qui fait avancer la simulation d'un tick, une fois que tous les agents ont agi pendant le tick courant.
+// La fonction update (qui peut être nil) est exécutée au nouveau tick, avant que les agents ne soient réveillés.
+func (env *Environnement) AdvanceTick(update func()) int64 {
+	env.clock.L.Lock()
+	defer env.clock.L.Unlock()
+
+	for env.acted < env.running {
+		env.clock.Wait()
+	}
+	env.acted = 0
+	tick := env.tick.Add(1)
+	if update != nil {
+		update()
+	}
+	env.clock.Broadcast()
+	return tick
+}
+
+// Fonction qui exécute update entre deux ticks, sans faire avancer l'horloge: elle attend que tous les agents aient agi
+// pendant le tick courant, comme AdvanceTick
+func (env *Environnement) BetweenTicks(update func()) {
+	env.clock.L.Lock()
+	defer env.clock.L.Unlock()
+
+	for env.acted < env.running && !env.stopped {
+		env.clock.Wait()
+	}
+	update()
+}
+
+// Fonction qui met à jour les minuteurs des agents, une fois par tick: à la fin de son dialogue, l'agent termine son action,
+// libère l'ordinateur qu'il utilisait et redevient disponible
+func (env *Environnement) UpdateTimers() {
+	for _, agent := range env.Ags {
+		if agent.DialogTimer > 0 {
+			agent.DialogTimer--
+			if agent.DialogTimer == 0 {
+				agent.ClearAction()
+				if agent.UseComputer != nil {
+					agent.UseComputer.Release()
+					agent.UseComputer = nil
+				}
+				agent.Occupied = false
+			}
+		}
+	}
+}
+
+// Fonction qui inscrit un agent auprès de l'horloge: la simulation attendra qu'il ait agi avant de passer au tick suivant
+func (env *Environnement) Register() {
+	env.clock.L.Lock()
+	defer env.clock.L.Unlock()
+	env.running++
+}
+
+// Fonction qui désinscrit un agent de l'horloge
+func (env *Environnement) Unregister() {
+	env.clock.L.Lock()
+	defer env.clock.L.Unlock()
+	env.running--
+	env.clock.Broadcast()
+}
+
+// Fonction qui bloque un agent jusqu'à ce que l'horloge dépasse le tick last, et renvoie le nouveau tick.
+// Le booléen est faux si l'horloge a été arrêtée entre temps.
+func (env *Environnement) WaitTick(last int64) (int64, bool) {
+	env.clock.L.Lock()
+	defer env.clock.L.Unlock()
+
+	for env.tick.Load() <= last && !env.stopped {
+		env.clock.Wait()
+	}
+	return env.tick.Load(), !env.stopped
+}
+
+// Fonction qui arrête l'horloge: les boucles des agents se terminent au lieu d'attendre le tick suivant,
+// et l'écoute des messages lancée par Listen s'arrête
+func (env *Environnement) Stop() {
+	env.clock.L.Lock()
+	defer env.clock.L.Unlock()
+	if !env.stopped {
+		close(env.done)
+	}
+	env.stopped = true
+	env.clock.Broadcast()
+}
+
+// Fonction qui signale qu'un agent a fini d'agir pendant le tick courant
+func (env *Environnement) TickDone() {
+	env.clock.L.Lock()
+	defer env.clock.L.Unlock()
+	env.acted++
+	env.clock.Broadcast()
+}
+
+// Fonction qui date un évènement avec le tick courant et l'envoie sur le bus d'évènements
+func (env *Environnement) Emit(e events.Event) {
+	if env == nil || env.Events == nil {
+		return
+	}
+	e.Tick = env.Tick()
+	env.Events.Emit(e)
 }
 
 // Fonction qui ajoute un nouvel agent dans l'environnement
 func (env *Environnement) AddAgent(ag *Agent) {
 	env.Ags = append(env.Ags, ag)
+	env.Emit(events.Event{
+		Kind:   events.Spawn,
+		Agent:  string(ag.Id),
+		Before: ag.Opinion,
+		After:  ag.Opinion,
+		To:     string(ag.TypeAgt),
+	})
+
+	// Mise à jour du nombre d'agents de ce type
+	env.countAgent(ag.TypeAgt, 1)
+}
 
-	// Charger le nombre actuel d'agents de ce type
-	value, exists := env.NbrAgents.Load(ag.TypeAgt)
-	if exists {
-		// Si la clé existe, convertir en int et incrémenter
-		if nbr, ok := value.(int); ok {
-			env.NbrAgents.Store(ag.TypeAgt, nbr+1)
-		} else {
-			// Si la valeur n'est pas un int, gérer l'erreur ou initialiser à 1
-			env.NbrAgents.Store(ag.TypeAgt, 1)
-		}
-	} else {
-		// Si la clé n'existe pas, initialiser à 1
-		env.NbrAgents.Store(ag.TypeAgt, 1)
-	}
+// Fonction qui ajoute delta au compteur d'agents d'un type
+func (env *Environnement) countAgent(typeAgt TypeAgent, delta int) {
+	// Si la clé n'existe pas ou que la valeur n'est pas un int, le compteur repart de 0
+	value, _ := env.NbrAgents.Load(typeAgt)
+	nbr, _ := value.(int)
+	env.NbrAgents.Store(typeAgt, max(0, nbr+delta))
 }
 
 // Fonction qui envoie la liste des agents proches pour un agent donné
@@ -133,10 +262,14 @@ func (env *Environnement) NearbyObjects(ag *Agent) []*InterfaceObjet {
 	return nearbyObjects
 }
 
-// Fonction de l'environnement qui gère la communication avec les agents via les channels
+// Fonction de l'environnement qui gère la communication avec les agents via les channels, jusqu'à l'appel de Stop
 func (env *Environnement) Listen() {
-	go func() {
-		for msg := range env.Communication {
+	for {
+		select {
+		case <-env.done:
+			return
+
+		case msg := <-env.Communication:
 			switch {
 			case msg.Type == PerceptionMsg:
 				near := env.NearbyAgents(msg.Agent)
@@ -147,12 +280,15 @@ func (env *Environnement) Listen() {
 
 			}
 		}
-	}()
+	}
 }
 
-// Fonction d'envoi d'un message à un agent via son channel
+// Fonction d'envoi d'un message à un agent via son channel; l'envoi est abandonné si l'environnement est arrêté
 func (env *Environnement) SendToAgent(agt *Agent, msg Message) {
-	agt.SyncChan <- msg
+	select {
+	case agt.SyncChan <- msg:
+	case <-env.done:
+	}
 }
 
 // Fonction de movement d'un agent dans l'environnement (soit mise à jour de sa position)
@@ -162,15 +298,11 @@ func (env *Environnement) Move(ag *Agent) {
 	// On reste dans la même direction
 	if ag.MoveTimer > 0 {
 		ag.MoveTimer -= 1
-		// Si une collision a lieu on s'arrête
-		if CheckCollisionHorizontal((ag.Position.X+ag.Position.Dx), (ag.Position.Y+ag.Position.Dy), ag.Env.Carte.Coliders) ||
-			CheckCollisionVertical((ag.Position.X+ag.Position.Dx), (ag.Position.Y+ag.Position.Dy), ag.Env.Carte.Coliders) {
-			return
+		// L'agent glisse le long des obstacles; bloqué dans les deux directions, il en choisit une nouvelle au prochain tick
+		if !env.slide(ag) {
+			ag.MoveTimer = 0
 		}
-
-		// Sinon on continue de bouger
-		ag.Position.X += ag.Position.Dx
-		ag.Position.Y += ag.Position.Dy
+		env.separate(ag, ut.Maxspeed)
 		return
 	}
 
@@ -197,9 +329,9 @@ func (env *Environnement) moveWithHeatMap(ag *Agent) {
 	leastVisited := ag.HeatMap.GetLeastVisitedPositions(ag.Position, 3)
 
 	// 70% de chance d'aller vers une position moins visitée
-	if len(leastVisited) > 0 && rand.Float64() < 0.7 {
+	if len(leastVisited) > 0 && env.Rng.Float64() < 0.7 {
 		// Choisit aléatoirement une des positions les moins visitées
-		targetPos := leastVisited[rand.Intn(len(leastVisited))]
+		targetPos := leastVisited[env.Rng.IntN(len(leastVisited))]
 		// Calcule la direction vers la position choisie
 		dx := targetPos.X - ag.Position.X
 		dy := targetPos.Y - ag.Position.Y
@@ -217,7 +349,7 @@ func (env *Environnement) moveWithHeatMap(ag *Agent) {
 // Mouvement de patrouille pour les Neutrals
 func (env *Environnement) movePatrol(ag *Agent) {
 	// Possibilité de changer de direction même si vous n'avez pas atteint le waypoint
-	if ag.CurrentWaypoint != nil && rand.Float64() < 0.02 {
+	if ag.CurrentWaypoint != nil && env.Rng.Float64() < 0.02 {
 		ag.CurrentWaypoint = nil
 	}
 
@@ -234,7 +366,7 @@ func (env *Environnement) movePatrol(ag *Agent) {
 				choices := make([]ut.Position, 0, numChoices)
 
 				for i := 0; i < numChoices; i++ {
-					randomIdx := rand.Intn(len(ag.HeatMap.Positions))
+					randomIdx := env.Rng.IntN(len(ag.HeatMap.Positions))
 					pos := ag.HeatMap.Positions[randomIdx]
 
 					// Vérifie si le chemin vers le point est dégagé
@@ -264,7 +396,7 @@ func (env *Environnement) movePatrol(ag *Agent) {
 						obstacleScore := getObstacleAvoidanceScore(pos, env.Carte.Coliders)
 
 						// Combine les scores
-						randomFactor := 0.5 + rand.Float64()
+						randomFactor := 0.5 + env.Rng.Float64()
 						finalScore := (distScore*0.4 + obstacleScore*0.4) * randomFactor
 
 						if finalScore > bestScore {
@@ -296,12 +428,12 @@ func (env *Environnement) movePatrol(ag *Agent) {
 		dy := ag.CurrentWaypoint.Y - ag.Position.Y
 
 		// Réduit la variation aléatoire
-		dx += (rand.Float64()*2 - 1) * 2 // Réduit à ±2 pixels
-		dy += (rand.Float64()*2 - 1) * 2
+		dx += (env.Rng.Float64()*2 - 1) * 2 // Réduit à ±2 pixels
+		dy += (env.Rng.Float64()*2 - 1) * 2
 
 		length := math.Sqrt(dx*dx + dy*dy)
 		if length > 0 {
-			speed := ut.Maxspeed * (0.9 + rand.Float64()*0.2) // Vitesse plus constante
+			speed := ut.Maxspeed * (0.9 + env.Rng.Float64()*0.2) // Vitesse plus constante
 			ag.Position.Dx = (dx / length) * speed
 			ag.Position.Dy = (dy / length) * speed
 		}
@@ -363,7 +495,7 @@ func (env *Environnement) moveRandom(ag *Agent) {
 		{Dx: 0, Dy: -ut.Maxspeed},
 	}
 
-	randIdx := rand.Intn(len(directions))
+	randIdx := env.Rng.IntN(len(directions))
 	ag.Position.Dx = directions[randIdx].Dx
 	ag.Position.Dy = directions[randIdx].Dy
 }
@@ -421,8 +553,8 @@ func (env *Environnement) moveToCenterOfMass(ag *Agent) {
 	}
 
 	// Ajoute un élément de hasard pour éviter un regroupement parfait
-	centerX += (rand.Float64()*2 - 1) * 10
-	centerY += (rand.Float64()*2 - 1) * 10
+	centerX += (env.Rng.Float64()*2 - 1) * 10
+	centerY += (env.Rng.Float64()*2 - 1) * 10
 
 	// Calcule la direction vers le centre de masse
 	dx := centerX - ag.Position.X
@@ -432,64 +564,61 @@ func (env *Environnement) moveToCenterOfMass(ag *Agent) {
 	length := math.Sqrt(dx*dx + dy*dy)
 	if length > 0 {
 		// Varie la vitesse en fonction de la distance au centre de masse
-		speedFactor := 0.8 + rand.Float64()*0.4 // Vitesse entre 80% et 120% de la vitesse maximale
+		speedFactor := 0.8 + env.Rng.Float64()*0.4 // Vitesse entre 80% et 120% de la vitesse maximale
 		ag.Position.Dx = (dx / length) * ut.Maxspeed * speedFactor
 		ag.Position.Dy = (dy / length) * ut.Maxspeed * speedFactor
 	}
 
 	// Petite chance de passer à un mouvement aléatoire pour éviter un regroupement excessif
-	if rand.Float64() < 0.05 { // 5% de chance
+	if env.Rng.Float64() < 0.05 { // 5% de chance
 		env.moveRandom(ag)
 	}
 }
 
 // Fonction qui définit les poids des agents
 func (env *Environnement) SetPoids() {
-	minWeight := 0.01
-	maxWeight := 1.0
 	for _, ag := range env.Ags {
-		sum := 0.0
-		for _, ag2 := range env.Ags {
-			// Pour chaque agent déjà existant de l'environnement,
-			// on affect un poids absolu aléatoire et impacté par la relation entre agents
-			// et on calcule le poids relatif
-			ag.Poids_abs[ag2.ID()] = minWeight + rand.Float64()*(maxWeight-minWeight)*ag.Relation[ag2.ID()]
-			sum += ag.Poids_abs[ag2.Id]
+		env.setAgentPoids(ag)
+	}
+}
+
+// Fonction qui tire les poids absolus d'un agent envers tous les agents de l'environnement et calcule ses poids relatifs
+func (env *Environnement) setAgentPoids(ag *Agent) {
+	sum := 0.0
+	for _, ag2 := range env.Ags {
+		// Pour chaque agent déjà existant de l'environnement,
+		// on affect un poids absolu aléatoire et impacté par la relation entre agents
+		// et on calcule le poids relatif
+		ag.Poids_abs[ag2.ID()] = env.randomPoids(ag.Relation[ag2.ID()])
+		sum += ag.Poids_abs[ag2.Id]
+	}
+	// On applique la propriété de normalisation des poids absolus
+	for _, ag2 := range env.Ags {
+		pairAg := ut.Pair{}
+		if sum > 0 {
+			ag.Poids_abs[ag2.Id] = ag.Poids_abs[ag2.Id] / sum
+		} else {
+			ag.Poids_abs[ag2.Id] = 0
 		}
-		// On applique la propriété de normalisation des poids absolus
-		for _, ag2 := range env.Ags {
-			pairAg := ut.Pair{}
-			if sum > 0 {
-				ag.Poids_abs[ag2.Id] = ag.Poids_abs[ag2.Id] / sum
-			} else {
-				ag.Poids_abs[ag2.Id] = 0
-			}
-			if ag.Id != ag2.Id {
-				pairAg.Second = ag.Poids_abs[ag2.Id] / (ag.Poids_abs[ag2.Id] + ag.Poids_abs[ag.Id])
-				pairAg.First = ag.Poids_abs[ag.Id] / (ag.Poids_abs[ag2.Id] + ag.Poids_abs[ag.Id])
-			}
-			ag.Poids_rel[ag2.Id] = pairAg
+		if ag.Id != ag2.Id {
+			pairAg.Second = ag.Poids_abs[ag2.Id] / (ag.Poids_abs[ag2.Id] + ag.Poids_abs[ag.Id])
+			pairAg.First = ag.Poids_abs[ag.Id] / (ag.Poids_abs[ag2.Id] + ag.Poids_abs[ag.Id])
 		}
-
+		ag.Poids_rel[ag2.Id] = pairAg
 	}
 }
 
+// Fonction qui tire un poids absolu (non normalisé) impacté par la relation entre deux agents
+func (env *Environnement) randomPoids(relation float64) float64 {
+	return MinPoids + env.Rng.Float64()*(MaxPoids-MinPoids)*relation
+}
+
 // Fonction qui définie les relations entre agents
 func (env *Environnement) SetRelations() {
 	for _, ag := range env.Ags {
 		for _, ag2 := range env.Ags {
 			if ag.ID() != ag2.ID() {
-				close := rand.Float64()
-				switch {
-				case close < 0.25: //ennemi
-					ag.Relation[ag2.ID()] = 0.75
-				case close < 0.5: //pas de lien direct
-					ag.Relation[ag2.ID()] = 1
-				case close < 0.75: //amis
-					ag.Relation[ag2.ID()] = 1.25
-				case close < 1: //famille
-					ag.Relation[ag2.ID()] = 1.5
-				}
+				ag.Relation[ag2.ID()] = env.randomRelation()
 			} else {
 				ag.Relation[ag2.ID()] = 1
 			}
@@ -497,3 +626,18 @@ func (env *Environnement) SetRelations() {
 		}
 	}
 }
+
+// Fonction qui tire au hasard une relation entre deux agents
+func (env *Environnement) randomRelation() float64 {
+	close := env.Rng.Float64()
+	switch {
+	case close < 0.25: //ennemi
+		return 0.75
+	case close < 0.5: //pas de lien direct
+		return 1
+	case close < 0.75: //amis
+		return 1.25
+	default: //famille
+		return 1.5
+	}
+}
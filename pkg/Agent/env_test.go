@@ -0,0 +1,79 @@
+package pkg
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestSetPoidsNormalisation(t *testing.T) {
+	cases := []struct {
+		name      string
+		relations bool
+	}{
+		{"relations tirées au hasard", true},
+		{"sans relation", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			env := newTestEnv(7)
+			for i := 0; i < 10; i++ {
+				addTestAgent(env, fmt.Sprintf("Agent%d", i), float64(i)/10, 0, 0)
+			}
+			if c.relations {
+				env.SetRelations()
+			}
+			env.SetPoids()
+
+			for _, ag := range env.Ags {
+				// Les poids absolus d'un agent envers tous les agents forment une distribution
+				sum := 0.0
+				for _, ag2 := range env.Ags {
+					poids := ag.Poids_abs[ag2.Id]
+					if poids <= 0 || poids >= 1 {
+						t.Errorf("%s -> %s: poids absolu %.4f hors de ]0, 1[", ag.Id, ag2.Id, poids)
+					}
+					sum += poids
+				}
+				if math.Abs(sum-1) > epsilon {
+					t.Errorf("%s: somme des poids absolus %.6f, attendu 1", ag.Id, sum)
+				}
+
+				// Les poids relatifs partagent l'influence entre l'agent et son interlocuteur
+				for _, ag2 := range env.Ags {
+					pair := ag.Poids_rel[ag2.Id]
+					if ag.Id == ag2.Id {
+						if pair.First != 0 || pair.Second != 0 {
+							t.Errorf("%s: poids relatif envers lui-même %v", ag.Id, pair)
+						}
+						continue
+					}
+					if math.Abs(pair.First+pair.Second-1) > epsilon || pair.First <= 0 || pair.Second <= 0 {
+						t.Errorf("%s -> %s: poids relatifs %v", ag.Id, ag2.Id, pair)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestSetRelationsValues(t *testing.T) {
+	env := newTestEnv(7)
+	for i := 0; i < 10; i++ {
+		addTestAgent(env, fmt.Sprintf("Agent%d", i), 0.5, 0, 0)
+	}
+	env.SetRelations()
+
+	allowed := map[float64]bool{0.75: true, 1: true, 1.25: true, 1.5: true}
+	for _, ag := range env.Ags {
+		for _, ag2 := range env.Ags {
+			relation := ag.Relation[ag2.Id]
+			if ag.Id == ag2.Id && relation != 1 {
+				t.Errorf("%s: relation avec lui-même %.2f, attendu 1", ag.Id, relation)
+			}
+			if !allowed[relation] {
+				t.Errorf("%s -> %s: relation inattendue %.2f", ag.Id, ag2.Id, relation)
+			}
+		}
+	}
+}
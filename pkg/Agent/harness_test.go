@@ -0,0 +1,130 @@
+package pkg
+
+import (
+	"fmt"
+	"image"
+	"testing"
+
+	carte "github.com/Tmegaa/The-Gophecy/pkg/Carte"
+	events "github.com/Tmegaa/The-Gophecy/pkg/Events"
+	tile "github.com/Tmegaa/The-Gophecy/pkg/Tile"
+	ut "github.com/Tmegaa/The-Gophecy/pkg/Utilitaries"
+)
+
+// Étendue de la salle de test: une salle vide, sans obstacle ni objet
+const (
+	testWorldWidth  = 640
+	testWorldHeight = 480
+)
+
+// Fonction qui crée un environnement de test: une salle vide dont les cases d'apparition forment une grille de 48 pixels,
+// un générateur à graine fixe et une horloge qui n'avance que par testStep
+func newTestEnv(seed uint64) *Environnement {
+	var spawns []image.Rectangle
+	for y := 24; y+24 <= testWorldHeight; y += 48 {
+		for x := 24; x+24 <= testWorldWidth; x += 48 {
+			spawns = append(spawns, image.Rect(x, y, x+24, y+24))
+		}
+	}
+	room := carte.NewCarte(tile.TilemapJSON{}, nil, nil, nil, spawns, nil, image.Rect(0, 0, testWorldWidth, testWorldHeight))
+	env := NewEnvironment(make([]*Agent, 0), room, make([]InterfaceObjet, 0))
+	env.Rng = ut.NewRng(seed)
+	return env
+}
+
+// Sink qui garde en mémoire les évènements émis, pour les vérifier
+type recordingSink struct {
+	events []events.Event
+}
+
+// Fonction qui enregistre un évènement
+func (s *recordingSink) Write(e events.Event) error {
+	s.events = append(s.events, e)
+	return nil
+}
+
+// Fonction de fermeture: il n'y a rien à libérer
+func (s *recordingSink) Close() error { return nil }
+
+// Fonction qui branche un sink d'enregistrement sur le bus d'évènements de l'environnement
+func recordEvents(env *Environnement) *recordingSink {
+	sink := &recordingSink{}
+	env.Events = events.NewBus(sink)
+	return sink
+}
+
+// Fonction qui renvoie les évènements enregistrés d'un type donné
+func (s *recordingSink) ofKind(kind events.Kind) []events.Event {
+	var found []events.Event
+	for _, e := range s.events {
+		if e.Kind == kind {
+			found = append(found, e)
+		}
+	}
+	return found
+}
+
+// Fonction qui renvoie le type correspondant à une opinion, comme CheckType
+func typeForOpinion(opinion float64) TypeAgent {
+	switch {
+	case opinion > 2./3.:
+		return Believer
+	case opinion > 1./3.:
+		return Neutral
+	default:
+		return Sceptic
+	}
+}
+
+// Fonction qui ajoute à l'environnement un agent d'opinion donnée, à la position (x, y)
+func addTestAgent(env *Environnement, id string, opinion, x, y float64) *Agent {
+	agent := NewAgent(env, IdAgent(id), 1, 50, ut.Position{X: x, Y: y}, opinion,
+		make(map[IdAgent]float64), make(map[IdAgent]float64), 1, typeForOpinion(opinion), make(chan Message))
+	env.AddAgent(agent)
+	return agent
+}
+
+// Fonction qui fait avancer l'environnement d'un tick sans goroutine: mise à jour des minuteurs comme dans la simulation,
+// puis perception, délibération et action de chaque agent dans l'ordre, les déplacements étant traités aussitôt.
+// Avec une graine fixe, deux exécutions donnent donc exactement le même résultat
+func testStep(env *Environnement) {
+	env.AdvanceTick(env.UpdateTimers)
+
+	for _, agent := range env.Ags {
+		agent.AgentProximity = env.NearbyAgents(agent)
+		agent.ObjsProximity = env.NearbyObjects(agent)
+		choice := agent.Deliberate(env, agent.AgentProximity, agent.ObjsProximity)
+		agent.Act(env, choice)
+		for len(env.Communication) > 0 {
+			msg := <-env.Communication
+			if msg.Type == MoveMsg {
+				env.Move(msg.Agent)
+			}
+		}
+	}
+}
+
+// Fonction qui charge un jeu de données du dossier tests dans un environnement de test, comme la simulation
+func loadDataset(t *testing.T, env *Environnement, name string) {
+	t.Helper()
+	if _, err := CreateAgentsFromFile(env, "../../tests/"+name); err != nil {
+		t.Fatalf("échec du chargement du jeu de données: %v", err)
+	}
+}
+
+// Fonction qui renvoie l'opinion moyenne et le nombre d'agents de chaque type
+func opinionSummary(env *Environnement) (float64, map[TypeAgent]int) {
+	counts := make(map[TypeAgent]int)
+	sum := 0.0
+	for _, agent := range env.Ags {
+		sum += agent.Opinion
+		counts[agent.TypeAgt]++
+	}
+	return sum / float64(len(env.Ags)), counts
+}
+
+// Fonction qui décrit l'état des opinions, pour les messages d'erreur
+func describeOpinions(env *Environnement) string {
+	mean, counts := opinionSummary(env)
+	return fmt.Sprintf("moyenne %.3f, %d sceptiques, %d neutres, %d croyants", mean, counts[Sceptic], counts[Neutral], counts[Believer])
+}
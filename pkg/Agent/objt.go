@@ -4,8 +4,6 @@ import (
 	"sync"
 
 	pos "github.com/Tmegaa/The-Gophecy/pkg/Utilitaries"
-
-	"github.com/hajimehoshi/ebiten/v2"
 )
 
 // InterfaceObjet définit un comportement commun pour tous les objets
@@ -15,7 +13,6 @@ type InterfaceObjet interface {
 	GetUse() bool
 	GetProgramm() Programm
 	GetType() TypeObjet
-	GetImg() *ebiten.Image
 }
 
 // On définit les différents langages de programmation possibles: on pourra élargir par la suite
@@ -43,7 +40,6 @@ type Objet struct {
 	Id       IdObjet
 	Position pos.Position
 	Programm Programm
-	Img      *ebiten.Image
 	Used     bool
 	Type     TypeObjet
 }
@@ -63,9 +59,6 @@ func (o *Objet) GetProgramm() Programm { return o.Programm }
 // Fonction qui renvoie le type d'un objet
 func (o *Objet) GetType() TypeObjet { return o.Type }
 
-// Fonction qui renvoie l'image d'un objet
-func (o *Objet) GetImg() *ebiten.Image { return o.Img }
-
 // L'ordinateur est un type spécifique d'objet
 type Computer struct {
 	Objet
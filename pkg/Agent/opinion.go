@@ -0,0 +1,36 @@
+package pkg
+
+import "math"
+
+// Modèle de dynamique d'opinion utilisé lors des discussions
+type OpinionModel string
+
+const (
+	GophecyModel           OpinionModel = "Gophétie"         // modèle d'origine: effet des types et équations logistiques
+	DeGrootModel           OpinionModel = "DeGroot"          // moyenne pondérée par les poids relatifs
+	BoundedConfidenceModel OpinionModel = "Confiance bornée" // modèle de Deffuant: seules les opinions proches se rapprochent
+)
+
+// Liste des modèles d'opinion disponibles
+var OpinionModels = []OpinionModel{GophecyModel, DeGrootModel, BoundedConfidenceModel}
+
+// Paramètres du modèle de confiance bornée
+const (
+	ConfidenceBound      = 0.3 // écart d'opinion maximal pour que deux agents s'influencent
+	ConvergenceParameter = 0.3 // part de l'écart parcourue par chaque agent lors d'une discussion
+)
+
+// Fonction qui applique le modèle de la moyenne pondérée de DeGroot à deux agents en discussion
+func degrootOpinions(ag, ag2 *Agent) (float64, float64) {
+	rel, rel2 := ag.Poids_rel[ag2.Id], ag2.Poids_rel[ag.Id]
+	return rel.First*ag.Opinion + rel.Second*ag2.Opinion, rel2.Second*ag.Opinion + rel2.First*ag2.Opinion
+}
+
+// Fonction qui applique le modèle de confiance bornée de Deffuant à deux agents en discussion
+func boundedConfidenceOpinions(ag, ag2 *Agent) (float64, float64) {
+	gap := ag2.Opinion - ag.Opinion
+	if math.Abs(gap) >= ConfidenceBound {
+		return ag.Opinion, ag2.Opinion
+	}
+	return ag.Opinion + ConvergenceParameter*gap, ag2.Opinion - ConvergenceParameter*gap
+}
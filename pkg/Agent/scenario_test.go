@@ -0,0 +1,73 @@
+package pkg
+
+import "testing"
+
+// Durée des scénarios (en ticks): les opinions se sont stabilisées bien avant la fin d'une simulation de 5 minutes
+const scenarioTicks = 6000
+
+// Fonction qui joue un jeu de données du dossier tests dans la salle de test et renvoie l'environnement final
+func runScenario(t *testing.T, dataset string, seed uint64) *Environnement {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("scénario long")
+	}
+	env := newTestEnv(seed)
+	loadDataset(t, env, dataset)
+	for i := 0; i < scenarioTicks; i++ {
+		testStep(env)
+	}
+	return env
+}
+
+// Agents dont la plupart ont un paramètre personnel inférieur à 2: la population tend vers le scepticisme
+func TestScenarioScepticDrift(t *testing.T) {
+	env := runScenario(t, "agents_1.json", 1)
+	mean, counts := opinionSummary(env)
+	if mean > 0.4 || counts[Sceptic] < len(env.Ags)/2 || counts[Believer] > len(env.Ags)/10 {
+		t.Errorf("la population ne tend pas vers le scepticisme: %s", describeOpinions(env))
+	}
+}
+
+// Paramètres personnels entre 3 et 4: la population tend vers la croyance
+func TestScenarioBelieverDrift(t *testing.T) {
+	env := runScenario(t, "agents_2.json", 1)
+	mean, counts := opinionSummary(env)
+	if mean < 0.6 || counts[Believer] < len(env.Ags)/2 || counts[Sceptic] > len(env.Ags)/20 {
+		t.Errorf("la population ne tend pas vers la croyance: %s", describeOpinions(env))
+	}
+}
+
+// Paramètres personnels élevés: beaucoup de sceptiques et de croyants, très peu de neutres
+func TestScenarioPolarization(t *testing.T) {
+	env := runScenario(t, "agents_3.json", 1)
+	_, counts := opinionSummary(env)
+	if counts[Sceptic] < len(env.Ags)*3/10 || counts[Believer] < len(env.Ags)*3/10 || counts[Neutral] > len(env.Ags)/4 {
+		t.Errorf("la population n'est pas polarisée: %s", describeOpinions(env))
+	}
+}
+
+// Avec la même graine, l'horloge de test rejoue exactement la même simulation
+func TestScenarioDeterministic(t *testing.T) {
+	if testing.Short() {
+		t.Skip("scénario long")
+	}
+	run := func() []float64 {
+		env := newTestEnv(3)
+		loadDataset(t, env, "agents_3.json")
+		for i := 0; i < 500; i++ {
+			testStep(env)
+		}
+		opinions := make([]float64, len(env.Ags))
+		for i, agent := range env.Ags {
+			opinions[i] = agent.Opinion
+		}
+		return opinions
+	}
+
+	first, second := run(), run()
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("agent %d: opinions %.6f et %.6f pour la même graine", i, first[i], second[i])
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	carte "github.com/Tmegaa/The-Gophecy/pkg/Carte"
+	ut "github.com/Tmegaa/The-Gophecy/pkg/Utilitaries"
+)
+
+// Version du format des sauvegardes: à incrémenter à chaque modification incompatible
+const SnapshotVersion = 3
+
+// Sauvegarde complète de l'état d'un environnement
+type Snapshot struct {
+	Version        int                  `json:"version"`
+	Map            string               `json:"map"` // carte de la simulation, renseignée par l'appelant
+	Tick           int64                `json:"tick"`
+	Rng            []byte               `json:"rng"`
+	OpinionModel   OpinionModel         `json:"opinionModel,omitempty"`
+	NbrAgents      map[TypeAgent]int    `json:"nbrAgents"`
+	Agents         []AgentSnapshot      `json:"agents"`
+	Computers      []ObjetSnapshot      `json:"computers"`
+	Statues        []ObjetSnapshot      `json:"statues"`
+	VisitationMaps []VisitationSnapshot `json:"visitationMaps"`
+}
+
+// Sauvegarde d'un agent: les références vers d'autres agents ou objets sont remplacées par leurs identifiants
+type AgentSnapshot struct {
+	Id                IdAgent             `json:"id"`
+	Velocite          float64             `json:"velocite"`
+	Acuite            float64             `json:"acuite"`
+	Position          ut.Position         `json:"position"`
+	Opinion           float64             `json:"opinion"`
+	Charisme          map[IdAgent]float64 `json:"charisme"`
+	Relation          map[IdAgent]float64 `json:"relation"`
+	PersonalParameter float64             `json:"personalParameter"`
+	Poids_rel         map[IdAgent]ut.Pair `json:"poidsRel"`
+	Poids_abs         map[IdAgent]float64 `json:"poidsAbs"`
+	Vivant            bool                `json:"vivant"`
+	TypeAgt           TypeAgent           `json:"type"`
+	SubType           SubTypeAgent        `json:"subType"`
+	MoveTimer         int                 `json:"moveTimer"`
+	CurrentAction     ActionType          `json:"currentAction"`
+	DialogTimer       int                 `json:"dialogTimer"`
+	Occupied          bool                `json:"occupied"`
+	UseComputer       IdObjet             `json:"useComputer,omitempty"`
+	LastComputer      IdObjet             `json:"lastComputer,omitempty"`
+	LastStatue        IdObjet             `json:"lastStatue,omitempty"`
+	TickLastStatue    int64               `json:"tickLastStatue"`
+	HeatMap           int                 `json:"heatMap"` // index dans Snapshot.VisitationMaps, -1 si aucune
+	CurrentWaypoint   *ut.Position        `json:"currentWaypoint,omitempty"`
+	MovementStrategy  MovementStrategy    `json:"movementStrategy"`
+	DiscussingWith    IdAgent             `json:"discussingWith,omitempty"`
+	LastTalkedTo      []IdAgent           `json:"lastTalkedTo"`
+	MaxLastTalked     int                 `json:"maxLastTalked"`
+}
+
+// Sauvegarde d'un objet (ordinateur ou statue)
+type ObjetSnapshot struct {
+	Id       IdObjet     `json:"id"`
+	Position ut.Position `json:"position"`
+	Programm Programm    `json:"programm"`
+	Used     bool        `json:"used"`
+}
+
+// Sauvegarde d'une carte des positions visitées
+type VisitationSnapshot struct {
+	Positions []ut.Position `json:"positions"`
+	Visits    map[int]int   `json:"visits"`
+}
+
+// Fonction qui sauvegarde l'état de l'environnement: l'appelant doit s'assurer que les agents ne sont pas modifiés pendant la sauvegarde
+func (env *Environnement) Snapshot() (*Snapshot, error) {
+	rng, err := env.Rng.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	snap := &Snapshot{
+		Version:      SnapshotVersion,
+		Tick:         env.Tick(),
+		Rng:          rng,
+		OpinionModel: env.OpinionModel,
+		NbrAgents:    make(map[TypeAgent]int),
+		Agents:       make([]AgentSnapshot, 0, len(env.Ags)),
+	}
+
+	env.NbrAgents.Range(func(key, value any) bool {
+		snap.NbrAgents[key.(TypeAgent)] = value.(int)
+		return true
+	})
+
+	// Les cartes de visites peuvent être partagées par plusieurs agents: on ne les sauvegarde qu'une fois
+	heatMaps := make(map[*VisitationMap]int)
+	for _, ag := range env.Ags {
+		heatMap := -1
+		if ag.HeatMap != nil {
+			idx, ok := heatMaps[ag.HeatMap]
+			if !ok {
+				idx = len(snap.VisitationMaps)
+				heatMaps[ag.HeatMap] = idx
+				snap.VisitationMaps = append(snap.VisitationMaps, ag.HeatMap.snapshot())
+			}
+			heatMap = idx
+		}
+		snap.Agents = append(snap.Agents, ag.snapshot(heatMap))
+	}
+
+	for _, obj := range env.Objs {
+		o := ObjetSnapshot{Id: obj.ID(), Position: obj.ObjPosition(), Programm: obj.GetProgramm(), Used: obj.GetUse()}
+		switch obj.GetType() {
+		case ComputerType:
+			snap.Computers = append(snap.Computers, o)
+		case StatueType:
+			snap.Statues = append(snap.Statues, o)
+		}
+	}
+	return snap, nil
+}
+
+// Fonction qui sauvegarde l'état d'un agent
+func (ag *Agent) snapshot(heatMap int) AgentSnapshot {
+	s := AgentSnapshot{
+		Id:                ag.Id,
+		Velocite:          ag.Velocite,
+		Acuite:            ag.Acuite,
+		Position:          ag.Position,
+		Opinion:           ag.Opinion,
+		Charisme:          ag.Charisme,
+		Relation:          ag.Relation,
+		PersonalParameter: ag.PersonalParameter,
+		Poids_rel:         ag.Poids_rel,
+		Poids_abs:         ag.Poids_abs,
+		Vivant:            ag.Vivant,
+		TypeAgt:           ag.TypeAgt,
+		SubType:           ag.SubType,
+		MoveTimer:         ag.MoveTimer,
+		CurrentAction:     ag.CurrentAction,
+		DialogTimer:       ag.DialogTimer,
+		Occupied:          ag.Occupied,
+		TickLastStatue:    ag.TickLastStatue,
+		HeatMap:           heatMap,
+		CurrentWaypoint:   ag.CurrentWaypoint,
+		MovementStrategy:  ag.MovementStrategy,
+		LastTalkedTo:      make([]IdAgent, 0, len(ag.LastTalkedTo)),
+		MaxLastTalked:     ag.MaxLastTalked,
+	}
+	if ag.UseComputer != nil {
+		s.UseComputer = ag.UseComputer.ID()
+	}
+	if ag.LastComputer != nil {
+		s.LastComputer = ag.LastComputer.ID()
+	}
+	if ag.LastStatue != nil {
+		s.LastStatue = ag.LastStatue.ID()
+	}
+	if ag.DiscussingWith != nil {
+		s.DiscussingWith = ag.DiscussingWith.Id
+	}
+	for _, other := range ag.LastTalkedTo {
+		s.LastTalkedTo = append(s.LastTalkedTo, other.Id)
+	}
+	return s
+}
+
+// Fonction qui sauvegarde une carte des positions visitées
+func (vm *VisitationMap) snapshot() VisitationSnapshot {
+	vm.mutex.RLock()
+	defer vm.mutex.RUnlock()
+
+	visits := make(map[int]int, len(vm.Visits))
+	for k, v := range vm.Visits {
+		visits[k] = v
+	}
+	return VisitationSnapshot{Positions: vm.Positions, Visits: visits}
+}
+
+// Fonction qui reconstruit un environnement équivalent à partir d'une sauvegarde et de la carte sur laquelle il évolue
+func RestoreEnvironment(snap *Snapshot, carte *carte.Carte) (*Environnement, error) {
+	if snap.Version != SnapshotVersion {
+		return nil, fmt.Errorf("version de sauvegarde non supportée: %d (attendue: %d)", snap.Version, SnapshotVersion)
+	}
+
+	env := NewEnvironment(make([]*Agent, 0, len(snap.Agents)), carte, make([]InterfaceObjet, 0))
+	if err := env.Rng.UnmarshalBinary(snap.Rng); err != nil {
+		return nil, fmt.Errorf("état du générateur aléatoire invalide: %v", err)
+	}
+	env.tick.Store(snap.Tick)
+	env.OpinionModel = snap.OpinionModel
+	for t, n := range snap.NbrAgents {
+		env.NbrAgents.Store(t, n)
+	}
+
+	// Objets
+	computers := make(map[IdObjet]*Computer)
+	for _, o := range snap.Computers {
+		c := NewComputer(env, o.Id, o.Position)
+		c.Programm = o.Programm
+		c.Used = o.Used
+		computers[o.Id] = c
+		env.Objs = append(env.Objs, c)
+	}
+	statues := make(map[IdObjet]*Statue)
+	for _, o := range snap.Statues {
+		s := NewStatue(env, o.Id, o.Position)
+		statues[o.Id] = s
+		env.Objs = append(env.Objs, s)
+	}
+
+	// Cartes des positions visitées
+	heatMaps := make([]*VisitationMap, len(snap.VisitationMaps))
+	for i, vs := range snap.VisitationMaps {
+		heatMaps[i] = &VisitationMap{Positions: vs.Positions, Visits: vs.Visits}
+	}
+
+	// Premier passage: création des agents
+	agents := make(map[IdAgent]*Agent, len(snap.Agents))
+	for _, s := range snap.Agents {
+		ag := &Agent{
+			Env:               env,
+			Id:                s.Id,
+			Velocite:          s.Velocite,
+			Acuite:            s.Acuite,
+			Position:          s.Position,
+			Opinion:           s.Opinion,
+			Charisme:          s.Charisme,
+			Relation:          s.Relation,
+			PersonalParameter: s.PersonalParameter,
+			Poids_rel:         s.Poids_rel,
+			Poids_abs:         s.Poids_abs,
+			Vivant:            s.Vivant,
+			TypeAgt:           s.TypeAgt,
+			SubType:           s.SubType,
+			SyncChan:          make(chan Message),
+			MoveTimer:         s.MoveTimer,
+			CurrentAction:     s.CurrentAction,
+			DialogTimer:       s.DialogTimer,
+			Occupied:          s.Occupied,
+			AgentProximity:    make([]*Agent, 0),
+			ObjsProximity:     make([]*InterfaceObjet, 0),
+			UseComputer:       computers[s.UseComputer],
+			LastComputer:      computers[s.LastComputer],
+			LastStatue:        statues[s.LastStatue],
+			TickLastStatue:    s.TickLastStatue,
+			CurrentWaypoint:   s.CurrentWaypoint,
+			MovementStrategy:  s.MovementStrategy,
+			LastTalkedTo:      make([]*Agent, 0, len(s.LastTalkedTo)),
+			MaxLastTalked:     s.MaxLastTalked,
+		}
+		if s.HeatMap >= 0 && s.HeatMap < len(heatMaps) {
+			ag.HeatMap = heatMaps[s.HeatMap]
+		}
+		agents[s.Id] = ag
+		env.Ags = append(env.Ags, ag)
+	}
+
+	// Deuxième passage: références entre agents
+	for _, s := range snap.Agents {
+		ag := agents[s.Id]
+		ag.DiscussingWith = agents[s.DiscussingWith]
+		for _, id := range s.LastTalkedTo {
+			if other, ok := agents[id]; ok {
+				ag.LastTalkedTo = append(ag.LastTalkedTo, other)
+			}
+		}
+	}
+
+	return env, nil
+}
+
+// Fonction qui écrit une sauvegarde dans un fichier JSON
+func (snap *Snapshot) Save(path string) error {
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Fonction qui lit une sauvegarde depuis un fichier JSON
+func LoadSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("échec de la lecture de la sauvegarde: %v", err)
+	}
+
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("échec de l'analyse de la sauvegarde: %v", err)
+	}
+	// Une sauvegarde d'un autre format ne désigne pas forcément sa carte: elle est refusée dès la lecture
+	if snap.Version != SnapshotVersion {
+		return nil, fmt.Errorf("version de sauvegarde non supportée: %d (attendue: %d)", snap.Version, SnapshotVersion)
+	}
+	return &snap, nil
+}
@@ -90,3 +90,13 @@ func (vm *VisitationMap) findNearestPositionIndex(pos ut.Position) int {
 
 	return nearestIdx
 }
+
+// Fonction qui parcourt les positions de la carte avec leur nombre de visites
+func (vm *VisitationMap) ForEach(fn func(pos ut.Position, count int)) {
+	vm.mutex.RLock()
+	defer vm.mutex.RUnlock()
+
+	for idx, count := range vm.Visits {
+		fn(vm.Positions[idx], count)
+	}
+}
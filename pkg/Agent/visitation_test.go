@@ -0,0 +1,67 @@
+package pkg
+
+import (
+	"testing"
+
+	ut "github.com/Tmegaa/The-Gophecy/pkg/Utilitaries"
+)
+
+// Fonction qui crée une carte des visites sur une ligne de positions espacées de 24 pixels
+func newTestVisitationMap(n int) *VisitationMap {
+	positions := make([]ut.Position, n)
+	for i := range positions {
+		positions[i] = ut.Position{X: float64(i * 24)}
+	}
+	return NewVisitationMap(positions)
+}
+
+func TestVisitationMapIncrementNearest(t *testing.T) {
+	vm := newTestVisitationMap(4)
+	vm.IncrementVisit(ut.Position{X: 30})
+	vm.IncrementVisit(ut.Position{X: 20})
+	vm.IncrementVisit(ut.Position{X: 70, Y: 5})
+
+	want := map[int]int{0: 0, 1: 2, 2: 0, 3: 1}
+	for idx, count := range want {
+		if vm.Visits[idx] != count {
+			t.Errorf("position %d: %d visites, attendu %d", idx, vm.Visits[idx], count)
+		}
+	}
+}
+
+func TestVisitationMapLeastVisited(t *testing.T) {
+	vm := newTestVisitationMap(5)
+	for _, x := range []float64{0, 0, 24, 48, 72, 72, 96} {
+		vm.IncrementVisit(ut.Position{X: x})
+	}
+
+	// Les positions visitées une fois passent en premier, de la plus proche à la plus éloignée
+	got := vm.GetLeastVisitedPositions(ut.Position{X: 90}, 3)
+	want := []float64{96, 48, 24}
+	if len(got) != len(want) {
+		t.Fatalf("%d positions, attendu %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].X != want[i] {
+			t.Errorf("position %d: x = %.0f, attendu %.0f", i, got[i].X, want[i])
+		}
+	}
+
+	if got := vm.GetLeastVisitedPositions(ut.Position{}, 10); len(got) != 5 {
+		t.Errorf("%d positions renvoyées pour une limite de 10, attendu les 5 de la carte", len(got))
+	}
+}
+
+func TestVisitationMapForEach(t *testing.T) {
+	vm := newTestVisitationMap(3)
+	vm.IncrementVisit(ut.Position{X: 48})
+
+	total, seen := 0, 0
+	vm.ForEach(func(pos ut.Position, count int) {
+		seen++
+		total += count
+	})
+	if seen != 3 || total != 1 {
+		t.Errorf("%d positions parcourues et %d visites, attendu 3 et 1", seen, total)
+	}
+}
@@ -0,0 +1,19 @@
+package pkg
+
+import carte "github.com/Tmegaa/The-Gophecy/pkg/Carte"
+
+// Fonction qui renvoie la zone de la carte où se trouve le centre de l'agent, ou nil s'il n'est dans aucune zone
+func (ag *Agent) Zone() *carte.Zone {
+	if ag.Env == nil || ag.Env.Carte == nil {
+		return nil
+	}
+	return ag.Env.Carte.ZoneAt(ag.Position.X+AgentSize/2, ag.Position.Y+AgentSize/2)
+}
+
+// Fonction qui renvoie les règles de la zone où se trouve l'agent
+func (ag *Agent) zoneRules() carte.ZoneRules {
+	if zone := ag.Zone(); zone != nil {
+		return zone.Rules
+	}
+	return carte.NoRules
+}
@@ -0,0 +1,190 @@
+package analysis
+
+import (
+	"math"
+	"sort"
+)
+
+// Paramètres par défaut des indicateurs
+const (
+	DefaultBins           = 10   // nombre de classes pour l'entropie et la polarisation
+	DefaultAlpha          = 1.6  // sensibilité à la polarisation (Esteban–Ray), entre 0 et 1.6
+	DefaultClusterGap     = 0.1  // écart minimal entre deux groupes d'opinion
+	DefaultConsensusSigma = 0.05 // écart-type en dessous duquel on considère qu'il y a consensus
+)
+
+// Indicateurs calculés sur une population d'opinions
+type Indicators struct {
+	Bimodality    float64 // coefficient de bimodalité (> 5/9 suggère une distribution bimodale)
+	Polarization  float64 // polarisation d'Esteban–Ray
+	Entropy       float64 // entropie des opinions normalisée entre 0 et 1
+	Clusters      int     // nombre de groupes d'opinion
+	Assortativity float64 // assortativité des opinions sur le réseau des relations
+}
+
+// Fonction qui calcule les indicateurs qui ne dépendent que des opinions
+func Compute(opinions []float64) Indicators {
+	return Indicators{
+		Bimodality:   BimodalityCoefficient(opinions),
+		Polarization: EstebanRay(opinions, DefaultAlpha, DefaultBins),
+		Entropy:      Entropy(opinions, DefaultBins),
+		Clusters:     Clusters(opinions, DefaultClusterGap),
+	}
+}
+
+// Fonction qui calcule le coefficient de bimodalité de Sarle à partir de l'asymétrie et du kurtosis corrigés
+func BimodalityCoefficient(values []float64) float64 {
+	n := float64(len(values))
+	if n < 4 {
+		return 0
+	}
+
+	mean := 0.0
+	for _, v := range values {
+		mean += v
+	}
+	mean /= n
+
+	var m2, m3, m4 float64
+	for _, v := range values {
+		d := v - mean
+		m2 += d * d
+		m3 += d * d * d
+		m4 += d * d * d * d
+	}
+	m2 /= n
+	m3 /= n
+	m4 /= n
+	if m2 == 0 {
+		return 0
+	}
+
+	// Asymétrie et excès de kurtosis corrigés du biais d'échantillonnage
+	g := m3 / math.Pow(m2, 1.5)
+	k := m4/(m2*m2) - 3
+	skew := g * math.Sqrt(n*(n-1)) / (n - 2)
+	kurt := ((n+1)*k + 6) * (n - 1) / ((n - 2) * (n - 3))
+
+	return (skew*skew + 1) / (kurt + 3*(n-1)*(n-1)/((n-2)*(n-3)))
+}
+
+// Fonction qui calcule la polarisation d'Esteban–Ray: les opinions sont regroupées en classes de
+// population π_i et de centre y_i, et P = Σ_i Σ_j π_i^(1+α) π_j |y_i - y_j|
+func EstebanRay(values []float64, alpha float64, bins int) float64 {
+	shares := histogramShares(values, bins)
+
+	polarization := 0.0
+	for i, pi := range shares {
+		if pi == 0 {
+			continue
+		}
+		yi := (float64(i) + 0.5) / float64(bins)
+		for j, pj := range shares {
+			yj := (float64(j) + 0.5) / float64(bins)
+			polarization += math.Pow(pi, 1+alpha) * pj * math.Abs(yi-yj)
+		}
+	}
+	return polarization
+}
+
+// Fonction qui calcule l'entropie de Shannon des opinions, normalisée par le logarithme du nombre de classes
+func Entropy(values []float64, bins int) float64 {
+	if bins < 2 {
+		return 0
+	}
+	entropy := 0.0
+	for _, p := range histogramShares(values, bins) {
+		if p > 0 {
+			entropy -= p * math.Log(p)
+		}
+	}
+	return entropy / math.Log(float64(bins))
+}
+
+// Fonction qui compte le nombre de groupes d'opinion: deux opinions consécutives appartiennent
+// au même groupe si elles sont séparées de moins de "gap"
+func Clusters(values []float64, gap float64) int {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	clusters := 1
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i]-sorted[i-1] > gap {
+			clusters++
+		}
+	}
+	return clusters
+}
+
+// Fonction qui renvoie le premier tick à partir duquel l'écart-type des opinions reste sous le seuil
+// jusqu'à la fin de la série. Le booléen est faux si le consensus n'est jamais atteint.
+func TimeToConsensus(ticks []int64, variances []float64, sigma float64) (int64, bool) {
+	threshold := sigma * sigma
+	first := -1
+	for i, v := range variances {
+		if v <= threshold {
+			if first < 0 {
+				first = i
+			}
+		} else {
+			first = -1
+		}
+	}
+	if first < 0 || first >= len(ticks) {
+		return 0, false
+	}
+	return ticks[first], true
+}
+
+// Fonction qui calcule l'assortativité des opinions sur le réseau des relations: c'est la corrélation
+// de Pearson pondérée des opinions aux deux extrémités de chaque lien. Seuls les liens d'amitié et de
+// famille (relation > 1) sont pris en compte, pondérés par la valeur de la relation.
+func Assortativity[K comparable](opinions map[K]float64, relations map[K]map[K]float64) float64 {
+	var sumW, sumX, sumXX, sumXY float64
+	for a, rel := range relations {
+		xa, ok := opinions[a]
+		if !ok {
+			continue
+		}
+		for b, w := range rel {
+			xb, ok := opinions[b]
+			if !ok || a == b || w <= 1 {
+				continue
+			}
+			// Chaque lien orienté contribue de façon symétrique
+			sumW += w
+			sumX += w * (xa + xb) / 2
+			sumXX += w * (xa*xa + xb*xb) / 2
+			sumXY += w * xa * xb
+		}
+	}
+	if sumW == 0 {
+		return 0
+	}
+
+	mean := sumX / sumW
+	variance := sumXX/sumW - mean*mean
+	if variance <= 0 {
+		return 0
+	}
+	return (sumXY/sumW - mean*mean) / variance
+}
+
+// Fonction qui renvoie la part de la population dans chaque classe d'opinion sur [0, 1]
+func histogramShares(values []float64, bins int) []float64 {
+	shares := make([]float64, bins)
+	if len(values) == 0 || bins < 1 {
+		return shares
+	}
+	for _, v := range values {
+		idx := max(0, min(bins-1, int(v*float64(bins))))
+		shares[idx]++
+	}
+	for i := range shares {
+		shares[i] /= float64(len(values))
+	}
+	return shares
+}
@@ -0,0 +1,171 @@
+package capture
+
+import (
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"os"
+	"path/filepath"
+	"time"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+// Format d'enregistrement des images capturées
+type Format string
+
+const (
+	PNG Format = "png" // une image PNG par capture, dans un dossier
+	GIF Format = "gif" // une animation GIF regroupant toutes les captures
+)
+
+// Paramètres par défaut de l'enregistrement
+const (
+	DefaultGIFWidth = 960 // largeur maximale des images d'une animation GIF (les captures plus larges sont réduites)
+	MaxGIFFrames    = 600 // nombre maximal d'images d'une animation GIF, gardées en mémoire jusqu'à la fin de l'enregistrement
+	FrameBuffer     = 8   // nombre de captures en attente d'encodage
+)
+
+// Fonction qui convertit le nom d'un format
+func ParseFormat(name string) (Format, error) {
+	switch Format(name) {
+	case PNG, GIF:
+		return Format(name), nil
+	default:
+		return "", fmt.Errorf("format de capture inconnu: %s (png ou gif)", name)
+	}
+}
+
+// Enregistreur de captures: les images sont encodées dans une goroutine pour ne pas ralentir l'affichage
+type Recorder struct {
+	Path     string // dossier des images PNG ou fichier de l'animation GIF
+	format   Format
+	delay    int // durée d'affichage d'une image de l'animation (en centièmes de seconde)
+	maxWidth int
+	frames   chan image.Image
+	done     chan error
+	count    int
+}
+
+// Création d'un enregistreur: path est un dossier pour le format PNG et un fichier pour le format GIF,
+// interval est la durée séparant deux captures, utilisée pour la vitesse de l'animation
+func NewRecorder(path string, format Format, interval time.Duration, maxWidth int) (*Recorder, error) {
+	if format == PNG {
+		if err := os.MkdirAll(path, 0o755); err != nil {
+			return nil, fmt.Errorf("échec de la création du dossier de captures: %v", err)
+		}
+	} else if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("échec de la création du dossier de captures: %v", err)
+	}
+
+	r := &Recorder{
+		Path:     path,
+		format:   format,
+		delay:    max(1, int(interval/(10*time.Millisecond))),
+		maxWidth: maxWidth,
+		frames:   make(chan image.Image, FrameBuffer),
+		done:     make(chan error, 1),
+	}
+	go r.encode()
+	return r, nil
+}
+
+// Fonction qui rajoute une capture: l'image ne doit plus être modifiée par l'appelant.
+// Une animation GIF est limitée à MaxGIFFrames images: au-delà, la capture est refusée avec une erreur
+func (r *Recorder) Add(img image.Image) error {
+	if r.format == GIF && r.count >= MaxGIFFrames {
+		return fmt.Errorf("limite de %d images atteinte pour l'animation %s", MaxGIFFrames, r.Path)
+	}
+	r.count++
+	r.frames <- img
+	return nil
+}
+
+// Fonction qui renvoie le nombre d'images capturées
+func (r *Recorder) Count() int {
+	return r.count
+}
+
+// Fonction qui termine l'enregistrement et renvoie la première erreur d'encodage
+func (r *Recorder) Close() error {
+	close(r.frames)
+	return <-r.done
+}
+
+// Fonction qui encode les captures au fur et à mesure de leur arrivée
+func (r *Recorder) encode() {
+	var err error
+	anim := &gif.GIF{}
+	index := 0
+	for img := range r.frames {
+		// Après une erreur, on vide le canal sans encoder pour ne pas bloquer l'appelant
+		if err != nil {
+			continue
+		}
+		index++
+		img = Resize(img, r.maxWidth)
+		switch r.format {
+		case PNG:
+			err = SavePNG(filepath.Join(r.Path, fmt.Sprintf("frame_%05d.png", index)), img)
+		case GIF:
+			anim.Image = append(anim.Image, Paletted(img))
+			anim.Delay = append(anim.Delay, r.delay)
+		}
+	}
+
+	if err == nil && r.format == GIF && len(anim.Image) > 0 {
+		err = saveGIF(r.Path, anim)
+	}
+	r.done <- err
+}
+
+// Fonction qui réduit une image à la largeur donnée en conservant ses proportions (aucune réduction si maxWidth <= 0)
+func Resize(img image.Image, maxWidth int) image.Image {
+	bounds := img.Bounds()
+	if maxWidth <= 0 || bounds.Dx() <= maxWidth {
+		return img
+	}
+	height := bounds.Dy() * maxWidth / bounds.Dx()
+	dst := image.NewRGBA(image.Rect(0, 0, maxWidth, height))
+	xdraw.ApproxBiLinear.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
+
+// Fonction qui convertit une image en image à palette pour l'animation GIF (palette Plan 9 avec tramage)
+func Paletted(img image.Image) *image.Paletted {
+	bounds := img.Bounds()
+	paletted := image.NewPaletted(image.Rect(0, 0, bounds.Dx(), bounds.Dy()), palette.Plan9)
+	draw.FloydSteinberg.Draw(paletted, paletted.Bounds(), img, bounds.Min)
+	return paletted
+}
+
+// Fonction qui enregistre une image au format PNG
+func SavePNG(path string, img image.Image) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := png.Encode(file, img); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// Fonction qui enregistre une animation au format GIF
+func saveGIF(path string, anim *gif.GIF) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := gif.EncodeAll(file, anim); err != nil {
+		return err
+	}
+	return file.Close()
+}
@@ -4,28 +4,28 @@ import (
 	"image"
 
 	tile "github.com/Tmegaa/The-Gophecy/pkg/Tile"
-
-	"github.com/hajimehoshi/ebiten/v2"
 )
 
-// Définition du type Carte
+// Définition du type Carte: la géométrie de la carte, sans ses images (elles sont chargées par l'affichage)
 type Carte struct {
 	TilemapJSON tile.TilemapJSON
-	Tilesets    []tile.Tileset
-	TilemapImg  *ebiten.Image
 	Coliders    []image.Rectangle
 	Ordinateurs []image.Rectangle
 	Statues     []image.Rectangle
+	Spawns      []image.Rectangle // cases où les agents peuvent apparaître
+	Zones       []Zone            // zones nommées (salles, chapelle...) et leurs règles
+	Bounds      image.Rectangle   // étendue de la carte dans le monde, dont les agents ne peuvent pas sortir
 }
 
 // Fonction de création d'une nouvelle carte
-func NewCarte(tilemapJSON tile.TilemapJSON, tilesets []tile.Tileset, tilemapImg *ebiten.Image, coliders []image.Rectangle, ordinateurs []image.Rectangle, statues []image.Rectangle) *Carte {
+func NewCarte(tilemapJSON tile.TilemapJSON, coliders []image.Rectangle, ordinateurs []image.Rectangle, statues []image.Rectangle, spawns []image.Rectangle, zones []Zone, bounds image.Rectangle) *Carte {
 	return &Carte{
 		TilemapJSON: tilemapJSON,
-		Tilesets:    tilesets,
-		TilemapImg:  tilemapImg,
 		Coliders:    coliders,
 		Ordinateurs: ordinateurs,
 		Statues:     statues,
+		Spawns:      spawns,
+		Zones:       zones,
+		Bounds:      bounds,
 	}
 }
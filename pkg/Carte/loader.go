@@ -0,0 +1,220 @@
+package carte
+
+import (
+	"fmt"
+	"image"
+	"path/filepath"
+	"slices"
+
+	"github.com/Tmegaa/The-Gophecy/assets"
+	mapgen "github.com/Tmegaa/The-Gophecy/pkg/Mapgen"
+	tile "github.com/Tmegaa/The-Gophecy/pkg/Tile"
+	ut "github.com/Tmegaa/The-Gophecy/pkg/Utilitaries"
+)
+
+// Constantes de chargement des cartes
+const (
+	TileSize = 24             // taille d'une case de la grille (en pixels)
+	MapsPath = "assets/maps/" // dossier des cartes fournies
+)
+
+// Fonction qui renvoie le chemin d'une carte: un nom seul désigne une carte du dossier des cartes (ou intégrée au programme),
+// tout autre chemin est utilisé tel quel
+func MapPath(mapFile string) string {
+	if mapgen.IsSpec(mapFile) {
+		return mapFile
+	}
+	if filepath.Base(mapFile) == mapFile {
+		return MapsPath + mapFile
+	}
+	return mapFile
+}
+
+// Fonction qui charge la carte désignée par path (voir ReadTilemap) et en extrait la géométrie
+func LoadCarte(path string) (*Carte, error) {
+	tilemapJSON, err := ReadTilemap(path)
+	if err != nil {
+		return nil, fmt.Errorf("échec du chargement de la carte %s: %v", path, err)
+	}
+	properties := tilemapJSON.TileProperties()
+
+	// Les couches, les tiles et les objets sont reconnus par leur rôle, le nombre d'objets vient donc de la carte
+	coliders := GenerateRectangles(tilemapJSON, properties, tile.ColliderRole)
+	computers := GenerateRectangles(tilemapJSON, properties, tile.ComputerRole)
+	statues := GenerateRectangles(tilemapJSON, properties, tile.StatueRole)
+	spawns := GenerateSpawns(tilemapJSON, properties, coliders)
+	zones := GenerateZones(tilemapJSON)
+
+	return NewCarte(*tilemapJSON, coliders, computers, statues, spawns, zones, MapBounds(tilemapJSON)), nil
+}
+
+// Fonction qui lit la carte désignée par path, ou la génère si path est une description "generate:..."
+func ReadTilemap(path string) (*tile.TilemapJSON, error) {
+	if !mapgen.IsSpec(path) {
+		return tile.NewTilemapJSON(path)
+	}
+
+	params, err := mapgen.ParseParams(path)
+	if err != nil {
+		return nil, err
+	}
+	generated, err := mapgen.Generate(params)
+	if err != nil {
+		return nil, err
+	}
+	contents, err := generated.JSON("tilesets")
+	if err != nil {
+		return nil, err
+	}
+
+	// La carte générée utilise les jeux de tiles fournis, comme si elle était enregistrée dans le dossier des cartes
+	fsys, dir, err := assets.Resolve(MapsPath)
+	if err != nil {
+		return nil, err
+	}
+	return tile.ParseTilemap(fsys, dir+"/generated.json", contents)
+}
+
+// Fonction qui renvoie l'étendue de la carte dans le monde: les coordonnées du monde sont celles de TILED
+func MapBounds(tilemapJSON *tile.TilemapJSON) image.Rectangle {
+	return image.Rect(0, 0, tilemapJSON.Width*TileSize, tilemapJSON.Height*TileSize)
+}
+
+// Fonction qui renvoie les positions des cases d'apparition, où peuvent être placés les agents
+func (c *Carte) SpawnPositions() []ut.Position {
+	positions := make([]ut.Position, 0, len(c.Spawns))
+	for _, spawn := range c.Spawns {
+		positions = append(positions, ut.Position{X: float64(spawn.Min.X), Y: float64(spawn.Min.Y)})
+	}
+	return positions
+}
+
+// Fonction qui renvoie les rectangles occupés par les tiles et les objets ayant le rôle donné
+func GenerateRectangles(tilemapJSON *tile.TilemapJSON, properties map[int]tile.Properties, role string) []image.Rectangle {
+	rectangles := TileRectangles(tilemapJSON, properties, role)
+	return append(rectangles, ObjectRectangles(tilemapJSON, properties, role, false)...)
+}
+
+// Fonction qui renvoie les rectangles occupés par les tiles ayant le rôle donné, d'après leur couche ou leurs propriétés
+func TileRectangles(tilemapJSON *tile.TilemapJSON, properties map[int]tile.Properties, role string) []image.Rectangle {
+	var rectangles []image.Rectangle
+	for _, layer := range tilemapJSON.Layers {
+		for i, tileID := range layer.Data {
+			if tileID == 0 || !tile.HasRole(layer, properties[tile.TileID(tileID)], role) {
+				continue
+			}
+			size, ok := tilemapJSON.TileSize(tileID)
+			if !ok {
+				continue
+			}
+
+			rectangles = append(rectangles, TileBounds(i, layer.Width, size))
+		}
+	}
+	return rectangles
+}
+
+// Fonction qui renvoie le rectangle occupé dans le monde par la tile d'indice i d'une couche de tiles de largeur layerWidth.
+// Comme dans TILED, une tile plus haute que la grille est ancrée par son coin inférieur gauche au bas de sa case
+func TileBounds(i, layerWidth int, size image.Point) image.Rectangle {
+	topLeft := image.Pt((i%layerWidth)*TileSize, (i/layerWidth+1)*TileSize-size.Y)
+	return image.Rectangle{Min: topLeft, Max: topLeft.Add(size)}
+}
+
+// Fonction qui renvoie les rectangles des objets ayant le rôle donné, dans les coordonnées du monde.
+// Un point occupe une case; avec split, les zones sont découpées en cases dont le centre est dans l'objet
+func ObjectRectangles(tilemapJSON *tile.TilemapJSON, properties map[int]tile.Properties, role string, split bool) []image.Rectangle {
+	var rectangles []image.Rectangle
+	for _, layer := range tilemapJSON.Layers {
+		for _, object := range layer.Objects {
+			if !tile.HasObjectRole(layer, object, properties[tile.TileID(object.GID)], role) {
+				continue
+			}
+
+			bounds := object.Bounds()
+			switch {
+			case object.Point:
+				rectangles = append(rectangles, image.Rect(0, 0, TileSize, TileSize).Add(bounds.Min))
+			case split:
+				rectangles = append(rectangles, objectCells(object)...)
+			default:
+				rectangles = append(rectangles, bounds)
+			}
+		}
+	}
+	return rectangles
+}
+
+// Fonction qui renvoie les cases dont le centre est à l'intérieur de l'objet, dans les coordonnées de TILED
+func objectCells(object tile.ObjectJSON) []image.Rectangle {
+	var cells []image.Rectangle
+	bounds := object.Bounds()
+	for y := bounds.Min.Y - bounds.Min.Y%TileSize; y < bounds.Max.Y; y += TileSize {
+		for x := bounds.Min.X - bounds.Min.X%TileSize; x < bounds.Max.X; x += TileSize {
+			if object.Contains(float64(x)+TileSize/2, float64(y)+TileSize/2) {
+				cells = append(cells, image.Rect(x, y, x+TileSize, y+TileSize))
+			}
+		}
+	}
+	return cells
+}
+
+// Fonction de génération des zones nommées: les objets des couches de zones, ou dont la classe est un type de zone connu.
+// Les propriétés "influence", "prayer" et "discussion" de l'objet ou de sa couche remplacent les règles par défaut du type
+func GenerateZones(tilemapJSON *tile.TilemapJSON) []Zone {
+	var zones []Zone
+	for _, layer := range tilemapJSON.Layers {
+		for _, object := range layer.Objects {
+			kind := ZoneKind(object.Kind())
+			if !tile.IsZone(layer, object) && !slices.Contains(ZoneKinds, kind) {
+				continue
+			}
+
+			rules := DefaultRules(kind)
+			properties := append(append(tile.Properties{}, object.Properties...), layer.Properties...)
+			if influence, ok := properties.Float("influence"); ok {
+				rules.Influence = influence
+			}
+			if prayer, ok := properties.Float("prayer"); ok {
+				rules.Prayer = prayer
+			}
+			if discussion, ok := properties.Bool("discussion"); ok {
+				rules.Discussion = discussion
+			}
+
+			cells := objectCells(object)
+			name := object.Name
+			if name == "" {
+				name = kind
+			}
+			zones = append(zones, NewZone(name, kind, rules, cells))
+		}
+	}
+	return zones
+}
+
+// Fonction de génération des cases d'apparition des agents: les tiles et zones marquées "spawn" si la carte en contient,
+// sinon les tiles marquées "walkable" qui ne sont pas couvertes par un obstacle
+func GenerateSpawns(tilemapJSON *tile.TilemapJSON, properties map[int]tile.Properties, coliders []image.Rectangle) []image.Rectangle {
+	spawns := TileRectangles(tilemapJSON, properties, tile.SpawnRole)
+	spawns = append(spawns, ObjectRectangles(tilemapJSON, properties, tile.SpawnRole, true)...)
+	if len(spawns) > 0 {
+		return spawns
+	}
+
+	walkable := TileRectangles(tilemapJSON, properties, tile.WalkableRole)
+	walkable = append(walkable, ObjectRectangles(tilemapJSON, properties, tile.WalkableRole, true)...)
+	for _, rect := range walkable {
+		free := true
+		for _, colider := range coliders {
+			if rect.Overlaps(colider) {
+				free = false
+				break
+			}
+		}
+		if free {
+			spawns = append(spawns, rect)
+		}
+	}
+	return spawns
+}
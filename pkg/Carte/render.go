@@ -0,0 +1,83 @@
+package carte
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+
+	tile "github.com/Tmegaa/The-Gophecy/pkg/Tile"
+)
+
+// Couleur du fond de la carte, sous les tiles
+var Background = color.RGBA{57, 61, 125, 255}
+
+// Fonction qui dessine les tiles de la carte avec image/draw, sans Ebiten: l'image couvre l'étendue de la carte
+// dans le monde (MapBounds), pour les captures sans fenêtre et l'image annotée de la vérification des cartes
+func RenderTilemap(tilemapJSON *tile.TilemapJSON) (*image.RGBA, error) {
+	tilesets, err := tilemapJSON.GenImageTilesets()
+	if err != nil {
+		return nil, fmt.Errorf("échec du chargement des jeux de tiles: %v", err)
+	}
+
+	// Même disposition que l'affichage
+	background := image.NewRGBA(MapBounds(tilemapJSON))
+	draw.Draw(background, background.Bounds(), image.NewUniform(Background), image.Point{}, draw.Src)
+	tileImage := func(gid int) image.Image {
+		if index := tilemapJSON.TilesetIndex(gid); index >= 0 {
+			if img := tilesets[index].Image(gid); img != nil {
+				return flipImage(img, gid)
+			}
+		}
+		return nil
+	}
+	for _, layer := range tilemapJSON.Layers {
+		for i, tileID := range layer.Data {
+			img := tileImage(tileID)
+			if tileID == 0 || img == nil {
+				continue
+			}
+			dst := TileBounds(i, layer.Width, img.Bounds().Size())
+			draw.Draw(background, dst, img, img.Bounds().Min, draw.Over)
+		}
+
+		// Tiles placées librement dans les couches d'objets (sans mise à l'échelle)
+		for _, object := range layer.Objects {
+			img := tileImage(object.GID)
+			if object.GID == 0 || img == nil {
+				continue
+			}
+			x, y := int(object.X), int(object.Y)-img.Bounds().Dy()
+			dst := image.Rect(x, y, x+img.Bounds().Dx(), y+img.Bounds().Dy())
+			draw.Draw(background, dst, img, img.Bounds().Min, draw.Over)
+		}
+	}
+	return background, nil
+}
+
+// Fonction qui renvoie l'image d'une tile retournée selon les bits de poids fort de son identifiant global, comme à l'affichage
+func flipImage(img image.Image, gid int) image.Image {
+	horizontal, vertical, diagonal := tile.Flips(gid)
+	if !horizontal && !vertical && !diagonal {
+		return img
+	}
+	bounds := img.Bounds()
+	size := tile.FlippedSize(gid, bounds.Size())
+	flipped := image.NewRGBA(image.Rectangle{Max: size})
+	for y := 0; y < size.Y; y++ {
+		for x := 0; x < size.X; x++ {
+			sx, sy := x, y
+			if horizontal {
+				sx = size.X - 1 - x
+			}
+			if vertical {
+				sy = size.Y - 1 - y
+			}
+			if diagonal {
+				sx, sy = sy, sx
+			}
+			flipped.Set(x, y, img.At(bounds.Min.X+sx, bounds.Min.Y+sy))
+		}
+	}
+	return flipped
+}
@@ -0,0 +1,99 @@
+package carte
+
+import (
+	"image"
+	"strings"
+)
+
+// Types de zones reconnus: ils viennent de la classe des objets des couches de zones de TILED
+const (
+	LabZone         = "lab"          // salle informatique
+	ChapelZone      = "chapel"       // chapelle
+	CafeteriaZone   = "cafeteria"    // cafétéria
+	LibraryZone     = "library"      // bibliothèque
+	LectureHallZone = "lecture_hall" // amphithéâtre
+)
+
+// Liste des types de zones reconnus
+var ZoneKinds = []string{LabZone, ChapelZone, CafeteriaZone, LibraryZone, LectureHallZone}
+
+// Règles qui modifient le comportement des agents dans une zone
+type ZoneRules struct {
+	Influence  float64 // facteur appliqué aux variations d'opinion des discussions (une salle bruyante l'atténue)
+	Prayer     float64 // facteur appliqué au gain d'opinion d'une prière
+	Discussion bool    // les agents peuvent y engager une discussion
+}
+
+// Règles hors de toute zone: elles ne modifient rien
+var NoRules = ZoneRules{Influence: 1, Prayer: 1, Discussion: true}
+
+// Fonction qui renvoie les règles par défaut d'un type de zone; les propriétés de la carte peuvent les remplacer
+func DefaultRules(kind string) ZoneRules {
+	rules := NoRules
+	switch kind {
+	case ChapelZone:
+		rules.Prayer = 2
+	case CafeteriaZone:
+		rules.Influence = 0.5
+	case LibraryZone:
+		rules.Discussion = false
+	}
+	return rules
+}
+
+// Fonction qui normalise le type d'une zone: "Lecture hall" devient "lecture_hall"
+func ZoneKind(class string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(class)), " ", "_")
+}
+
+// Zone nommée de la carte, formée des cases dont le centre est à l'intérieur de la forme dessinée dans TILED
+type Zone struct {
+	Name   string            // nom de la zone (celui de l'objet, ou son type à défaut)
+	Kind   string            // type de la zone (lab, chapel...)
+	Rules  ZoneRules         // règles de comportement dans la zone
+	Bounds image.Rectangle   // rectangle englobant les cases de la zone
+	Cells  []image.Rectangle // cases de la zone
+}
+
+// Fonction de création d'une zone à partir de ses cases
+func NewZone(name, kind string, rules ZoneRules, cells []image.Rectangle) Zone {
+	zone := Zone{Name: name, Kind: kind, Rules: rules, Cells: cells}
+	for _, cell := range cells {
+		zone.Bounds = zone.Bounds.Union(cell)
+	}
+	return zone
+}
+
+// Fonction qui indique si le point (x, y) est dans la zone
+func (z *Zone) Contains(x, y float64) bool {
+	point := image.Pt(int(x), int(y))
+	if !point.In(z.Bounds) {
+		return false
+	}
+	for _, cell := range z.Cells {
+		if point.In(cell) {
+			return true
+		}
+	}
+	return false
+}
+
+// Fonction qui renvoie la zone contenant le point (x, y), ou nil. Si des zones se chevauchent, la plus petite l'emporte
+func (c *Carte) ZoneAt(x, y float64) *Zone {
+	var found *Zone
+	for i := range c.Zones {
+		zone := &c.Zones[i]
+		if zone.Contains(x, y) && (found == nil || len(zone.Cells) < len(found.Cells)) {
+			found = zone
+		}
+	}
+	return found
+}
+
+// Fonction qui renvoie les règles en vigueur au point (x, y)
+func (c *Carte) RulesAt(x, y float64) ZoneRules {
+	if zone := c.ZoneAt(x, y); zone != nil {
+		return zone.Rules
+	}
+	return NoRules
+}
@@ -0,0 +1,48 @@
+package events
+
+import "fmt"
+
+// Différents types d'évènements émis pendant la simulation
+type Kind string
+
+const (
+	DiscussionStart Kind = "discussion_start" // début d'une discussion entre deux agents
+	DiscussionEnd   Kind = "discussion_end"   // fin d'une discussion entre deux agents
+	OpinionChange   Kind = "opinion_delta"    // modification de l'opinion d'un agent
+	TypeChange      Kind = "type_change"      // changement de type (sceptique, neutre, croyant)
+	SubTypeChange   Kind = "subtype_change"   // changement de sous-type (pirate, convertisseur)
+	ProgramChange   Kind = "program_change"   // changement du langage installé sur un ordinateur
+	Prayer          Kind = "prayer"           // fin d'une prière auprès d'une statue
+	Spawn           Kind = "spawn"            // arrivée d'un agent dans l'environnement
+	Death           Kind = "death"            // départ d'un agent de l'environnement
+)
+
+// Un évènement de la simulation: chaque action notable d'un agent en produit un
+type Event struct {
+	Tick   int64   `json:"tick"`             // tick de la simulation au moment de l'évènement
+	Kind   Kind    `json:"kind"`             // type d'évènement
+	Agent  string  `json:"agent"`            // agent à l'origine de l'évènement
+	Other  string  `json:"other,omitempty"`  // autre agent concerné (partenaire de discussion)
+	Object string  `json:"object,omitempty"` // objet concerné (ordinateur ou statue)
+	Before float64 `json:"before"`           // opinion de l'agent avant l'évènement
+	After  float64 `json:"after"`            // opinion de l'agent après l'évènement
+	From   string  `json:"from,omitempty"`   // ancienne valeur (type, sous-type ou programme)
+	To     string  `json:"to,omitempty"`     // nouvelle valeur (type, sous-type ou programme)
+}
+
+// Fonction qui renvoie la variation d'opinion portée par l'évènement
+func (e Event) Delta() float64 {
+	return e.After - e.Before
+}
+
+// Fonction qui renvoie une description lisible de l'évènement
+func (e Event) String() string {
+	switch e.Kind {
+	case OpinionChange:
+		return fmt.Sprintf("[%d] %s %s: %.3f -> %.3f", e.Tick, e.Kind, e.Agent, e.Before, e.After)
+	case TypeChange, SubTypeChange, ProgramChange:
+		return fmt.Sprintf("[%d] %s %s: %s -> %s", e.Tick, e.Kind, e.Agent, e.From, e.To)
+	default:
+		return fmt.Sprintf("[%d] %s %s", e.Tick, e.Kind, e.Agent)
+	}
+}
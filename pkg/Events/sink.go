@@ -0,0 +1,152 @@
+package events
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// Une destination d'écriture pour les évènements de la simulation
+type Sink interface {
+	Write(Event) error
+	Close() error
+}
+
+// Sink qui écrit un objet JSON par ligne (format JSON Lines)
+type JSONLSink struct {
+	w   io.WriteCloser
+	enc *json.Encoder
+}
+
+// Création d'un nouveau sink JSON Lines
+func NewJSONLSink(w io.WriteCloser) *JSONLSink {
+	return &JSONLSink{w: w, enc: json.NewEncoder(w)}
+}
+
+// Fonction qui écrit un évènement sur une ligne
+func (s *JSONLSink) Write(e Event) error {
+	return s.enc.Encode(e)
+}
+
+// Fonction qui ferme la destination d'écriture
+func (s *JSONLSink) Close() error {
+	return s.w.Close()
+}
+
+// En-tête des fichiers CSV d'évènements
+var csvHeader = []string{"tick", "kind", "agent", "other", "object", "before", "after", "from", "to"}
+
+// Sink qui écrit les évènements au format CSV
+type CSVSink struct {
+	w         io.WriteCloser
+	csv       *csv.Writer
+	headerOut bool
+}
+
+// Création d'un nouveau sink CSV
+func NewCSVSink(w io.WriteCloser) *CSVSink {
+	return &CSVSink{w: w, csv: csv.NewWriter(w)}
+}
+
+// Fonction qui écrit un évènement sur une ligne du fichier CSV
+func (s *CSVSink) Write(e Event) error {
+	// L'en-tête est écrit avant le premier évènement
+	if !s.headerOut {
+		if err := s.csv.Write(csvHeader); err != nil {
+			return err
+		}
+		s.headerOut = true
+	}
+
+	record := []string{
+		strconv.FormatInt(e.Tick, 10),
+		string(e.Kind),
+		e.Agent,
+		e.Other,
+		e.Object,
+		strconv.FormatFloat(e.Before, 'f', -1, 64),
+		strconv.FormatFloat(e.After, 'f', -1, 64),
+		e.From,
+		e.To,
+	}
+	return s.csv.Write(record)
+}
+
+// Fonction qui vide le tampon et ferme la destination d'écriture
+func (s *CSVSink) Close() error {
+	s.csv.Flush()
+	if err := s.csv.Error(); err != nil {
+		s.w.Close()
+		return err
+	}
+	return s.w.Close()
+}
+
+// Fonction qui crée un sink fichier dont le format dépend de l'extension (.csv ou JSON Lines sinon)
+func NewFileSink(path string) (Sink, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("échec de la création du journal d'évènements: %v", err)
+	}
+
+	if filepath.Ext(path) == ".csv" {
+		return NewCSVSink(file), nil
+	}
+	return NewJSONLSink(file), nil
+}
+
+// Bus qui diffuse les évènements vers plusieurs sinks: il peut être utilisé par plusieurs goroutines
+type Bus struct {
+	mutex sync.Mutex
+	sinks []Sink
+	err   error
+}
+
+// Création d'un nouveau bus d'évènements
+func NewBus(sinks ...Sink) *Bus {
+	return &Bus{sinks: sinks}
+}
+
+// Fonction qui rajoute un sink au bus
+func (b *Bus) Attach(s Sink) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.sinks = append(b.sinks, s)
+}
+
+// Fonction qui envoie un évènement à tous les sinks: la première erreur est conservée
+func (b *Bus) Emit(e Event) {
+	if b == nil {
+		return
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for _, s := range b.sinks {
+		if err := s.Write(e); err != nil && b.err == nil {
+			b.err = err
+		}
+	}
+}
+
+// Fonction qui ferme tous les sinks et renvoie la première erreur rencontrée
+func (b *Bus) Close() error {
+	if b == nil {
+		return nil
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for _, s := range b.sinks {
+		if err := s.Close(); err != nil && b.err == nil {
+			b.err = err
+		}
+	}
+	b.sinks = nil
+	return b.err
+}
@@ -0,0 +1,143 @@
+package influence
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	ag "github.com/Tmegaa/The-Gophecy/pkg/Agent"
+)
+
+// Couleurs des types d'agents dans le graphe des conversions
+var dotColors = map[ag.TypeAgent]string{
+	ag.Sceptic:  "red",
+	ag.Neutral:  "gray",
+	ag.Believer: "black",
+}
+
+// Fonction qui écrit le registre dans le dossier donné: "influence.csv" (score de chaque source),
+// "conversions.csv" (changements de type attribués) et "conversions.dot" (graphe des conversions au format Graphviz)
+func (l *Ledger) Save(dir string) error {
+	if err := l.writeScores(filepath.Join(dir, "influence.csv")); err != nil {
+		return err
+	}
+	if err := l.writeConversions(filepath.Join(dir, "conversions.csv")); err != nil {
+		return err
+	}
+
+	file, err := os.Create(filepath.Join(dir, "conversions.dot"))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err := l.WriteDOT(file); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// Fonction qui écrit le score de chaque source au format CSV
+func (l *Ledger) writeScores(path string) error {
+	header := []string{"source", "kind", "total", "net", "changes", "conversions", "toward_go", "indirect", "indirect_conversions"}
+	rows := make([][]string, 0)
+	for _, s := range l.Scores() {
+		rows = append(rows, []string{
+			s.ID,
+			string(s.Kind),
+			strconv.FormatFloat(s.Total, 'f', 6, 64),
+			strconv.FormatFloat(s.Net, 'f', 6, 64),
+			strconv.Itoa(s.Changes),
+			strconv.Itoa(s.Conversions),
+			strconv.Itoa(s.TowardGo),
+			strconv.FormatFloat(s.Indirect, 'f', 6, 64),
+			strconv.Itoa(s.IndirectConversions),
+		})
+	}
+	return writeCSV(path, header, rows)
+}
+
+// Fonction qui écrit les changements de type attribués au format CSV
+func (l *Ledger) writeConversions(path string) error {
+	header := []string{"tick", "agent", "from", "to", "source", "kind", "source_subtype", "installer"}
+	rows := make([][]string, 0)
+	for _, c := range l.Conversions() {
+		rows = append(rows, []string{
+			strconv.FormatInt(c.Tick, 10),
+			c.Agent,
+			string(c.From),
+			string(c.To),
+			c.Source.ID,
+			string(c.Source.Kind),
+			string(c.SourceSubType),
+			c.Installer,
+		})
+	}
+	return writeCSV(path, header, rows)
+}
+
+// Fonction qui écrit le graphe des conversions au format DOT: une flèche va de la source vers l'agent converti,
+// colorée d'après le nouveau type, de sorte que les chaînes de conversion (A convertit B qui convertit C) apparaissent
+func (l *Ledger) WriteDOT(w io.Writer) error {
+	conversions := l.Conversions()
+
+	if _, err := fmt.Fprintln(w, "digraph conversions {\n\trankdir=LR;\n\tnode [shape=ellipse];"); err != nil {
+		return err
+	}
+	objects := make(map[string]SourceKind)
+	for _, c := range conversions {
+		if c.Source.Kind == StatueSource || c.Source.Kind == ComputerSource {
+			objects[c.Source.ID] = c.Source.Kind
+		}
+	}
+	for id, kind := range objects {
+		shape := "box"
+		if kind == StatueSource {
+			shape = "house"
+		}
+		if _, err := fmt.Fprintf(w, "\t%q [shape=%s];\n", id, shape); err != nil {
+			return err
+		}
+	}
+
+	installers := make(map[[2]string]bool)
+	for _, c := range conversions {
+		source := c.Source.ID
+		if c.Source.Kind == EditorSource {
+			source = "éditeur"
+		}
+		label := fmt.Sprintf("%d: %s → %s", c.Tick, c.From, c.To)
+		if _, err := fmt.Fprintf(w, "\t%q -> %q [label=%q, color=%s];\n", source, c.Agent, label, dotColors[c.To]); err != nil {
+			return err
+		}
+		// Pour un ordinateur, on relie aussi l'agent qui a installé son programme
+		if edge := [2]string{c.Installer, source}; c.Installer != "" && !installers[edge] {
+			installers[edge] = true
+			if _, err := fmt.Fprintf(w, "\t%q -> %q [style=dashed];\n", c.Installer, source); err != nil {
+				return err
+			}
+		}
+	}
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
+
+// Fonction qui écrit un fichier CSV avec un en-tête
+func writeCSV(path string, header []string, rows [][]string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	if err := w.WriteAll(rows); err != nil {
+		return err
+	}
+	return file.Close()
+}
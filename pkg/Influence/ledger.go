@@ -0,0 +1,408 @@
+package influence
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"sync"
+
+	ag "github.com/Tmegaa/The-Gophecy/pkg/Agent"
+	events "github.com/Tmegaa/The-Gophecy/pkg/Events"
+)
+
+// Nature d'une source d'influence
+type SourceKind string
+
+const (
+	AgentSource    SourceKind = "agent"    // partenaire de discussion
+	StatueSource   SourceKind = "statue"   // statue devant laquelle l'agent a prié
+	ComputerSource SourceKind = "computer" // ordinateur utilisé par l'agent
+	EditorSource   SourceKind = "editor"   // modification manuelle depuis l'éditeur
+)
+
+// Source d'une variation d'opinion
+type Source struct {
+	ID   string     // identifiant de l'agent ou de l'objet (vide pour l'éditeur)
+	Kind SourceKind // nature de la source
+}
+
+// Score d'influence d'une source: variations d'opinion et changements de type qu'elle a provoqués
+type Score struct {
+	Source
+	Total               float64 // somme des valeurs absolues des variations provoquées
+	Net                 float64 // somme des variations provoquées (positive lorsque les agents se rapprochent de Go)
+	Changes             int     // nombre de variations d'opinion provoquées
+	Conversions         int     // nombre de changements de type provoqués
+	TowardGo            int     // changements de type vers un type plus favorable à Go
+	Indirect            float64 // influence exercée à travers les ordinateurs dont l'agent a changé le programme
+	IndirectConversions int     // changements de type provoqués à travers ces ordinateurs
+}
+
+// Fonction qui renvoie le nombre total de changements de type provoqués, directement ou non
+func (s Score) AllConversions() int {
+	return s.Conversions + s.IndirectConversions
+}
+
+// Influence reçue par un agent de la part d'une source
+type Contribution struct {
+	Source
+	Delta   float64 // somme des variations d'opinion dues à cette source
+	Changes int     // nombre de variations d'opinion dues à cette source
+}
+
+// Changement de type d'un agent, attribué à la source de la dernière variation de son opinion
+type Conversion struct {
+	Tick          int64
+	Agent         string          // agent converti
+	From          ag.TypeAgent    // ancien type
+	To            ag.TypeAgent    // nouveau type
+	Source        Source          // source de la variation d'opinion qui a provoqué le changement
+	SourceSubType ag.SubTypeAgent // sous-type de l'agent source au moment de la variation
+	Installer     string          // agent ayant installé le programme de l'ordinateur source, s'il est connu
+}
+
+// Fonction qui indique si la conversion rapproche l'agent de Go
+func (c Conversion) TowardGo() bool {
+	return typeRank(c.To) > typeRank(c.From)
+}
+
+// Bilan d'une tactique: influence cumulée des sources d'une même catégorie
+type Tactic struct {
+	Name        string  // sous-type des agents, ou nature de l'objet
+	Sources     int     // nombre de sources de cette catégorie ayant modifié au moins une opinion
+	Total       float64 // somme des valeurs absolues des variations provoquées
+	Net         float64 // somme des variations provoquées
+	Conversions int     // nombre de changements de type provoqués
+	TowardGo    int     // changements de type vers un type plus favorable à Go
+}
+
+// Dernière variation d'opinion d'un agent, conservée pour lui attribuer un éventuel changement de type
+type lastDelta struct {
+	source           Source
+	subType          ag.SubTypeAgent
+	installer        string
+	installerSubType ag.SubTypeAgent
+}
+
+// Programme installé sur un ordinateur et agent qui l'a installé
+type installation struct {
+	agent   string
+	subType ag.SubTypeAgent
+}
+
+// Registre des influences: il reçoit les évènements de la simulation et attribue chaque variation d'opinion
+// à sa source (partenaire de discussion, statue, ordinateur ou éditeur)
+type Ledger struct {
+	mutex       sync.Mutex
+	scores      map[Source]*Score
+	bySubType   map[ag.SubTypeAgent]map[string]*Score // influence des agents selon leur sous-type au moment de l'influence
+	received    map[string]map[Source]*Contribution
+	conversions []Conversion
+	subTypes    map[string]ag.SubTypeAgent
+	statues     map[string]bool
+	installed   map[string]installation
+	last        map[string]lastDelta
+}
+
+// Création d'un nouveau registre des influences
+func NewLedger() *Ledger {
+	return &Ledger{
+		scores:      make(map[Source]*Score),
+		bySubType:   make(map[ag.SubTypeAgent]map[string]*Score),
+		received:    make(map[string]map[Source]*Contribution),
+		conversions: make([]Conversion, 0),
+		subTypes:    make(map[string]ag.SubTypeAgent),
+		statues:     make(map[string]bool),
+		installed:   make(map[string]installation),
+		last:        make(map[string]lastDelta),
+	}
+}
+
+// Fonction qui fait connaître le sous-type initial d'un agent (les changements suivants arrivent par les évènements)
+func (l *Ledger) Register(agent ag.IdAgent, subType ag.SubTypeAgent) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.subTypes[string(agent)] = subType
+}
+
+// Fonction qui reçoit les évènements de la simulation
+func (l *Ledger) Write(e events.Event) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	switch e.Kind {
+	case events.Prayer:
+		// La prière précède toujours la variation d'opinion qu'elle provoque
+		if e.Object != "" {
+			l.statues[e.Object] = true
+		}
+	case events.ProgramChange:
+		l.installed[e.Object] = installation{agent: e.Agent, subType: l.subTypes[e.Agent]}
+	case events.SubTypeChange:
+		l.subTypes[e.Agent] = ag.SubTypeAgent(e.To)
+	case events.OpinionChange:
+		l.attribute(e)
+	case events.TypeChange:
+		l.convert(e)
+	}
+	return nil
+}
+
+// Fonction qui attribue une variation d'opinion à sa source
+func (l *Ledger) attribute(e events.Event) {
+	source := Source{Kind: EditorSource}
+	switch {
+	case e.Other != "":
+		source = Source{ID: e.Other, Kind: AgentSource}
+	case e.Object != "" && l.statues[e.Object]:
+		source = Source{ID: e.Object, Kind: StatueSource}
+	case e.Object != "":
+		source = Source{ID: e.Object, Kind: ComputerSource}
+	}
+
+	delta := e.Delta()
+	last := lastDelta{source: source}
+	score := l.score(source)
+	score.Total += math.Abs(delta)
+	score.Net += delta
+	score.Changes++
+
+	if source.Kind == AgentSource {
+		last.subType = l.subTypes[source.ID]
+		byType := l.subTypeScore(last.subType, source)
+		byType.Total += math.Abs(delta)
+		byType.Net += delta
+		byType.Changes++
+	}
+
+	// L'influence d'un ordinateur est aussi créditée à l'agent qui a installé son programme
+	if installed, ok := l.installed[e.Object]; ok && source.Kind == ComputerSource && installed.agent != e.Agent {
+		last.installer, last.installerSubType = installed.agent, installed.subType
+		l.score(Source{ID: installed.agent, Kind: AgentSource}).Indirect += math.Abs(delta)
+		l.subTypeScore(installed.subType, Source{ID: installed.agent, Kind: AgentSource}).Indirect += math.Abs(delta)
+	}
+	l.last[e.Agent] = last
+
+	contributions, ok := l.received[e.Agent]
+	if !ok {
+		contributions = make(map[Source]*Contribution)
+		l.received[e.Agent] = contributions
+	}
+	contribution, ok := contributions[source]
+	if !ok {
+		contribution = &Contribution{Source: source}
+		contributions[source] = contribution
+	}
+	contribution.Delta += delta
+	contribution.Changes++
+}
+
+// Fonction qui attribue un changement de type à la source de la dernière variation d'opinion de l'agent
+func (l *Ledger) convert(e events.Event) {
+	last, ok := l.last[e.Agent]
+	if !ok {
+		return
+	}
+	conversion := Conversion{
+		Tick:          e.Tick,
+		Agent:         e.Agent,
+		From:          ag.TypeAgent(e.From),
+		To:            ag.TypeAgent(e.To),
+		Source:        last.source,
+		SourceSubType: last.subType,
+		Installer:     last.installer,
+	}
+	l.conversions = append(l.conversions, conversion)
+
+	towardGo := 0
+	if conversion.TowardGo() {
+		towardGo = 1
+	}
+	score := l.score(last.source)
+	score.Conversions++
+	score.TowardGo += towardGo
+	if last.source.Kind == AgentSource {
+		byType := l.subTypeScore(last.subType, last.source)
+		byType.Conversions++
+		byType.TowardGo += towardGo
+	}
+	if last.installer != "" {
+		installer := Source{ID: last.installer, Kind: AgentSource}
+		l.score(installer).IndirectConversions++
+		l.subTypeScore(last.installerSubType, installer).IndirectConversions++
+	}
+}
+
+// Fonction qui renvoie le score d'une source, créé au besoin
+func (l *Ledger) score(source Source) *Score {
+	score, ok := l.scores[source]
+	if !ok {
+		score = &Score{Source: source}
+		l.scores[source] = score
+	}
+	return score
+}
+
+// Fonction qui renvoie le score d'un agent pour un sous-type donné, créé au besoin
+func (l *Ledger) subTypeScore(subType ag.SubTypeAgent, source Source) *Score {
+	scores, ok := l.bySubType[subType]
+	if !ok {
+		scores = make(map[string]*Score)
+		l.bySubType[subType] = scores
+	}
+	score, ok := scores[source.ID]
+	if !ok {
+		score = &Score{Source: source}
+		scores[source.ID] = score
+	}
+	return score
+}
+
+// Fonction qui ne fait rien: le registre est conservé en mémoire
+func (l *Ledger) Close() error { return nil }
+
+// Fonction qui renvoie les scores de toutes les sources, de la plus influente à la moins influente
+func (l *Ledger) Scores() []Score {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	scores := make([]Score, 0, len(l.scores))
+	for _, score := range l.scores {
+		scores = append(scores, *score)
+	}
+	sortByInfluence(scores)
+	return scores
+}
+
+// Fonction qui renvoie les n agents dont les discussions ont le plus modifié l'opinion de leurs partenaires (tous si n <= 0)
+func (l *Ledger) Top(n int) []Score {
+	scores := slices.DeleteFunc(l.Scores(), func(s Score) bool {
+		return s.Kind != AgentSource || s.Changes == 0
+	})
+	return truncate(scores, n)
+}
+
+// Fonction qui classe les agents selon leur efficacité lorsqu'ils avaient le sous-type donné:
+// d'abord le nombre de changements de type provoqués (directement ou à travers les ordinateurs), puis l'influence cumulée
+func (l *Ledger) Ranking(subType ag.SubTypeAgent, n int) []Score {
+	l.mutex.Lock()
+	scores := make([]Score, 0, len(l.bySubType[subType]))
+	for _, score := range l.bySubType[subType] {
+		scores = append(scores, *score)
+	}
+	l.mutex.Unlock()
+
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].AllConversions() != scores[j].AllConversions() {
+			return scores[i].AllConversions() > scores[j].AllConversions()
+		}
+		if a, b := scores[i].Total+scores[i].Indirect, scores[j].Total+scores[j].Indirect; a != b {
+			return a > b
+		}
+		return scores[i].ID < scores[j].ID
+	})
+	return truncate(scores, n)
+}
+
+// Fonction qui regroupe l'influence directe par tactique: sous-type des agents, statues, ordinateurs et éditeur
+func (l *Ledger) Tactics() []Tactic {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	tactics := make([]Tactic, 0)
+	add := func(name string, scores []*Score) {
+		tactic := Tactic{Name: name}
+		for _, score := range scores {
+			if score.Changes == 0 {
+				continue
+			}
+			tactic.Sources++
+			tactic.Total += score.Total
+			tactic.Net += score.Net
+			tactic.Conversions += score.Conversions
+			tactic.TowardGo += score.TowardGo
+		}
+		tactics = append(tactics, tactic)
+	}
+
+	for _, subType := range []ag.SubTypeAgent{ag.Converter, ag.Pirate, ag.None} {
+		scores := make([]*Score, 0, len(l.bySubType[subType]))
+		for _, score := range l.bySubType[subType] {
+			scores = append(scores, score)
+		}
+		name := string(subType)
+		if subType == ag.None {
+			name = "Sans sous-type"
+		}
+		add(name, scores)
+	}
+	for _, kind := range []SourceKind{StatueSource, ComputerSource, EditorSource} {
+		scores := make([]*Score, 0)
+		for source, score := range l.scores {
+			if source.Kind == kind {
+				scores = append(scores, score)
+			}
+		}
+		add(string(kind), scores)
+	}
+	return tactics
+}
+
+// Fonction qui renvoie l'influence reçue par un agent, de la source la plus influente à la moins influente
+func (l *Ledger) Received(agent ag.IdAgent) []Contribution {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	contributions := make([]Contribution, 0, len(l.received[string(agent)]))
+	for _, contribution := range l.received[string(agent)] {
+		contributions = append(contributions, *contribution)
+	}
+	sort.Slice(contributions, func(i, j int) bool {
+		a, b := math.Abs(contributions[i].Delta), math.Abs(contributions[j].Delta)
+		if a != b {
+			return a > b
+		}
+		return contributions[i].ID < contributions[j].ID
+	})
+	return contributions
+}
+
+// Fonction qui renvoie une copie des changements de type attribués, du plus ancien au plus récent
+func (l *Ledger) Conversions() []Conversion {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return append([]Conversion(nil), l.conversions...)
+}
+
+// Fonction qui trie des scores par influence cumulée décroissante
+func sortByInfluence(scores []Score) {
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Total != scores[j].Total {
+			return scores[i].Total > scores[j].Total
+		}
+		if scores[i].Kind != scores[j].Kind {
+			return scores[i].Kind < scores[j].Kind
+		}
+		return scores[i].ID < scores[j].ID
+	})
+}
+
+// Fonction qui ne garde que les n premiers scores (tous si n <= 0)
+func truncate(scores []Score, n int) []Score {
+	if n > 0 && len(scores) > n {
+		return scores[:n]
+	}
+	return scores
+}
+
+// Fonction qui renvoie le rang d'un type sur l'axe sceptique → neutre → croyant
+func typeRank(t ag.TypeAgent) int {
+	switch t {
+	case ag.Sceptic:
+		return 0
+	case ag.Neutral:
+		return 1
+	default:
+		return 2
+	}
+}
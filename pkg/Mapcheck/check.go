@@ -0,0 +1,260 @@
+// Package mapcheck vérifie la cohérence d'une carte chargée: obstacles qui se chevauchent, cases d'apparition
+// bloquées, régions inaccessibles et objets qu'aucun agent ne peut approcher.
+package mapcheck
+
+import (
+	"fmt"
+	"image"
+	"slices"
+
+	ag "github.com/Tmegaa/The-Gophecy/pkg/Agent"
+)
+
+// Types de problèmes détectés
+const (
+	OverlapIssue     = "chevauchement" // deux obstacles de couches différentes (ou deux doublons) se chevauchent
+	SpawnIssue       = "apparition"    // une case d'apparition est couverte par un obstacle
+	UnreachableIssue = "inaccessible"  // une région praticable est séparée de la région principale
+	ApproachIssue    = "approche"      // un objet n'a aucune case de la région principale à côté de lui
+)
+
+// Élément de la carte et la couche dont il vient
+type Item struct {
+	Name   string // nom de la couche, de l'objet ou de la zone
+	Bounds image.Rectangle
+}
+
+// Carte à vérifier, dans les coordonnées du monde
+type Input struct {
+	TileSize  int               // taille d'une case (en pixels)
+	Bounds    image.Rectangle   // étendue de la carte
+	Colliders []Item            // obstacles, avec leur couche
+	Spawns    []image.Rectangle // cases d'apparition des agents
+	Walkable  []image.Rectangle // sol praticable; toute la carte si vide
+	Objects   []Item            // ordinateurs et statues
+	Zones     []Item            // zones nommées
+}
+
+// Problème détecté sur la carte
+type Issue struct {
+	Kind    string
+	Message string
+	Bounds  image.Rectangle // endroit du problème, pour l'image annotée
+	Warning bool            // simple avertissement: la carte reste utilisable (objets posés les uns sur les autres par exemple)
+}
+
+// Résultat de la vérification
+type Report struct {
+	Issues    []Issue
+	Free      int // nombre de cases praticables non couvertes par un obstacle
+	Reachable int // nombre de cases de la région principale, la plus grande où apparaissent des agents
+}
+
+// Fonction qui indique si la vérification n'a trouvé aucune erreur (les avertissements sont tolérés)
+func (r Report) OK() bool {
+	for _, issue := range r.Issues {
+		if !issue.Warning {
+			return false
+		}
+	}
+	return true
+}
+
+// Fonction qui renvoie l'emprise d'un agent placé en (x, y), coin supérieur gauche de son image
+func agentRect(p image.Point) image.Rectangle {
+	return image.Rect(p.X, p.Y, p.X+ag.AgentSize, p.Y+ag.AgentSize)
+}
+
+// Fonction qui indique si un rectangle chevauche un obstacle
+func (in *Input) blocked(rect image.Rectangle) bool {
+	for _, collider := range in.Colliders {
+		if collider.Bounds.Overlaps(rect) {
+			return true
+		}
+	}
+	return false
+}
+
+// Fonction qui renvoie les cases de la grille recouvertes par un rectangle, repérées par leur indice de colonne et de ligne
+func (in *Input) cellsOf(rect image.Rectangle) []image.Point {
+	var cells []image.Point
+	for y := floorDiv(rect.Min.Y, in.TileSize); y*in.TileSize < rect.Max.Y; y++ {
+		for x := floorDiv(rect.Min.X, in.TileSize); x*in.TileSize < rect.Max.X; x++ {
+			cells = append(cells, image.Pt(x, y))
+		}
+	}
+	return cells
+}
+
+// Fonction qui renvoie le rectangle d'une case de la grille
+func (in *Input) cellRect(cell image.Point) image.Rectangle {
+	return image.Rect(cell.X*in.TileSize, cell.Y*in.TileSize, (cell.X+1)*in.TileSize, (cell.Y+1)*in.TileSize)
+}
+
+// Fonction qui indique si un agent centré dans la case peut s'y tenir sans toucher d'obstacle
+func (in *Input) cellFree(cell image.Point) bool {
+	rect := in.cellRect(cell)
+	margin := (in.TileSize - ag.AgentSize) / 2
+	return !in.blocked(agentRect(rect.Min.Add(image.Pt(margin, margin))))
+}
+
+// Fonction qui vérifie la carte
+func Check(in Input) Report {
+	var report Report
+	report.Issues = append(report.Issues, in.overlaps()...)
+	report.Issues = append(report.Issues, in.blockedSpawns()...)
+
+	// Cases praticables qui ne sont pas couvertes par un obstacle
+	walkable := in.Walkable
+	if len(walkable) == 0 {
+		walkable = []image.Rectangle{in.Bounds}
+	}
+	free := make(map[image.Point]bool)
+	for _, rect := range walkable {
+		for _, cell := range in.cellsOf(rect.Intersect(in.Bounds)) {
+			if _, seen := free[cell]; !seen {
+				free[cell] = in.cellFree(cell)
+			}
+		}
+	}
+	for _, ok := range free {
+		if ok {
+			report.Free++
+		}
+	}
+
+	// Régions de cases praticables reliées entre elles, et celles où apparaissent des agents
+	region := make(map[image.Point]int)
+	var sizes []int
+	var bounds []image.Rectangle
+	for _, cell := range sortedCells(free) {
+		if !free[cell] || region[cell] != 0 {
+			continue
+		}
+		sizes = append(sizes, 0)
+		bounds = append(bounds, image.Rectangle{})
+		id := len(sizes)
+		stack := []image.Point{cell}
+		region[cell] = id
+		for len(stack) > 0 {
+			current := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			sizes[id-1]++
+			bounds[id-1] = bounds[id-1].Union(in.cellRect(current))
+			for _, next := range []image.Point{current.Add(image.Pt(1, 0)), current.Add(image.Pt(-1, 0)), current.Add(image.Pt(0, 1)), current.Add(image.Pt(0, -1))} {
+				if free[next] && region[next] == 0 {
+					region[next] = id
+					stack = append(stack, next)
+				}
+			}
+		}
+	}
+
+	// La région principale est la plus grande où apparaissent des agents: les autres sont inaccessibles depuis elle
+	spawned := make(map[int]bool)
+	for _, spawn := range in.Spawns {
+		for _, cell := range in.cellsOf(agentRect(spawn.Min)) {
+			if id := region[cell]; id != 0 {
+				spawned[id] = true
+			}
+		}
+	}
+	mainRegion := 0
+	for id := 1; id <= len(sizes); id++ {
+		if spawned[id] && (mainRegion == 0 || sizes[id-1] > sizes[mainRegion-1]) {
+			mainRegion = id
+		}
+	}
+	if mainRegion != 0 {
+		report.Reachable = sizes[mainRegion-1]
+	}
+	for id := 1; id <= len(sizes); id++ {
+		if id == mainRegion {
+			continue
+		}
+		message := fmt.Sprintf("région isolée de %d cases, sans case d'apparition: les agents ne peuvent pas y aller", sizes[id-1])
+		if spawned[id] {
+			message = fmt.Sprintf("région isolée de %d cases: les agents qui y apparaissent ne peuvent pas en sortir", sizes[id-1])
+		}
+		report.Issues = append(report.Issues, Issue{Kind: UnreachableIssue, Message: message, Bounds: bounds[id-1]})
+	}
+
+	// Un objet doit avoir à côté de lui une case de la région principale depuis laquelle un agent peut l'utiliser
+	for _, object := range in.Objects {
+		approach := false
+		for _, cell := range in.cellsOf(object.Bounds.Inset(-in.TileSize)) {
+			if mainRegion != 0 && region[cell] == mainRegion {
+				approach = true
+				break
+			}
+		}
+		if !approach {
+			report.Issues = append(report.Issues, Issue{
+				Kind:    ApproachIssue,
+				Message: fmt.Sprintf("%s en %v n'a aucune case accessible à côté de lui", object.Name, object.Bounds.Min),
+				Bounds:  object.Bounds,
+			})
+		}
+	}
+	return report
+}
+
+// Fonction qui renvoie les obstacles de couches différentes qui se chevauchent, sous forme d'avertissements
+// (un ordinateur posé sur un bureau est volontaire). Les tiles d'une même couche se recouvrent souvent
+// (les murs verticaux font deux cases de haut, les coins se croisent): seuls les doublons exacts y sont signalés
+func (in *Input) overlaps() []Issue {
+	var issues []Issue
+	for i, a := range in.Colliders {
+		for _, b := range in.Colliders[i+1:] {
+			if !a.Bounds.Overlaps(b.Bounds) || (a.Name == b.Name && a.Bounds != b.Bounds) {
+				continue
+			}
+			issues = append(issues, Issue{
+				Kind:    OverlapIssue,
+				Message: fmt.Sprintf("%s %v chevauche %s %v", a.Name, a.Bounds, b.Name, b.Bounds),
+				Bounds:  a.Bounds.Intersect(b.Bounds),
+				Warning: true,
+			})
+		}
+	}
+	return issues
+}
+
+// Fonction qui renvoie les cases d'apparition où un agent toucherait un obstacle
+func (in *Input) blockedSpawns() []Issue {
+	var issues []Issue
+	for _, spawn := range in.Spawns {
+		if in.blocked(agentRect(spawn.Min)) {
+			issues = append(issues, Issue{
+				Kind:    SpawnIssue,
+				Message: fmt.Sprintf("case d'apparition %v couverte par un obstacle", spawn.Min),
+				Bounds:  spawn,
+			})
+		}
+	}
+	return issues
+}
+
+// Fonction qui renvoie les cases dans l'ordre de lecture, pour que les régions soient numérotées de façon reproductible
+func sortedCells(cells map[image.Point]bool) []image.Point {
+	sorted := make([]image.Point, 0, len(cells))
+	for cell := range cells {
+		sorted = append(sorted, cell)
+	}
+	slices.SortFunc(sorted, func(a, b image.Point) int {
+		if a.Y != b.Y {
+			return a.Y - b.Y
+		}
+		return a.X - b.X
+	})
+	return sorted
+}
+
+// Fonction de division entière arrondie vers le bas, y compris pour les coordonnées négatives
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
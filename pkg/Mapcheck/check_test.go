@@ -0,0 +1,115 @@
+package mapcheck
+
+import (
+	"image"
+	"slices"
+	"testing"
+)
+
+// Taille des cartes de test
+const testSize = 24
+
+// Fonction qui renvoie le rectangle de la case (x, y)
+func cell(x, y int) image.Rectangle {
+	return image.Rect(x*testSize, y*testSize, (x+1)*testSize, (y+1)*testSize)
+}
+
+// Fonction qui renvoie les obstacles d'une couche occupant les cases données
+func colliders(layer string, cells ...image.Rectangle) []Item {
+	items := make([]Item, len(cells))
+	for i, rect := range cells {
+		items[i] = Item{Name: layer, Bounds: rect}
+	}
+	return items
+}
+
+// Fonction qui crée une carte de 8x6 cases sans obstacle, avec une case d'apparition en haut à gauche
+func newTestInput() Input {
+	return Input{
+		TileSize: testSize,
+		Bounds:   image.Rect(0, 0, 8*testSize, 6*testSize),
+		Spawns:   []image.Rectangle{cell(0, 0)},
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name      string
+		edit      func(in *Input)
+		kinds     []string
+		ok        bool
+		reachable int
+	}{
+		{
+			name:      "carte libre",
+			edit:      func(in *Input) {},
+			ok:        true,
+			reachable: 48,
+		},
+		{
+			// Seuls les obstacles de couches différentes, ou les doublons exacts, sont signalés
+			name: "chevauchement",
+			edit: func(in *Input) {
+				in.Colliders = append(in.Colliders, colliders("bureaux", cell(5, 4))...)
+				in.Colliders = append(in.Colliders, colliders("ordinateurs", cell(5, 4))...)
+				in.Colliders = append(in.Colliders, colliders("murs", cell(7, 0), cell(7, 0).Add(image.Pt(0, 12)))...)
+			},
+			kinds:     []string{OverlapIssue},
+			ok:        true,
+			reachable: 45,
+		},
+		{
+			name: "case d'apparition bloquée",
+			edit: func(in *Input) {
+				in.Spawns = append(in.Spawns, cell(3, 3))
+				in.Colliders = colliders("murs", cell(3, 3))
+			},
+			kinds:     []string{SpawnIssue},
+			reachable: 47,
+		},
+		{
+			// Un mur sur toute la hauteur sépare les trois dernières colonnes
+			name: "région isolée",
+			edit: func(in *Input) {
+				in.Colliders = colliders("murs", cell(4, 0), cell(4, 1), cell(4, 2), cell(4, 3), cell(4, 4), cell(4, 5))
+			},
+			kinds:     []string{UnreachableIssue},
+			reachable: 24,
+		},
+		{
+			// Un ordinateur entouré d'obstacles, sans case libre à côté de lui
+			name: "objet sans case d'approche",
+			edit: func(in *Input) {
+				for y := 1; y <= 3; y++ {
+					for x := 1; x <= 3; x++ {
+						in.Colliders = append(in.Colliders, colliders("meubles", cell(x, y))...)
+					}
+				}
+				in.Objects = []Item{{Name: "ordinateur", Bounds: cell(2, 2)}}
+			},
+			kinds:     []string{ApproachIssue},
+			reachable: 39,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := newTestInput()
+			tt.edit(&in)
+			report := Check(in)
+
+			var kinds []string
+			for _, issue := range report.Issues {
+				kinds = append(kinds, issue.Kind)
+			}
+			if !slices.Equal(kinds, tt.kinds) {
+				t.Errorf("problèmes %v, attendu %v: %+v", kinds, tt.kinds, report.Issues)
+			}
+			if report.OK() != tt.ok {
+				t.Errorf("OK() = %v, attendu %v", report.OK(), tt.ok)
+			}
+			if report.Reachable != tt.reachable {
+				t.Errorf("%d cases accessibles, attendu %d", report.Reachable, tt.reachable)
+			}
+		})
+	}
+}
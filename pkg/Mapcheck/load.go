@@ -0,0 +1,55 @@
+package mapcheck
+
+import (
+	"fmt"
+	"image"
+
+	carte "github.com/Tmegaa/The-Gophecy/pkg/Carte"
+	tile "github.com/Tmegaa/The-Gophecy/pkg/Tile"
+)
+
+// Fonction qui charge une carte (nom du dossier des cartes, chemin ou description "generate:...") comme la simulation
+// et la vérifie. Les obstacles, objets et zones sont rapportés avec le nom de leur couche
+func CheckFile(mapFile string) (Input, Report, error) {
+	path := carte.MapPath(mapFile)
+	tilemapJSON, err := carte.ReadTilemap(path)
+	if err != nil {
+		return Input{}, Report{}, fmt.Errorf("échec du chargement de la carte %s: %v", path, err)
+	}
+	properties := tilemapJSON.TileProperties()
+
+	in := Input{
+		TileSize: carte.TileSize,
+		Bounds:   carte.MapBounds(tilemapJSON),
+	}
+
+	// Chaque couche est lue seule pour savoir d'où vient chaque rectangle
+	var coliders []image.Rectangle
+	for _, layer := range tilemapJSON.Layers {
+		single := *tilemapJSON
+		single.Layers = []tile.TilemapLayerJSON{layer}
+
+		layerColiders := carte.GenerateRectangles(&single, properties, tile.ColliderRole)
+		coliders = append(coliders, layerColiders...)
+		in.Colliders = append(in.Colliders, layerItems(layer.Name, layerColiders)...)
+		in.Objects = append(in.Objects, layerItems(layer.Name, carte.GenerateRectangles(&single, properties, tile.ComputerRole))...)
+		in.Objects = append(in.Objects, layerItems(layer.Name, carte.GenerateRectangles(&single, properties, tile.StatueRole))...)
+	}
+
+	in.Spawns = carte.GenerateSpawns(tilemapJSON, properties, coliders)
+	in.Walkable = carte.TileRectangles(tilemapJSON, properties, tile.WalkableRole)
+	in.Walkable = append(in.Walkable, carte.ObjectRectangles(tilemapJSON, properties, tile.WalkableRole, true)...)
+	for _, zone := range carte.GenerateZones(tilemapJSON) {
+		in.Zones = append(in.Zones, Item{Name: zone.Name, Bounds: zone.Bounds})
+	}
+	return in, Check(in), nil
+}
+
+// Fonction qui associe le nom d'une couche à chacun de ses rectangles
+func layerItems(name string, rectangles []image.Rectangle) []Item {
+	items := make([]Item, len(rectangles))
+	for i, rect := range rectangles {
+		items[i] = Item{Name: name, Bounds: rect}
+	}
+	return items
+}
@@ -0,0 +1,100 @@
+package mapcheck
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+
+	capture "github.com/Tmegaa/The-Gophecy/pkg/Capture"
+	carte "github.com/Tmegaa/The-Gophecy/pkg/Carte"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// Couleurs de l'image annotée
+var (
+	ColliderColor color.Color = color.NRGBA{200, 30, 30, 90}  // remplissage des obstacles
+	ColliderEdge  color.Color = color.NRGBA{200, 30, 30, 255} // bord des obstacles
+	SpawnColor    color.Color = color.NRGBA{30, 170, 60, 200} // cases d'apparition
+	ObjectEdge    color.Color = color.NRGBA{240, 150, 0, 255} // ordinateurs et statues
+	ZoneEdge      color.Color = color.NRGBA{40, 90, 220, 255} // zones nommées
+	IssueEdge     color.Color = color.NRGBA{255, 0, 255, 255} // erreurs détectées
+	WarningEdge   color.Color = color.NRGBA{255, 120, 0, 255} // avertissements
+	LabelColor    color.Color = color.White                   // texte des étiquettes
+)
+
+// Fonction qui dessine sur l'image de la carte les obstacles, les cases d'apparition, les objets, les zones
+// et les problèmes détectés. L'image doit couvrir in.Bounds: ses coordonnées sont celles du monde
+func Annotate(img *image.RGBA, in Input, report Report) {
+	for _, spawn := range in.Spawns {
+		dot := image.Rect(0, 0, 6, 6).Add(spawn.Min).Add(image.Pt(in.TileSize/2-3, in.TileSize/2-3))
+		draw.Draw(img, dot, image.NewUniform(SpawnColor), image.Point{}, draw.Over)
+	}
+	for _, collider := range in.Colliders {
+		draw.Draw(img, collider.Bounds, image.NewUniform(ColliderColor), image.Point{}, draw.Over)
+		strokeRect(img, collider.Bounds, ColliderEdge, 1)
+	}
+	for _, object := range in.Objects {
+		strokeRect(img, object.Bounds, ObjectEdge, 1)
+	}
+	for _, zone := range in.Zones {
+		strokeRect(img, zone.Bounds, ZoneEdge, 2)
+		drawLabel(img, zone.Name, zone.Bounds.Min.Add(image.Pt(4, 4)), ZoneEdge)
+	}
+	// Les avertissements sont seulement entourés, pour ne pas masquer les erreurs
+	for _, issue := range report.Issues {
+		if issue.Warning {
+			strokeRect(img, issue.Bounds, WarningEdge, 1)
+		}
+	}
+	for _, issue := range report.Issues {
+		if !issue.Warning {
+			strokeRect(img, issue.Bounds.Inset(-2), IssueEdge, 2)
+			drawLabel(img, issue.Kind, image.Pt(issue.Bounds.Min.X, issue.Bounds.Max.Y+4), IssueEdge)
+		}
+	}
+}
+
+// Fonction qui trace le contour d'un rectangle avec l'épaisseur donnée
+func strokeRect(img *image.RGBA, rect image.Rectangle, c color.Color, width int) {
+	src := image.NewUniform(c)
+	draw.Draw(img, image.Rect(rect.Min.X, rect.Min.Y, rect.Max.X, rect.Min.Y+width), src, image.Point{}, draw.Over)
+	draw.Draw(img, image.Rect(rect.Min.X, rect.Max.Y-width, rect.Max.X, rect.Max.Y), src, image.Point{}, draw.Over)
+	draw.Draw(img, image.Rect(rect.Min.X, rect.Min.Y+width, rect.Min.X+width, rect.Max.Y-width), src, image.Point{}, draw.Over)
+	draw.Draw(img, image.Rect(rect.Max.X-width, rect.Min.Y+width, rect.Max.X, rect.Max.Y-width), src, image.Point{}, draw.Over)
+}
+
+// Fonction qui écrit une étiquette sur fond coloré, le coin supérieur gauche en at
+func drawLabel(img *image.RGBA, text string, at image.Point, background color.Color) {
+	face := basicfont.Face7x13
+	box := image.Rect(0, 0, font.MeasureString(face, text).Ceil()+4, face.Height+2).Add(at)
+	draw.Draw(img, box, image.NewUniform(background), image.Point{}, draw.Over)
+
+	drawer := font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(LabelColor),
+		Face: face,
+		Dot:  fixed.P(at.X+2, at.Y+face.Ascent+1),
+	}
+	drawer.DrawString(text)
+}
+
+// Fonction qui écrit l'image de la carte annotée avec les obstacles, les cases d'apparition, les zones et les problèmes
+func RenderFile(mapFile string, in Input, report Report, pngPath string) error {
+	path := carte.MapPath(mapFile)
+	tilemapJSON, err := carte.ReadTilemap(path)
+	if err != nil {
+		return fmt.Errorf("échec du chargement de la carte %s: %v", path, err)
+	}
+	background, err := carte.RenderTilemap(tilemapJSON)
+	if err != nil {
+		return err
+	}
+	// L'image annotée est allouée sur l'étendue de la carte, pour que les rectangles du monde y tombent à leur place
+	img := image.NewRGBA(in.Bounds)
+	draw.Draw(img, img.Bounds(), background, in.Bounds.Min, draw.Src)
+	Annotate(img, in, report)
+	return capture.SavePNG(pngPath, img)
+}
@@ -0,0 +1,384 @@
+// Générateur procédural de campus: des salles reliées par des allées, avec leurs portes, des salles informatiques
+// et des statues. La carte produite est au format JSON de TILED et utilise les jeux de tiles fournis
+package mapgen
+
+import (
+	"fmt"
+	"image"
+	"math"
+
+	ut "github.com/Tmegaa/The-Gophecy/pkg/Utilitaries"
+)
+
+// Dimensions de la carte et des salles (en cases)
+const (
+	TileSize      = 24
+	MinWidth      = 24
+	MinHeight     = 18
+	MinRoomWidth  = 8
+	MinRoomHeight = 7
+
+	// Comme dans les cartes fournies, les deux premières lignes restent libres pour le haut des murs de deux cases:
+	// la ligne visible v est la ligne v+rowOffset des données
+	rowOffset = 2
+	// Nombre d'essais pour placer un objet dans la cour avant d'abandonner
+	placementAttempts = 500
+)
+
+// Identifiants globaux des tiles utilisées, dans les jeux de tiles de assets/maps/tilesets
+const (
+	hWallGID  = 391 // mur horizontal de deux cases
+	vWallGID  = 395 // mur vertical de deux cases
+	statueGID = 421
+)
+
+var (
+	computerGIDs = []int{419, 420}
+	propGIDs     = []int{426, 428, 433}
+)
+
+// Type de salle, utilisé comme classe des zones de la couche "zones"
+type RoomKind string
+
+const (
+	Lab         RoomKind = "lab"          // salle informatique
+	Chapel      RoomKind = "chapel"       // chapelle, où sont placées les statues
+	LectureHall RoomKind = "lecture_hall" // amphithéâtre
+)
+
+// Salle générée; les rectangles sont en cases visibles, murs compris
+type Room struct {
+	Kind   RoomKind
+	Bounds image.Rectangle
+}
+
+// Carte générée
+type Map struct {
+	Params    Params
+	Rooms     []Room
+	Computers int // nombre d'ordinateurs placés
+	Statues   int // nombre de statues placées
+
+	width, height int              // taille de la carte (en cases)
+	layers        map[string][]int // identifiants des tiles par couche
+	occupied      []bool           // cases visibles déjà prises par un mur ou un objet
+	rng           *ut.Rng
+}
+
+// Couches de la carte, dans l'ordre d'affichage; les murs et les meubles sont des obstacles
+var layerNames = []string{"floor", "walls", "furniture", "computers", "statues"}
+
+// Fonction qui génère une carte à partir des paramètres
+func Generate(params Params) (*Map, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+
+	m := &Map{
+		Params:   params,
+		width:    params.Width,
+		height:   params.Height,
+		layers:   make(map[string][]int),
+		occupied: make([]bool, params.Width*(params.Height-rowOffset)),
+		rng:      ut.NewRng(params.Seed),
+	}
+	for _, name := range layerNames {
+		m.layers[name] = make([]int, params.Width*params.Height)
+	}
+
+	m.paveFloor()
+	if err := m.placeRooms(); err != nil {
+		return nil, err
+	}
+	for _, room := range m.Rooms {
+		m.furnish(room)
+	}
+	if err := m.placeStatues(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Fonction qui renvoie le nombre de lignes visibles de la carte
+func (m *Map) visibleHeight() int {
+	return m.height - rowOffset
+}
+
+// Fonction qui place une tile dans une couche, à la case visible (c, v)
+func (m *Map) put(layer string, c, v, gid int) {
+	r := v + rowOffset
+	if c >= 0 && c < m.width && r >= 0 && r < m.height {
+		m.layers[layer][r*m.width+c] = gid
+	}
+}
+
+// Fonction qui indique si le rectangle de cases visibles est dans la carte et libre
+func (m *Map) free(area image.Rectangle) bool {
+	if !area.In(image.Rect(0, 0, m.width, m.visibleHeight())) {
+		return false
+	}
+	for v := area.Min.Y; v < area.Max.Y; v++ {
+		for c := area.Min.X; c < area.Max.X; c++ {
+			if m.occupied[v*m.width+c] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Fonction qui marque comme prises les cases du rectangle qui sont dans la carte
+func (m *Map) occupy(area image.Rectangle) {
+	area = area.Intersect(image.Rect(0, 0, m.width, m.visibleHeight()))
+	for v := area.Min.Y; v < area.Max.Y; v++ {
+		for c := area.Min.X; c < area.Max.X; c++ {
+			m.occupied[v*m.width+c] = true
+		}
+	}
+}
+
+// Fonction qui pave toute la partie visible de la carte avec les quatre tiles de sol, en damier comme la carte d'origine
+func (m *Map) paveFloor() {
+	for v := 0; v < m.visibleHeight(); v++ {
+		for c := 0; c < m.width; c++ {
+			gid := 21
+			if (v+rowOffset)%2 == 1 {
+				gid = 5
+			}
+			if c%2 == 0 {
+				gid++
+			}
+			m.put("floor", c, v, gid)
+		}
+	}
+}
+
+// Fonction qui renvoie un entier tiré uniformément entre lo et hi (inclus)
+func (m *Map) between(lo, hi int) int {
+	if hi <= lo {
+		return lo
+	}
+	return lo + m.rng.IntN(hi-lo+1)
+}
+
+// Fonction qui découpe la carte en emplacements, tire ceux qui reçoivent une salle et y construit les salles,
+// séparées par des allées d'au moins deux cases
+func (m *Map) placeRooms() error {
+	rooms := m.Params.Rooms
+	areaWidth, areaHeight := m.width-2, m.visibleHeight()-2
+	columns := min(rooms, int(math.Ceil(math.Sqrt(float64(rooms*areaWidth)/float64(areaHeight)))))
+	rows := (rooms + columns - 1) / columns
+	slotWidth, slotHeight := areaWidth/columns, areaHeight/rows
+	if slotWidth < MinRoomWidth+2 || slotHeight < MinRoomHeight+2 {
+		return fmt.Errorf("trop de salles (%d) pour une carte de %dx%d cases", rooms, m.width, m.height)
+	}
+
+	slots := make([]image.Point, 0, columns*rows)
+	for j := 0; j < rows; j++ {
+		for i := 0; i < columns; i++ {
+			slots = append(slots, image.Pt(1+i*slotWidth, 1+j*slotHeight))
+		}
+	}
+	m.rng.Shuffle(len(slots), func(i, j int) { slots[i], slots[j] = slots[j], slots[i] })
+
+	kinds := m.roomKinds()
+	for i, slot := range slots[:rooms] {
+		width := m.between(max(MinRoomWidth, slotWidth*2/3), slotWidth-2)
+		height := m.between(max(MinRoomHeight, slotHeight*2/3), slotHeight-2)
+		x := slot.X + m.between(0, slotWidth-2-width)
+		y := slot.Y + m.between(0, slotHeight-2-height)
+
+		room := Room{Kind: kinds[i], Bounds: image.Rect(x, y, x+width, y+height)}
+		m.buildWalls(room.Bounds)
+		m.Rooms = append(m.Rooms, room)
+	}
+	return nil
+}
+
+// Fonction qui répartit les types de salles: un tiers de salles informatiques (au moins une),
+// une chapelle s'il y a des statues et plus d'une salle, des salles de cours pour le reste
+func (m *Map) roomKinds() []RoomKind {
+	rooms := m.Params.Rooms
+	chapels := 0
+	if m.Params.Statues > 0 && rooms > 1 {
+		chapels = 1
+	}
+	labs := min(max(1, (rooms+2)/3), rooms-chapels)
+
+	kinds := make([]RoomKind, rooms)
+	for i := range kinds {
+		switch {
+		case i < labs:
+			kinds[i] = Lab
+		case i < labs+chapels:
+			kinds[i] = Chapel
+		default:
+			kinds[i] = LectureHall
+		}
+	}
+	m.rng.Shuffle(len(kinds), func(i, j int) { kinds[i], kinds[j] = kinds[j], kinds[i] })
+	return kinds
+}
+
+// Fonction qui construit les murs d'une salle, percés d'une ou deux portes de deux cases sur des côtés différents
+func (m *Map) buildWalls(bounds image.Rectangle) {
+	left, right := bounds.Min.X, bounds.Max.X-1
+	top, bottom := bounds.Min.Y, bounds.Max.Y-1
+
+	// Côtés percés: 0 haut, 1 bas, 2 gauche, 3 droite
+	sides := []int{0, 1, 2, 3}
+	m.rng.Shuffle(len(sides), func(i, j int) { sides[i], sides[j] = sides[j], sides[i] })
+	doors := map[int]int{}
+	for _, side := range sides[:1+m.rng.IntN(2)] {
+		if side < 2 {
+			// Les murs horizontaux font deux cases: la porte remplace un morceau entier
+			doors[side] = left + 2*m.between(1, (right-left-3)/2)
+		} else {
+			doors[side] = m.between(top+2, bottom-3)
+		}
+	}
+
+	// Murs horizontaux, posés par morceaux de deux cases
+	for side, v := range []int{top, bottom} {
+		door, hasDoor := doors[side]
+		for c := left; c < right; c += 2 {
+			if !hasDoor || c != door {
+				m.put("walls", c, v, hWallGID)
+				m.occupy(image.Rect(c, v, c+2, v+1))
+			}
+		}
+	}
+
+	// Murs verticaux: chaque morceau couvre sa case et celle du dessus. À gauche, la case du coin inférieur
+	// porte déjà le premier morceau du mur du bas
+	for i, c := range []int{left, right} {
+		side := 2 + i
+		door, hasDoor := doors[side]
+		last := bottom
+		if side == 2 {
+			last = bottom - 1
+		}
+		for v := top + 1; v <= last; v++ {
+			if hasDoor && v >= door && v <= door+2 {
+				continue
+			}
+			m.put("walls", c, v, vWallGID)
+			m.occupy(image.Rect(c, v-1, c+1, v+1))
+		}
+	}
+}
+
+// Fonction qui place un objet dont le coin inférieur gauche est sur la case (c, v) s'il y a la place,
+// en gardant une case libre autour pour que les agents puissent passer
+func (m *Map) place(layer string, c, v, width, height, gid int) bool {
+	footprint := image.Rect(c, v-height+1, c+width, v+1)
+	if !m.free(footprint) {
+		return false
+	}
+	m.put(layer, c, v, gid)
+	m.occupy(footprint.Inset(-1))
+	return true
+}
+
+// Fonction qui renvoie l'intérieur d'une salle où poser des objets: une case libre est gardée le long des murs
+func interior(room Room) image.Rectangle {
+	return room.Bounds.Inset(2)
+}
+
+// Fonction qui meuble une salle selon son type
+func (m *Map) furnish(room Room) {
+	inside := interior(room)
+	switch room.Kind {
+	case Lab:
+		// Postes de travail alignés, équipés d'un ordinateur selon la densité demandée
+		placed := false
+		for v := inside.Min.Y + 1; v < inside.Max.Y; v += 3 {
+			for c := inside.Min.X; c+2 <= inside.Max.X; c += 3 {
+				if m.rng.Float64() < m.Params.ComputerDensity && m.placeComputer(c, v) {
+					placed = true
+				}
+			}
+		}
+		// Une salle informatique contient au moins un ordinateur, sauf si la densité est nulle
+		if !placed && m.Params.ComputerDensity > 0 {
+			m.placeComputer(inside.Min.X, inside.Min.Y+1)
+		}
+	case Chapel:
+		// Les statues sont placées ensuite, avec quelques bancs au fond de la chapelle
+		m.place("furniture", inside.Min.X, inside.Max.Y-1, 2, 1, propGIDs[0])
+		m.place("furniture", inside.Max.X-2, inside.Max.Y-1, 2, 1, propGIDs[0])
+	case LectureHall:
+		for range m.between(2, 4) {
+			for range placementAttempts {
+				c := m.between(inside.Min.X, inside.Max.X-2)
+				v := m.between(inside.Min.Y+1, inside.Max.Y-1)
+				if m.place("furniture", c, v, 2, 2, propGIDs[m.rng.IntN(len(propGIDs))]) {
+					break
+				}
+			}
+		}
+	}
+}
+
+// Fonction qui place un ordinateur (deux cases sur deux) et le compte
+func (m *Map) placeComputer(c, v int) bool {
+	if !m.place("computers", c, v, 2, 2, computerGIDs[m.rng.IntN(len(computerGIDs))]) {
+		return false
+	}
+	m.Computers++
+	return true
+}
+
+// Fonction qui place les statues: dans les chapelles d'abord, puis dans la cour, puis dans les autres salles
+func (m *Map) placeStatues() error {
+	var chapels, others []image.Rectangle
+	for _, room := range m.Rooms {
+		if room.Kind == Chapel {
+			chapels = append(chapels, interior(room))
+		} else {
+			others = append(others, interior(room))
+		}
+	}
+	courtyard := []image.Rectangle{image.Rect(1, 1, m.width-1, m.visibleHeight()-1)}
+
+	for m.Statues < m.Params.Statues {
+		if !m.placeStatue(chapels, false) && !m.placeStatue(courtyard, true) && !m.placeStatue(others, false) {
+			return fmt.Errorf("impossible de placer %d statues sur une carte de %dx%d cases (%d placées)",
+				m.Params.Statues, m.width, m.height, m.Statues)
+		}
+	}
+	return nil
+}
+
+// Fonction qui indique si un rectangle de cases est dehors, à au moins deux cases de toute salle
+func (m *Map) outdoors(area image.Rectangle) bool {
+	for _, room := range m.Rooms {
+		if area.Overlaps(room.Bounds.Inset(-2)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Fonction qui place une statue (deux cases sur trois) au hasard dans l'une des zones, éventuellement hors des salles
+func (m *Map) placeStatue(areas []image.Rectangle, outdoors bool) bool {
+	if len(areas) == 0 {
+		return false
+	}
+	for range placementAttempts {
+		area := areas[m.rng.IntN(len(areas))]
+		if area.Dx() < 2 || area.Dy() < 3 {
+			continue
+		}
+		c := m.between(area.Min.X, area.Max.X-2)
+		v := m.between(area.Min.Y+2, area.Max.Y-1)
+		if outdoors && !m.outdoors(image.Rect(c, v-2, c+2, v+1)) {
+			continue
+		}
+		if m.place("statues", c, v, 2, 3, statueGID) {
+			m.Statues++
+			return true
+		}
+	}
+	return false
+}
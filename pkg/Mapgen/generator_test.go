@@ -0,0 +1,108 @@
+package mapgen
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseParams(t *testing.T) {
+	defaults := DefaultParams()
+	tests := []struct {
+		spec string
+		want Params
+		err  bool
+	}{
+		{spec: "generate:", want: defaults},
+		{spec: "generate:seed=7,rooms=8", want: Params{Seed: 7, Width: 80, Height: 45, Rooms: 8, ComputerDensity: 0.5, Statues: 1}},
+		{spec: "width=30, height=20 ,computers=0,statues=0", want: Params{Seed: 1, Width: 30, Height: 20, Rooms: 6, ComputerDensity: 0, Statues: 0}},
+		{spec: "generate:rooms", err: true},
+		{spec: "generate:floors=2", err: true},
+		{spec: "generate:seed=-1", err: true},
+		{spec: "generate:computers=1.5", err: true},
+		{spec: "generate:width=10", err: true},
+		{spec: "generate:rooms=0", err: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseParams(tt.spec)
+		if tt.err {
+			if err == nil {
+				t.Errorf("%q: pas d'erreur", tt.spec)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: erreur inattendue: %v", tt.spec, err)
+		} else if got != tt.want {
+			t.Errorf("%q: paramètres %+v, attendu %+v", tt.spec, got, tt.want)
+		}
+	}
+
+	// Les paramètres relus depuis leur description sont identiques
+	params := Params{Seed: 42, Width: 60, Height: 30, Rooms: 4, ComputerDensity: 0.25, Statues: 2}
+	if got, err := ParseParams(Prefix + params.String()); err != nil || got != params {
+		t.Errorf("relecture de %s: %+v (%v)", params, got, err)
+	}
+}
+
+// Fonction qui génère une carte et renvoie son contenu JSON
+func generateJSON(t *testing.T, params Params) []byte {
+	t.Helper()
+	m, err := Generate(params)
+	if err != nil {
+		t.Fatalf("%s: erreur inattendue: %v", params, err)
+	}
+	contents, err := m.JSON("tilesets")
+	if err != nil {
+		t.Fatalf("%s: erreur inattendue: %v", params, err)
+	}
+	return contents
+}
+
+func TestGenerateDeterministic(t *testing.T) {
+	params := DefaultParams()
+	params.Seed = 7
+	first, second := generateJSON(t, params), generateJSON(t, params)
+	if !bytes.Equal(first, second) {
+		t.Errorf("deux cartes différentes pour les mêmes paramètres %s", params)
+	}
+
+	params.Seed = 8
+	if bytes.Equal(first, generateJSON(t, params)) {
+		t.Errorf("la même carte pour les graines 7 et 8")
+	}
+}
+
+func TestGenerateTooManyRooms(t *testing.T) {
+	params := DefaultParams()
+	params.Width, params.Height, params.Rooms = MinWidth, MinHeight, 12
+	_, err := Generate(params)
+	if err == nil || !strings.Contains(err.Error(), "trop de salles") {
+		t.Errorf("erreur %v, attendu trop de salles", err)
+	}
+}
+
+func TestGenerateWithoutComputers(t *testing.T) {
+	params := DefaultParams()
+	params.ComputerDensity = 0
+	m, err := Generate(params)
+	if err != nil {
+		t.Fatalf("erreur inattendue: %v", err)
+	}
+	if m.Computers != 0 {
+		t.Errorf("%d ordinateurs placés, attendu 0", m.Computers)
+	}
+	if len(m.Rooms) != params.Rooms || m.Statues != params.Statues {
+		t.Errorf("%d salles et %d statues, attendu %d et %d", len(m.Rooms), m.Statues, params.Rooms, params.Statues)
+	}
+	// Les salles informatiques existent toujours, sans ordinateur
+	if !slices.ContainsFunc(m.Rooms, func(room Room) bool { return room.Kind == Lab }) {
+		t.Errorf("aucune salle informatique")
+	}
+	for _, gid := range m.layers["computers"] {
+		if gid != 0 {
+			t.Fatalf("tile %d dans la couche des ordinateurs", gid)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package mapgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+)
+
+// Jeux de tiles utilisés par la carte générée, avec leur premier identifiant global (comme dans assets/maps/spawn.json)
+var tilesets = []struct {
+	firstGID int
+	file     string
+}{
+	{1, "img.json"},
+	{385, "obj.json"},
+	{419, "objcomputers.json"},
+	{421, "objstatue.json"},
+	{422, "obj10.json"},
+}
+
+// Couche au format JSON de TILED
+type layerJSON struct {
+	Data       []int          `json:"data,omitempty"`
+	Objects    []objectJSON   `json:"objects,omitempty"`
+	DrawOrder  string         `json:"draworder,omitempty"`
+	Width      int            `json:"width,omitempty"`
+	Height     int            `json:"height,omitempty"`
+	Id         int            `json:"id"`
+	Name       string         `json:"name"`
+	Opacity    float64        `json:"opacity"`
+	Properties []propertyJSON `json:"properties,omitempty"`
+	Type       string         `json:"type"`
+	Visible    bool           `json:"visible"`
+	X          int            `json:"x"`
+	Y          int            `json:"y"`
+}
+
+// Objet rectangulaire au format JSON de TILED
+type objectJSON struct {
+	Id       int     `json:"id"`
+	Name     string  `json:"name"`
+	Type     string  `json:"type"`
+	X        float64 `json:"x"`
+	Y        float64 `json:"y"`
+	Width    float64 `json:"width"`
+	Height   float64 `json:"height"`
+	Rotation float64 `json:"rotation"`
+	Visible  bool    `json:"visible"`
+}
+
+// Propriété personnalisée au format JSON de TILED
+type propertyJSON struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value any    `json:"value"`
+}
+
+// Référence à un jeu de tiles externe
+type tilesetRefJSON struct {
+	FirstGID int    `json:"firstgid"`
+	Source   string `json:"source"`
+}
+
+// Carte au format JSON de TILED
+type mapJSON struct {
+	CompressionLevel int              `json:"compressionlevel"`
+	Width            int              `json:"width"`
+	Height           int              `json:"height"`
+	Infinite         bool             `json:"infinite"`
+	Layers           []layerJSON      `json:"layers"`
+	NextLayerId      int              `json:"nextlayerid"`
+	NextObjectId     int              `json:"nextobjectid"`
+	Orientation      string           `json:"orientation"`
+	Properties       []propertyJSON   `json:"properties"`
+	RenderOrder      string           `json:"renderorder"`
+	TiledVersion     string           `json:"tiledversion"`
+	TileWidth        int              `json:"tilewidth"`
+	TileHeight       int              `json:"tileheight"`
+	Tilesets         []tilesetRefJSON `json:"tilesets"`
+	Type             string           `json:"type"`
+	Version          string           `json:"version"`
+}
+
+// Fonction qui renvoie la carte au format JSON de TILED. Les jeux de tiles sont cherchés dans tilesetDir,
+// relatif au fichier de la carte ("tilesets" pour une carte enregistrée dans assets/maps)
+func (m *Map) JSON(tilesetDir string) ([]byte, error) {
+	tiled := mapJSON{
+		CompressionLevel: -1,
+		Width:            m.width,
+		Height:           m.height,
+		Orientation:      "orthogonal",
+		Properties:       []propertyJSON{{Name: "generator", Type: "string", Value: Prefix + m.Params.String()}},
+		RenderOrder:      "right-down",
+		TiledVersion:     "1.11.0",
+		TileWidth:        TileSize,
+		TileHeight:       TileSize,
+		Type:             "map",
+		Version:          "1.10",
+	}
+	for _, tileset := range tilesets {
+		tiled.Tilesets = append(tiled.Tilesets, tilesetRefJSON{FirstGID: tileset.firstGID, Source: path.Join(tilesetDir, tileset.file)})
+	}
+
+	for _, name := range layerNames {
+		layer := layerJSON{
+			Data:    m.layers[name],
+			Width:   m.width,
+			Height:  m.height,
+			Id:      len(tiled.Layers) + 1,
+			Name:    name,
+			Opacity: 1,
+			Type:    "tilelayer",
+			Visible: true,
+		}
+		if name == "walls" || name == "furniture" {
+			layer.Properties = []propertyJSON{{Name: "collider", Type: "bool", Value: true}}
+		}
+		tiled.Layers = append(tiled.Layers, layer)
+	}
+
+	// Les salles sont aussi décrites comme zones: des rectangles dont la classe est leur type, en coordonnées de TILED
+	rooms := layerJSON{Id: len(tiled.Layers) + 1, Name: "zones", DrawOrder: "topdown", Opacity: 1, Type: "objectgroup", Visible: true}
+	for i, room := range m.Rooms {
+		rooms.Objects = append(rooms.Objects, objectJSON{
+			Id:      i + 1,
+			Name:    fmt.Sprintf("%s %d", room.Kind, i+1),
+			Type:    string(room.Kind),
+			X:       float64(room.Bounds.Min.X * TileSize),
+			Y:       float64((room.Bounds.Min.Y + rowOffset) * TileSize),
+			Width:   float64(room.Bounds.Dx() * TileSize),
+			Height:  float64(room.Bounds.Dy() * TileSize),
+			Visible: true,
+		})
+	}
+	tiled.Layers = append(tiled.Layers, rooms)
+	tiled.NextLayerId = len(tiled.Layers) + 1
+	tiled.NextObjectId = len(m.Rooms) + 1
+
+	return json.Marshal(tiled)
+}
+
+// Fonction qui enregistre la carte dans un fichier JSON de TILED
+func (m *Map) Save(filePath, tilesetDir string) error {
+	contents, err := m.JSON(tilesetDir)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, contents, 0644)
+}
@@ -0,0 +1,97 @@
+package mapgen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Préfixe d'une carte générée dans l'option -map, suivi de ses paramètres: "generate:seed=7,rooms=8,statues=3"
+const Prefix = "generate:"
+
+// Paramètres du générateur de campus
+type Params struct {
+	Seed            uint64  // graine du générateur: les mêmes paramètres donnent toujours la même carte
+	Width           int     // largeur de la carte (en cases)
+	Height          int     // hauteur de la carte (en cases)
+	Rooms           int     // nombre de salles
+	ComputerDensity float64 // proportion des postes des salles informatiques équipés d'un ordinateur (0 à 1)
+	Statues         int     // nombre de statues
+}
+
+// Fonction qui renvoie les paramètres par défaut, proches de la carte d'origine
+func DefaultParams() Params {
+	return Params{
+		Seed:            1,
+		Width:           80,
+		Height:          45,
+		Rooms:           6,
+		ComputerDensity: 0.5,
+		Statues:         1,
+	}
+}
+
+// Fonction qui indique si le nom de carte désigne une carte générée
+func IsSpec(mapFile string) bool {
+	return strings.HasPrefix(mapFile, Prefix)
+}
+
+// Fonction qui lit les paramètres d'une carte générée ("seed=7,rooms=8", avec ou sans préfixe);
+// les paramètres absents gardent leur valeur par défaut
+func ParseParams(spec string) (Params, error) {
+	params := DefaultParams()
+	spec = strings.TrimPrefix(spec, Prefix)
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		key, value, found := strings.Cut(field, "=")
+		if !found {
+			return params, fmt.Errorf("paramètre de génération invalide %q (attendu: clé=valeur)", field)
+		}
+
+		var err error
+		switch strings.TrimSpace(key) {
+		case "seed":
+			params.Seed, err = strconv.ParseUint(value, 10, 64)
+		case "width":
+			params.Width, err = strconv.Atoi(value)
+		case "height":
+			params.Height, err = strconv.Atoi(value)
+		case "rooms":
+			params.Rooms, err = strconv.Atoi(value)
+		case "computers":
+			params.ComputerDensity, err = strconv.ParseFloat(value, 64)
+		case "statues":
+			params.Statues, err = strconv.Atoi(value)
+		default:
+			return params, fmt.Errorf("paramètre de génération inconnu %q", key)
+		}
+		if err != nil {
+			return params, fmt.Errorf("valeur invalide pour %s: %v", key, err)
+		}
+	}
+	return params, params.Validate()
+}
+
+// Fonction qui vérifie que les paramètres permettent de générer une carte
+func (p Params) Validate() error {
+	switch {
+	case p.Width < MinWidth || p.Height < MinHeight:
+		return fmt.Errorf("la carte doit faire au moins %dx%d cases", MinWidth, MinHeight)
+	case p.Rooms < 1:
+		return fmt.Errorf("la carte doit contenir au moins une salle")
+	case p.ComputerDensity < 0 || p.ComputerDensity > 1:
+		return fmt.Errorf("la densité d'ordinateurs doit être comprise entre 0 et 1")
+	case p.Statues < 0:
+		return fmt.Errorf("le nombre de statues ne peut pas être négatif")
+	}
+	return nil
+}
+
+// Fonction qui renvoie les paramètres au format de l'option -map, sans le préfixe
+func (p Params) String() string {
+	return fmt.Sprintf("seed=%d,width=%d,height=%d,rooms=%d,computers=%g,statues=%d",
+		p.Seed, p.Width, p.Height, p.Rooms, p.ComputerDensity, p.Statues)
+}
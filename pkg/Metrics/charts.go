@@ -0,0 +1,293 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	ag "github.com/Tmegaa/The-Gophecy/pkg/Agent"
+
+	"github.com/wcharczuk/go-chart/v2"
+	"github.com/wcharczuk/go-chart/v2/drawing"
+)
+
+// Couleurs associées à chaque type d'agent dans les graphiques
+var TypeColors = map[ag.TypeAgent]drawing.Color{
+	ag.Sceptic:  {R: 220, G: 50, B: 50, A: 255},
+	ag.Neutral:  {R: 150, G: 150, B: 150, A: 255},
+	ag.Believer: {R: 30, G: 30, B: 30, A: 255},
+}
+
+// Fonction qui trace l'opinion moyenne en fonction du temps
+func (r *Recorder) RenderOpinion(provider chart.RendererProvider, w io.Writer) error {
+	graph := chart.Chart{
+		XAxis: chart.XAxis{Name: "Tick"},
+		YAxis: chart.YAxis{Name: "Opinion moyenne"},
+		Series: []chart.Series{
+			chart.ContinuousSeries{
+				Name:    "Opinion moyenne",
+				XValues: r.Ticks(),
+				YValues: r.Means(),
+			},
+		},
+	}
+	return graph.Render(provider, w)
+}
+
+// Fonction qui trace la part de chaque type d'agent dans la population en fonction du temps
+func (r *Recorder) RenderTypeShares(provider chart.RendererProvider, w io.Writer) error {
+	series := make([]chart.Series, 0, len(AgentTypes))
+	for _, t := range AgentTypes {
+		shares := make([]float64, len(r.Samples))
+		for i, s := range r.Samples {
+			total := 0
+			for _, count := range s.TypeCounts {
+				total += count
+			}
+			if total > 0 {
+				shares[i] = float64(s.TypeCounts[t]) / float64(total)
+			}
+		}
+		series = append(series, chart.ContinuousSeries{
+			Name:    string(t),
+			XValues: r.Ticks(),
+			YValues: shares,
+			Style:   chart.Style{StrokeColor: TypeColors[t], StrokeWidth: 2},
+		})
+	}
+
+	graph := chart.Chart{
+		XAxis:  chart.XAxis{Name: "Tick"},
+		YAxis:  chart.YAxis{Name: "Part de la population", Range: &chart.ContinuousRange{Min: 0, Max: 1}},
+		Series: series,
+	}
+	graph.Elements = []chart.Renderable{chart.Legend(&graph)}
+	return graph.Render(provider, w)
+}
+
+// Fonction qui trace la variance des opinions en fonction du temps
+func (r *Recorder) RenderVariance(provider chart.RendererProvider, w io.Writer) error {
+	graph := chart.Chart{
+		XAxis: chart.XAxis{Name: "Tick"},
+		YAxis: chart.YAxis{Name: "Variance des opinions"},
+		Series: []chart.Series{
+			chart.ContinuousSeries{
+				Name:    "Variance",
+				XValues: r.Ticks(),
+				YValues: r.Variances(),
+			},
+		},
+	}
+	return graph.Render(provider, w)
+}
+
+// Fonction qui trace l'opinion moyenne de chaque type d'agent en fonction du temps
+func (r *Recorder) RenderTypeOpinions(provider chart.RendererProvider, w io.Writer) error {
+	series := make([]chart.Series, 0, len(AgentTypes)+1)
+	series = append(series, chart.ContinuousSeries{
+		Name:    "Population",
+		XValues: r.Ticks(),
+		YValues: r.Means(),
+		Style:   chart.Style{StrokeColor: drawing.Color{R: 60, G: 90, B: 200, A: 255}, StrokeWidth: 2, StrokeDashArray: []float64{5, 5}},
+	})
+	for _, t := range AgentTypes {
+		// Seuls les échantillons où le type compte des agents sont tracés
+		var ticks, means []float64
+		for _, s := range r.Samples {
+			if mean, ok := s.TypeMeans[t]; ok {
+				ticks = append(ticks, float64(s.Tick))
+				means = append(means, mean)
+			}
+		}
+		if len(ticks) < 2 {
+			continue
+		}
+		series = append(series, chart.ContinuousSeries{
+			Name:    string(t),
+			XValues: ticks,
+			YValues: means,
+			Style:   chart.Style{StrokeColor: TypeColors[t], StrokeWidth: 2},
+		})
+	}
+
+	graph := chart.Chart{
+		XAxis:  chart.XAxis{Name: "Tick"},
+		YAxis:  chart.YAxis{Name: "Opinion moyenne", Range: &chart.ContinuousRange{Min: 0, Max: 1}},
+		Series: series,
+	}
+	graph.Elements = []chart.Renderable{chart.Legend(&graph)}
+	return graph.Render(provider, w)
+}
+
+// Fonction qui trace les indicateurs de polarisation en fonction du temps
+func (r *Recorder) RenderIndicators(provider chart.RendererProvider, w io.Writer) error {
+	bimodality := make([]float64, len(r.Samples))
+	polarization := make([]float64, len(r.Samples))
+	entropy := make([]float64, len(r.Samples))
+	for i, s := range r.Samples {
+		bimodality[i] = s.Indicators.Bimodality
+		polarization[i] = s.Indicators.Polarization
+		entropy[i] = s.Indicators.Entropy
+	}
+
+	graph := chart.Chart{
+		XAxis: chart.XAxis{Name: "Tick"},
+		YAxis: chart.YAxis{Name: "Indicateur"},
+		Series: []chart.Series{
+			chart.ContinuousSeries{Name: "Bimodalité", XValues: r.Ticks(), YValues: bimodality},
+			chart.ContinuousSeries{Name: "Polarisation", XValues: r.Ticks(), YValues: polarization},
+			chart.ContinuousSeries{Name: "Entropie", XValues: r.Ticks(), YValues: entropy},
+		},
+	}
+	graph.Elements = []chart.Renderable{chart.Legend(&graph)}
+	return graph.Render(provider, w)
+}
+
+// Fonction qui trace le nombre d'ordinateurs sur lesquels Go est installé en fonction du temps
+func (r *Recorder) RenderGoComputers(provider chart.RendererProvider, w io.Writer) error {
+	computers := make([]float64, len(r.Samples))
+	peak := 1.0
+	for i, s := range r.Samples {
+		computers[i] = float64(s.GoComputers)
+		peak = max(peak, computers[i])
+	}
+
+	graph := chart.Chart{
+		XAxis: chart.XAxis{Name: "Tick"},
+		YAxis: chart.YAxis{Name: "Ordinateurs sous Go", Range: &chart.ContinuousRange{Min: 0, Max: peak}},
+		Series: []chart.Series{
+			chart.ContinuousSeries{
+				Name:    "Ordinateurs sous Go",
+				XValues: r.Ticks(),
+				YValues: computers,
+				Style:   chart.Style{StrokeColor: drawing.Color{R: 0, G: 173, B: 216, A: 255}, StrokeWidth: 2},
+			},
+		},
+	}
+	return graph.Render(provider, w)
+}
+
+// Fonction qui trace l'histogramme des opinions d'un échantillon
+func (r *Recorder) RenderHistogram(provider chart.RendererProvider, w io.Writer, s Sample, title string) error {
+	bars := make([]chart.Value, len(s.Histogram))
+	peak := 1.0
+	for b, count := range s.Histogram {
+		bars[b] = chart.Value{Value: float64(count), Label: fmt.Sprintf("%.1f", float64(b)/float64(len(s.Histogram)))}
+		peak = max(peak, float64(count))
+	}
+
+	graph := chart.BarChart{
+		Title:    title,
+		Height:   300,
+		BarWidth: 30,
+		YAxis:    chart.YAxis{Range: &chart.ContinuousRange{Min: 0, Max: peak}},
+		Bars:     bars,
+	}
+	return graph.Render(provider, w)
+}
+
+// Fonction qui trace la carte de chaleur de l'histogramme des opinions au cours du temps:
+// l'axe horizontal représente le temps, l'axe vertical les classes d'opinion (0 en bas, 1 en haut)
+func (r *Recorder) RenderHistogramHeatmap(provider chart.RendererProvider, w io.Writer, width, height int) error {
+	renderer, err := provider(width, height)
+	if err != nil {
+		return err
+	}
+
+	// Fond blanc
+	renderer.SetFillColor(drawing.ColorWhite)
+	fillRect(renderer, 0, 0, width, height)
+
+	if len(r.Samples) > 0 {
+		// On regroupe les échantillons par colonne pour ne pas dépasser la largeur de l'image
+		columns := min(len(r.Samples), width)
+		cellW := float64(width) / float64(columns)
+		cellH := float64(height) / float64(r.Bins)
+
+		peak := 1
+		for _, s := range r.Samples {
+			for _, count := range s.Histogram {
+				peak = max(peak, count)
+			}
+		}
+
+		for c := 0; c < columns; c++ {
+			s := r.Samples[c*len(r.Samples)/columns]
+			for b, count := range s.Histogram {
+				intensity := float64(count) / float64(peak)
+				renderer.SetFillColor(heatColor(intensity))
+				x0 := int(float64(c) * cellW)
+				y0 := height - int(float64(b+1)*cellH)
+				fillRect(renderer, x0, y0, int(float64(c+1)*cellW), y0+int(cellH)+1)
+			}
+		}
+	}
+
+	// Légende des axes
+	font, err := chart.GetDefaultFont()
+	if err != nil {
+		return err
+	}
+	renderer.SetFont(font)
+	renderer.SetFontSize(10)
+	renderer.SetFontColor(drawing.ColorBlack)
+	renderer.Text("opinion 1", 4, 12)
+	renderer.Text("opinion 0", 4, height-4)
+	if len(r.Samples) > 0 {
+		renderer.Text(fmt.Sprintf("tick %d", r.Samples[len(r.Samples)-1].Tick), width-80, height-4)
+	}
+
+	return renderer.Save(w)
+}
+
+// Fonction qui remplit un rectangle avec la couleur de remplissage courante
+func fillRect(renderer chart.Renderer, x0, y0, x1, y1 int) {
+	renderer.MoveTo(x0, y0)
+	renderer.LineTo(x1, y0)
+	renderer.LineTo(x1, y1)
+	renderer.LineTo(x0, y1)
+	renderer.Close()
+	renderer.Fill()
+}
+
+// Fonction qui renvoie une couleur allant du bleu foncé (0) au jaune (1)
+func heatColor(intensity float64) drawing.Color {
+	intensity = max(0, min(1, intensity))
+	return drawing.Color{
+		R: uint8(20 + 235*intensity),
+		G: uint8(20 + 200*intensity),
+		B: uint8(90 * (1 - intensity)),
+		A: 255,
+	}
+}
+
+// Fonction qui enregistre tous les graphiques au format PNG dans le dossier donné
+func (r *Recorder) SaveCharts(dir string) error {
+	// Il faut au moins deux échantillons pour tracer une courbe
+	if len(r.Samples) < 2 {
+		return nil
+	}
+
+	charts := map[string]func(io.Writer) error{
+		"opinion_averages.png": func(w io.Writer) error { return r.RenderOpinion(chart.PNG, w) },
+		"type_shares.png":      func(w io.Writer) error { return r.RenderTypeShares(chart.PNG, w) },
+		"variance.png":         func(w io.Writer) error { return r.RenderVariance(chart.PNG, w) },
+		"histogram_heatmap.png": func(w io.Writer) error {
+			return r.RenderHistogramHeatmap(chart.PNG, w, 800, 400)
+		},
+	}
+
+	for name, render := range charts {
+		file, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		err = render(file)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("échec du rendu du graphique %s: %v", name, err)
+		}
+	}
+	return nil
+}
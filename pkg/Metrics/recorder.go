@@ -0,0 +1,294 @@
+package metrics
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	ag "github.com/Tmegaa/The-Gophecy/pkg/Agent"
+	analysis "github.com/Tmegaa/The-Gophecy/pkg/Analysis"
+	carte "github.com/Tmegaa/The-Gophecy/pkg/Carte"
+)
+
+// Types et sous-types suivis par l'enregistreur, dans l'ordre des colonnes des fichiers
+var (
+	AgentTypes    = []ag.TypeAgent{ag.Sceptic, ag.Neutral, ag.Believer}
+	AgentSubTypes = []ag.SubTypeAgent{ag.None, ag.Pirate, ag.Converter}
+)
+
+// Mesures de la population d'agents à un tick donné
+type Sample struct {
+	Tick          int64                    // tick de la simulation
+	Mean          float64                  // opinion moyenne
+	Variance      float64                  // variance des opinions
+	TypeCounts    map[ag.TypeAgent]int     // nombre d'agents par type
+	SubTypeCounts map[ag.SubTypeAgent]int  // nombre d'agents par sous-type
+	TypeMeans     map[ag.TypeAgent]float64 // opinion moyenne par type (absent si le type n'a aucun agent)
+	GoComputers   int                      // nombre d'ordinateurs sur lesquels Go est installé
+	Discussions   int                      // nombre de discussions en cours
+	Histogram     []int                    // histogramme des opinions sur [0, 1]
+	Indicators    analysis.Indicators      // indicateurs de polarisation (sans l'assortativité, calculée en fin de simulation)
+	Zones         []ZoneSample             // occupation et opinions de chaque zone de la carte
+}
+
+// Mesures des agents présents dans une zone de la carte à un tick donné
+type ZoneSample struct {
+	Name       string               // nom de la zone
+	Kind       string               // type de la zone
+	Agents     int                  // nombre d'agents dans la zone
+	Mean       float64              // opinion moyenne des agents de la zone (0 si elle est vide)
+	TypeCounts map[ag.TypeAgent]int // nombre d'agents de la zone par type
+}
+
+// Enregistreur de séries temporelles: il échantillonne la population tous les "Every" ticks
+type Recorder struct {
+	Every   int      // période d'échantillonnage (en ticks)
+	Bins    int      // nombre de classes de l'histogramme des opinions
+	Samples []Sample // échantillons enregistrés
+}
+
+// Création d'un nouvel enregistreur
+func NewRecorder(every int, bins int) *Recorder {
+	if every < 1 {
+		every = 1
+	}
+	if bins < 1 {
+		bins = 1
+	}
+	return &Recorder{Every: every, Bins: bins, Samples: make([]Sample, 0)}
+}
+
+// Fonction qui échantillonne l'environnement si le tick correspond à la période d'échantillonnage
+func (r *Recorder) Record(tick int64, env *ag.Environnement) {
+	if tick%int64(r.Every) != 0 {
+		return
+	}
+	sample := Measure(tick, env.Ags, env.Objs, r.Bins)
+	if env.Carte != nil {
+		sample.Zones = MeasureZones(env.Ags, env.Carte.Zones)
+	}
+	r.Samples = append(r.Samples, sample)
+}
+
+// Fonction qui calcule l'occupation et l'opinion moyenne de chaque zone, dans l'ordre des zones de la carte
+func MeasureZones(agents []*ag.Agent, zones []carte.Zone) []ZoneSample {
+	samples := make([]ZoneSample, len(zones))
+	index := make(map[*carte.Zone]int, len(zones))
+	for i := range zones {
+		samples[i] = ZoneSample{Name: zones[i].Name, Kind: zones[i].Kind, TypeCounts: make(map[ag.TypeAgent]int)}
+		index[&zones[i]] = i
+	}
+
+	for _, agent := range agents {
+		zone := agent.Zone()
+		i, ok := index[zone]
+		if !ok {
+			continue
+		}
+		samples[i].Agents++
+		samples[i].Mean += agent.Opinion
+		samples[i].TypeCounts[agent.TypeAgt]++
+	}
+	for i := range samples {
+		if samples[i].Agents > 0 {
+			samples[i].Mean /= float64(samples[i].Agents)
+		}
+	}
+	return samples
+}
+
+// Fonction qui calcule les mesures d'une population d'agents et d'objets
+func Measure(tick int64, agents []*ag.Agent, objs []ag.InterfaceObjet, bins int) Sample {
+	s := Sample{
+		Tick:          tick,
+		TypeCounts:    make(map[ag.TypeAgent]int),
+		SubTypeCounts: make(map[ag.SubTypeAgent]int),
+		TypeMeans:     make(map[ag.TypeAgent]float64),
+		Histogram:     make([]int, bins),
+	}
+
+	opinions := make([]float64, len(agents))
+	discussing := 0
+	for i, agent := range agents {
+		opinions[i] = agent.Opinion
+		s.TypeCounts[agent.TypeAgt]++
+		s.SubTypeCounts[agent.SubType]++
+		s.TypeMeans[agent.TypeAgt] += agent.Opinion
+		s.Histogram[Bin(agent.Opinion, bins)]++
+		if agent.CurrentAction == ag.DiscussAct {
+			discussing++
+		}
+	}
+	for t, sum := range s.TypeMeans {
+		s.TypeMeans[t] = sum / float64(s.TypeCounts[t])
+	}
+	// Une discussion implique deux agents
+	s.Discussions = discussing / 2
+	s.Mean, s.Variance = MeanVariance(opinions)
+	s.Indicators = analysis.Compute(opinions)
+
+	for _, obj := range objs {
+		if obj.GetType() == ag.ComputerType && obj.GetProgramm() == ag.GoPgm {
+			s.GoComputers++
+		}
+	}
+	return s
+}
+
+// Fonction qui renvoie la classe de l'histogramme à laquelle appartient une opinion
+func Bin(opinion float64, bins int) int {
+	idx := int(opinion * float64(bins))
+	return max(0, min(bins-1, idx))
+}
+
+// Fonction qui calcule la moyenne et la variance d'une liste de valeurs
+func MeanVariance(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	mean := 0.0
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+
+	variance := 0.0
+	for _, v := range values {
+		variance += math.Pow(v-mean, 2)
+	}
+	return mean, variance / float64(len(values))
+}
+
+// Fonction qui renvoie la série des variances enregistrées
+func (r *Recorder) Variances() []float64 {
+	variances := make([]float64, len(r.Samples))
+	for i, s := range r.Samples {
+		variances[i] = s.Variance
+	}
+	return variances
+}
+
+// Fonction qui renvoie la série des opinions moyennes enregistrées
+func (r *Recorder) Means() []float64 {
+	means := make([]float64, len(r.Samples))
+	for i, s := range r.Samples {
+		means[i] = s.Mean
+	}
+	return means
+}
+
+// Fonction qui renvoie la série des ticks enregistrés
+func (r *Recorder) Ticks() []float64 {
+	ticks := make([]float64, len(r.Samples))
+	for i, s := range r.Samples {
+		ticks[i] = float64(s.Tick)
+	}
+	return ticks
+}
+
+// Fonction qui écrit les séries temporelles dans le dossier donné:
+// "metrics.csv" contient une colonne par mesure et "histogram.csv" une colonne par classe d'opinion
+func (r *Recorder) WriteCSV(dir string) error {
+	header := []string{"tick", "mean", "variance"}
+	for _, t := range AgentTypes {
+		header = append(header, "type_"+string(t))
+	}
+	for _, st := range AgentSubTypes {
+		header = append(header, "subtype_"+string(st))
+	}
+	header = append(header, "go_computers", "discussions", "bimodality", "polarization", "entropy", "clusters")
+
+	rows := make([][]string, 0, len(r.Samples))
+	for _, s := range r.Samples {
+		row := []string{
+			strconv.FormatInt(s.Tick, 10),
+			strconv.FormatFloat(s.Mean, 'f', 6, 64),
+			strconv.FormatFloat(s.Variance, 'f', 6, 64),
+		}
+		for _, t := range AgentTypes {
+			row = append(row, strconv.Itoa(s.TypeCounts[t]))
+		}
+		for _, st := range AgentSubTypes {
+			row = append(row, strconv.Itoa(s.SubTypeCounts[st]))
+		}
+		row = append(row, strconv.Itoa(s.GoComputers), strconv.Itoa(s.Discussions))
+		row = append(row,
+			strconv.FormatFloat(s.Indicators.Bimodality, 'f', 6, 64),
+			strconv.FormatFloat(s.Indicators.Polarization, 'f', 6, 64),
+			strconv.FormatFloat(s.Indicators.Entropy, 'f', 6, 64),
+			strconv.Itoa(s.Indicators.Clusters),
+		)
+		rows = append(rows, row)
+	}
+	if err := writeCSV(filepath.Join(dir, "metrics.csv"), header, rows); err != nil {
+		return err
+	}
+
+	// Histogramme: une colonne par classe, nommée d'après sa borne inférieure
+	header = []string{"tick"}
+	for b := 0; b < r.Bins; b++ {
+		header = append(header, fmt.Sprintf("bin_%.2f", float64(b)/float64(r.Bins)))
+	}
+	rows = rows[:0]
+	for _, s := range r.Samples {
+		row := []string{strconv.FormatInt(s.Tick, 10)}
+		for _, count := range s.Histogram {
+			row = append(row, strconv.Itoa(count))
+		}
+		rows = append(rows, row)
+	}
+	if err := writeCSV(filepath.Join(dir, "histogram.csv"), header, rows); err != nil {
+		return err
+	}
+	return r.writeZonesCSV(dir)
+}
+
+// Fonction qui écrit "zones.csv", une ligne par zone et par échantillon, si la carte contient des zones
+func (r *Recorder) writeZonesCSV(dir string) error {
+	header := []string{"tick", "zone", "kind", "agents", "mean"}
+	for _, t := range AgentTypes {
+		header = append(header, "type_"+string(t))
+	}
+
+	rows := make([][]string, 0)
+	for _, s := range r.Samples {
+		for _, zone := range s.Zones {
+			row := []string{
+				strconv.FormatInt(s.Tick, 10),
+				zone.Name,
+				zone.Kind,
+				strconv.Itoa(zone.Agents),
+				strconv.FormatFloat(zone.Mean, 'f', 6, 64),
+			}
+			for _, t := range AgentTypes {
+				row = append(row, strconv.Itoa(zone.TypeCounts[t]))
+			}
+			rows = append(rows, row)
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return writeCSV(filepath.Join(dir, "zones.csv"), header, rows)
+}
+
+// Fonction qui écrit un fichier CSV avec un en-tête
+func writeCSV(path string, header []string, rows [][]string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	if err := w.WriteAll(rows); err != nil {
+		return err
+	}
+	return file.Close()
+}
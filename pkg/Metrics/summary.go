@@ -0,0 +1,132 @@
+package metrics
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	ag "github.com/Tmegaa/The-Gophecy/pkg/Agent"
+	analysis "github.com/Tmegaa/The-Gophecy/pkg/Analysis"
+)
+
+// Résumé d'une simulation: une ligne par exécution dans "summary.csv" pour comparer plusieurs exécutions
+type Summary struct {
+	Date             time.Time            // date de fin de la simulation
+	Ticks            int64                // nombre de ticks simulés
+	Agents           int                  // nombre d'agents
+	FinalMean        float64              // opinion moyenne finale
+	FinalVariance    float64              // variance finale des opinions
+	TypeCounts       map[ag.TypeAgent]int // nombre final d'agents par type
+	Indicators       analysis.Indicators  // indicateurs finaux, assortativité comprise
+	ConsensusTick    int64                // tick à partir duquel le consensus est atteint
+	ConsensusReached bool                 // indique si le consensus a été atteint
+}
+
+// Fonction qui calcule tous les indicateurs d'une population d'agents, assortativité des relations comprise
+func PopulationIndicators(agents []*ag.Agent) analysis.Indicators {
+	opinions := make([]float64, len(agents))
+	byId := make(map[ag.IdAgent]float64, len(agents))
+	relations := make(map[ag.IdAgent]map[ag.IdAgent]float64, len(agents))
+	for i, agent := range agents {
+		opinions[i] = agent.Opinion
+		byId[agent.Id] = agent.Opinion
+		relations[agent.Id] = agent.Relation
+	}
+
+	indicators := analysis.Compute(opinions)
+	indicators.Assortativity = analysis.Assortativity(byId, relations)
+	return indicators
+}
+
+// Fonction qui construit le résumé d'une simulation à partir de la population finale et des séries enregistrées
+func (r *Recorder) Summarize(tick int64, agents []*ag.Agent) Summary {
+	final := Measure(tick, agents, nil, r.Bins)
+	summary := Summary{
+		Date:          time.Now(),
+		Ticks:         tick,
+		Agents:        len(agents),
+		FinalMean:     final.Mean,
+		FinalVariance: final.Variance,
+		TypeCounts:    final.TypeCounts,
+		Indicators:    PopulationIndicators(agents),
+	}
+	ticks := make([]int64, len(r.Samples))
+	for i, sample := range r.Samples {
+		ticks[i] = sample.Tick
+	}
+	summary.ConsensusTick, summary.ConsensusReached = analysis.TimeToConsensus(ticks, r.Variances(), analysis.DefaultConsensusSigma)
+	return summary
+}
+
+// Fonction qui affiche le résumé des indicateurs de polarisation
+func (s Summary) Print() {
+	fmt.Println("\nIndicateurs de polarisation:")
+	fmt.Printf("- Coefficient de bimodalité : %.3f\n", s.Indicators.Bimodality)
+	fmt.Printf("- Polarisation d'Esteban–Ray : %.4f\n", s.Indicators.Polarization)
+	fmt.Printf("- Entropie des opinions : %.3f\n", s.Indicators.Entropy)
+	fmt.Printf("- Nombre de groupes d'opinion : %d\n", s.Indicators.Clusters)
+	fmt.Printf("- Assortativité des relations : %.3f\n", s.Indicators.Assortativity)
+	if s.ConsensusReached {
+		fmt.Printf("- Consensus atteint au tick %d\n", s.ConsensusTick)
+	} else {
+		fmt.Println("- Consensus non atteint")
+	}
+}
+
+// Fonction qui rajoute le résumé à la fin du fichier "summary.csv" du dossier donné (l'en-tête est écrit à la création)
+func (s Summary) Append(dir string) error {
+	path := filepath.Join(dir, "summary.csv")
+	_, statErr := os.Stat(path)
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	if os.IsNotExist(statErr) {
+		header := []string{"date", "ticks", "agents", "mean", "variance"}
+		for _, t := range AgentTypes {
+			header = append(header, "type_"+string(t))
+		}
+		header = append(header, "bimodality", "polarization", "entropy", "clusters", "assortativity", "consensus_tick")
+		if err := w.Write(header); err != nil {
+			return err
+		}
+	}
+
+	consensus := ""
+	if s.ConsensusReached {
+		consensus = strconv.FormatInt(s.ConsensusTick, 10)
+	}
+	row := []string{
+		s.Date.Format(time.RFC3339),
+		strconv.FormatInt(s.Ticks, 10),
+		strconv.Itoa(s.Agents),
+		strconv.FormatFloat(s.FinalMean, 'f', 6, 64),
+		strconv.FormatFloat(s.FinalVariance, 'f', 6, 64),
+	}
+	for _, t := range AgentTypes {
+		row = append(row, strconv.Itoa(s.TypeCounts[t]))
+	}
+	row = append(row,
+		strconv.FormatFloat(s.Indicators.Bimodality, 'f', 6, 64),
+		strconv.FormatFloat(s.Indicators.Polarization, 'f', 6, 64),
+		strconv.FormatFloat(s.Indicators.Entropy, 'f', 6, 64),
+		strconv.Itoa(s.Indicators.Clusters),
+		strconv.FormatFloat(s.Indicators.Assortativity, 'f', 6, 64),
+		consensus,
+	)
+	if err := w.Write(row); err != nil {
+		return err
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,296 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	ag "github.com/Tmegaa/The-Gophecy/pkg/Agent"
+	events "github.com/Tmegaa/The-Gophecy/pkg/Events"
+	influence "github.com/Tmegaa/The-Gophecy/pkg/Influence"
+	metrics "github.com/Tmegaa/The-Gophecy/pkg/Metrics"
+
+	"github.com/wcharczuk/go-chart/v2"
+)
+
+// Nom du rapport écrit dans le dossier des résultats
+const FileName = "report.html"
+
+// Paramètre de la configuration affiché dans le rapport
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Agent influent: score d'influence accompagné du type final de l'agent
+type Influencer struct {
+	influence.Score
+	Type    ag.TypeAgent
+	SubType ag.SubTypeAgent
+}
+
+// Données nécessaires à la création du rapport d'une simulation
+type Data struct {
+	Title       string                 // titre du rapport
+	Seed        int64                  // graine du générateur aléatoire
+	TPS         int                    // nombre de ticks par seconde simulée
+	Config      []Field                // paramètres de la simulation
+	Summary     metrics.Summary        // résumé de fin de simulation
+	Recorder    *metrics.Recorder      // séries temporelles enregistrées
+	Influencers []Influencer           // agents les plus influents
+	Programs    []events.Event         // changements de programme des ordinateurs
+	Tactics     []influence.Tactic     // influence cumulée par tactique
+	Converters  []Influencer           // convertisseurs les plus efficaces
+	Pirates     []Influencer           // pirates les plus efficaces
+	Conversions []influence.Conversion // changements de type attribués à leur source
+	Graph       string                 // graphe des conversions au format DOT
+}
+
+// Journal des changements de programme des ordinateurs: il est rattaché au bus d'évènements
+type ProgramLog struct {
+	mutex   sync.Mutex
+	changes []events.Event
+}
+
+// Création d'un nouveau journal des changements de programme
+func NewProgramLog() *ProgramLog {
+	return &ProgramLog{changes: make([]events.Event, 0)}
+}
+
+// Fonction qui conserve les changements de programme
+func (p *ProgramLog) Write(e events.Event) error {
+	if e.Kind != events.ProgramChange {
+		return nil
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.changes = append(p.changes, e)
+	return nil
+}
+
+// Fonction qui ne fait rien: le journal est conservé en mémoire
+func (p *ProgramLog) Close() error { return nil }
+
+// Fonction qui renvoie une copie des changements de programme, du plus ancien au plus récent
+func (p *ProgramLog) Changes() []events.Event {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return append([]events.Event(nil), p.changes...)
+}
+
+// Ligne du tableau de répartition des types
+type typeRow struct {
+	Type  ag.TypeAgent
+	Count int
+	Share float64
+	Color string
+}
+
+// Ligne du tableau des indicateurs
+type indicatorRow struct {
+	Name  string
+	Value string
+}
+
+// Contenu du modèle HTML
+type page struct {
+	Data
+	Generated  string
+	Duration   string
+	Types      []typeRow
+	Indicators []indicatorRow
+	Charts     map[string]template.HTML
+}
+
+// Fonction qui écrit le rapport HTML autonome (graphiques SVG inclus) à l'emplacement donné
+func Write(path string, data Data) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("échec de la création du rapport: %v", err)
+	}
+	defer file.Close()
+
+	if err := Render(file, data); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// Fonction qui produit le rapport HTML d'une simulation
+func Render(w io.Writer, data Data) error {
+	summary := data.Summary
+	p := page{
+		Data:      data,
+		Generated: summary.Date.Format("02/01/2006 15:04:05"),
+		Charts:    make(map[string]template.HTML),
+	}
+	if data.TPS > 0 {
+		p.Duration = (time.Duration(summary.Ticks) * time.Second / time.Duration(data.TPS)).String()
+	}
+
+	for _, t := range metrics.AgentTypes {
+		row := typeRow{Type: t, Count: summary.TypeCounts[t], Color: hexColor(t)}
+		if summary.Agents > 0 {
+			row.Share = 100 * float64(row.Count) / float64(summary.Agents)
+		}
+		p.Types = append(p.Types, row)
+	}
+
+	consensus := "non atteint"
+	if summary.ConsensusReached {
+		consensus = fmt.Sprintf("tick %d", summary.ConsensusTick)
+	}
+	p.Indicators = []indicatorRow{
+		{"Coefficient de bimodalité", fmt.Sprintf("%.3f", summary.Indicators.Bimodality)},
+		{"Polarisation d'Esteban–Ray", fmt.Sprintf("%.4f", summary.Indicators.Polarization)},
+		{"Entropie des opinions", fmt.Sprintf("%.3f", summary.Indicators.Entropy)},
+		{"Nombre de groupes d'opinion", fmt.Sprintf("%d", summary.Indicators.Clusters)},
+		{"Assortativité des relations", fmt.Sprintf("%.3f", summary.Indicators.Assortativity)},
+		{"Consensus", consensus},
+	}
+
+	if err := p.renderCharts(); err != nil {
+		return err
+	}
+	return reportTemplate.Execute(w, p)
+}
+
+// Fonction qui trace les graphiques du rapport au format SVG
+func (p *page) renderCharts() error {
+	r := p.Recorder
+	if r == nil || len(r.Samples) == 0 {
+		return nil
+	}
+
+	charts := map[string]func(io.Writer) error{
+		"start": func(w io.Writer) error {
+			return r.RenderHistogram(chart.SVG, w, r.Samples[0], fmt.Sprintf("Tick %d", r.Samples[0].Tick))
+		},
+		"end": func(w io.Writer) error {
+			last := r.Samples[len(r.Samples)-1]
+			return r.RenderHistogram(chart.SVG, w, last, fmt.Sprintf("Tick %d", last.Tick))
+		},
+	}
+	// Il faut au moins deux échantillons pour tracer une courbe
+	if len(r.Samples) >= 2 {
+		charts["opinions"] = func(w io.Writer) error { return r.RenderTypeOpinions(chart.SVG, w) }
+		charts["shares"] = func(w io.Writer) error { return r.RenderTypeShares(chart.SVG, w) }
+		charts["indicators"] = func(w io.Writer) error { return r.RenderIndicators(chart.SVG, w) }
+		charts["computers"] = func(w io.Writer) error { return r.RenderGoComputers(chart.SVG, w) }
+	}
+
+	for name, render := range charts {
+		var buf bytes.Buffer
+		if err := render(&buf); err != nil {
+			return fmt.Errorf("échec du rendu du graphique %s: %v", name, err)
+		}
+		p.Charts[name] = template.HTML(buf.String())
+	}
+	return nil
+}
+
+// Fonction qui renvoie la couleur d'un type d'agent au format CSS
+func hexColor(t ag.TypeAgent) string {
+	c := metrics.TypeColors[t]
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="fr">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em auto; max-width: 1100px; color: #222; }
+h1 { color: #393d7d; }
+h2 { border-bottom: 2px solid #393d7d; padding-bottom: 0.2em; margin-top: 2em; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.8em; text-align: left; }
+th { background: #eef; }
+.bar { display: inline-block; height: 0.9em; vertical-align: middle; }
+.charts { display: flex; flex-wrap: wrap; gap: 1em; }
+.charts figure { margin: 0; }
+figcaption { text-align: center; font-style: italic; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>Rapport généré le {{.Generated}} — durée simulée {{with .Duration}}{{.}} {{end}}({{.Summary.Ticks}} ticks), graine {{.Seed}}.</p>
+
+<h2>Configuration</h2>
+<table>
+{{range .Config}}<tr><th>{{.Name}}</th><td>{{.Value}}</td></tr>
+{{end}}</table>
+
+<h2>Répartition finale des types</h2>
+<p>{{.Summary.Agents}} agents, opinion moyenne finale {{printf "%.3f" .Summary.FinalMean}} (variance {{printf "%.4f" .Summary.FinalVariance}}).</p>
+<table>
+<tr><th>Type</th><th>Agents</th><th>Part</th></tr>
+{{range .Types}}<tr><td>{{.Type}}</td><td>{{.Count}}</td><td><span class="bar" style="width: {{printf "%.0f" .Share}}px; background: {{.Color}}"></span> {{printf "%.1f" .Share}} %</td></tr>
+{{end}}</table>
+{{with index .Charts "shares"}}<figure>{{.}}<figcaption>Part de chaque type au cours du temps</figcaption></figure>{{end}}
+
+<h2>Trajectoires d'opinion par type</h2>
+{{with index .Charts "opinions"}}<figure>{{.}}<figcaption>Opinion moyenne de chaque type au cours du temps</figcaption></figure>{{else}}<p>Pas assez d'échantillons pour tracer les trajectoires.</p>{{end}}
+
+<h2>Histogrammes des opinions</h2>
+<div class="charts">
+{{with index .Charts "start"}}<figure>{{.}}<figcaption>Début de la simulation</figcaption></figure>{{end}}
+{{with index .Charts "end"}}<figure>{{.}}<figcaption>Fin de la simulation</figcaption></figure>{{end}}
+</div>
+
+<h2>Indicateurs de polarisation</h2>
+<table>
+{{range .Indicators}}<tr><th>{{.Name}}</th><td>{{.Value}}</td></tr>
+{{end}}</table>
+{{with index .Charts "indicators"}}<figure>{{.}}<figcaption>Indicateurs au cours du temps</figcaption></figure>{{end}}
+
+<h2>Agents les plus influents</h2>
+{{if .Influencers}}<table>
+<tr><th>Agent</th><th>Type final</th><th>Sous-type</th><th>Influence totale</th><th>Influence nette</th><th>Opinions modifiées</th><th>Conversions</th></tr>
+{{range .Influencers}}<tr><td>{{.ID}}</td><td>{{.Type}}</td><td>{{.SubType}}</td><td>{{printf "%.3f" .Total}}</td><td>{{printf "%+.3f" .Net}}</td><td>{{.Changes}}</td><td>{{.Conversions}}</td></tr>
+{{end}}</table>{{else}}<p>Aucune discussion n'a modifié d'opinion.</p>{{end}}
+
+<h2>Tactiques d'endoctrinement</h2>
+<p>Influence directe cumulée selon le sous-type de l'agent au moment de la discussion, ou selon l'objet utilisé. Une conversion est attribuée à la source de la variation d'opinion qui a fait changer l'agent de type.</p>
+<table>
+<tr><th>Tactique</th><th>Sources</th><th>Influence totale</th><th>Influence nette</th><th>Conversions</th><th>Vers Go</th></tr>
+{{range .Tactics}}<tr><td>{{.Name}}</td><td>{{.Sources}}</td><td>{{printf "%.3f" .Total}}</td><td>{{printf "%+.3f" .Net}}</td><td>{{.Conversions}}</td><td>{{.TowardGo}}</td></tr>
+{{end}}</table>
+
+<h2>Convertisseurs les plus efficaces</h2>
+{{template "ranking" .Converters}}
+
+<h2>Pirates les plus efficaces</h2>
+<p>L'influence indirecte est celle des ordinateurs dont l'agent a changé le programme.</p>
+{{template "ranking" .Pirates}}
+
+<h2>Conversions</h2>
+{{if .Conversions}}<details>
+<summary>{{len .Conversions}} changements de type</summary>
+<table>
+<tr><th>Tick</th><th>Agent</th><th>Ancien type</th><th>Nouveau type</th><th>Source</th><th>Nature</th><th>Sous-type de la source</th><th>Programme installé par</th></tr>
+{{range .Conversions}}<tr><td>{{.Tick}}</td><td>{{.Agent}}</td><td>{{.From}}</td><td>{{.To}}</td><td>{{.Source.ID}}</td><td>{{.Source.Kind}}</td><td>{{.SourceSubType}}</td><td>{{.Installer}}</td></tr>
+{{end}}</table>
+</details>
+<details>
+<summary>Graphe des conversions (Graphviz)</summary>
+<pre>{{.Graph}}</pre>
+</details>{{else}}<p>Aucun agent n'a changé de type.</p>{{end}}
+
+<h2>Historique des programmes des ordinateurs</h2>
+{{with index .Charts "computers"}}<figure>{{.}}<figcaption>Nombre d'ordinateurs sous Go</figcaption></figure>{{end}}
+{{if .Programs}}<table>
+<tr><th>Tick</th><th>Ordinateur</th><th>Agent</th><th>Ancien programme</th><th>Nouveau programme</th></tr>
+{{range .Programs}}<tr><td>{{.Tick}}</td><td>{{.Object}}</td><td>{{.Agent}}</td><td>{{.From}}</td><td>{{.To}}</td></tr>
+{{end}}</table>{{else}}<p>Aucun programme n'a été changé.</p>{{end}}
+</body>
+</html>
+{{define "ranking"}}{{if .}}<table>
+<tr><th>Agent</th><th>Type final</th><th>Conversions</th><th>dont vers Go</th><th>Conversions indirectes</th><th>Influence directe</th><th>Influence indirecte</th></tr>
+{{range .}}<tr><td>{{.ID}}</td><td>{{.Type}}</td><td>{{.Conversions}}</td><td>{{.TowardGo}}</td><td>{{.IndirectConversions}}</td><td>{{printf "%.3f" .Total}}</td><td>{{printf "%.3f" .Indirect}}</td></tr>
+{{end}}</table>{{else}}<p>Aucun agent de ce sous-type n'a exercé d'influence.</p>{{end}}{{end}}`))
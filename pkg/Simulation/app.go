@@ -0,0 +1,323 @@
+package simulation
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	metrics "github.com/Tmegaa/The-Gophecy/pkg/Metrics"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Dimensions de l'écran de fin
+const (
+	EndScreenWidth       = 960
+	EndScreenHeight      = 440
+	EndScreenBodyHeight  = 260 // hauteur des deux colonnes du résumé
+	EndScreenInfluencers = 5   // nombre d'agents influents affichés
+)
+
+// États de l'application graphique
+type appState int
+
+const (
+	MenuState appState = iota
+	RunningState
+	EndedState
+)
+
+// Application graphique: écran de démarrage, simulation puis écran de fin
+type App struct {
+	state   appState
+	menu    *startMenu
+	sim     *Simulation
+	config  SimulationConfig // configuration de la dernière simulation lancée depuis l'écran de démarrage
+	runs    int              // nombre de lancements de cette configuration
+	message string           // erreur survenue à la fin de la simulation
+	buttons [3]image.Rectangle
+	width   int
+	height  int
+}
+
+// Création de l'application à partir d'une configuration initiale (options de la ligne de commande)
+func NewApp(config SimulationConfig) *App {
+	return &App{state: MenuState, menu: newStartMenu(config), width: WindowWidth, height: WindowHeight}
+}
+
+// Fonction qui ouvre la fenêtre et fait tourner l'application
+func (a *App) Run() error {
+	initializeWindow()
+	err := ebiten.RunGame(a)
+
+	// La fenêtre a été fermée pendant une simulation: on enregistre tout de même ses résultats
+	if a.state == RunningState {
+		if finishErr := a.sim.finish(); finishErr != nil {
+			return finishErr
+		}
+	}
+	if err == ebiten.Termination {
+		return nil
+	}
+	return err
+}
+
+// Fonction qui lance une simulation avec la configuration donnée
+func (a *App) start(config SimulationConfig) {
+	simulation, err := NewSimulation(config)
+	if err != nil {
+		a.menu.message = err.Error()
+		a.state = MenuState
+		return
+	}
+	a.sim = simulation
+	a.message = ""
+	a.sim.startAgents()
+	a.state = RunningState
+}
+
+// Fonction de mise à jour de l'application
+func (a *App) Update() error {
+	switch a.state {
+	case MenuState:
+		a.menu.update()
+		if a.menu.quit {
+			return ebiten.Termination
+		}
+		if a.menu.launch {
+			a.menu.launch = false
+			config, err := a.menu.finalConfig()
+			if err != nil {
+				a.menu.message = err.Error()
+				return nil
+			}
+			a.start(config)
+			if a.state == RunningState {
+				// La configuration de la simulation contient la graine tirée: "Recommencer" relance la même configuration
+				// avec la même graine (les agents tournant en parallèle, la simulation obtenue peut différer)
+				a.config, a.runs = a.sim.config, 1
+			}
+		}
+
+	case RunningState:
+		err := a.sim.Update()
+		if err != ebiten.Termination {
+			return err
+		}
+		if err := a.sim.finish(); err != nil {
+			log.Printf("Failed to save results: %v", err)
+			a.message = err.Error()
+		}
+		a.state = EndedState
+
+	case EndedState:
+		a.updateEndScreen()
+		if a.menu.quit {
+			return ebiten.Termination
+		}
+	}
+	return nil
+}
+
+// Fonction qui traite les actions de l'utilisateur sur l'écran de fin
+func (a *App) updateEndScreen() {
+	restart := inpututil.IsKeyJustPressed(ebiten.KeyR)
+	configure := inpututil.IsKeyJustPressed(ebiten.KeyM)
+	quit := inpututil.IsKeyJustPressed(ebiten.KeyEscape) || inpututil.IsKeyJustPressed(ebiten.KeyQ)
+
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		cursor := image.Pt(ebiten.CursorPosition())
+		restart = restart || cursor.In(a.buttons[0])
+		configure = configure || cursor.In(a.buttons[1])
+		quit = quit || cursor.In(a.buttons[2])
+	}
+
+	switch {
+	case restart:
+		a.runs++
+		config, err := a.config.runConfig(a.runs)
+		if err != nil {
+			a.message = err.Error()
+			return
+		}
+		a.start(config)
+	case configure:
+		a.menu.refreshFiles()
+		a.state = MenuState
+	case quit:
+		a.menu.quit = true
+	}
+}
+
+// Fonction qui renvoie la configuration du n-ième lancement d'une même simulation: ses résultats, son journal d'évènements
+// et sa trace sont écrits dans le sous-dossier run_<graine>_<n> du dossier de résultats, sans écraser ceux des lancements précédents
+func (config SimulationConfig) runConfig(n int) (SimulationConfig, error) {
+	dir := filepath.Join(config.OutputDir, fmt.Sprintf("run_%d_%d", config.Seed, n))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return config, fmt.Errorf("échec de la création du dossier %s: %v", dir, err)
+	}
+	config.OutputDir = dir
+	if config.EventLogPath != "" {
+		config.EventLogPath = filepath.Join(dir, filepath.Base(config.EventLogPath))
+	}
+	if config.TracePath != "" {
+		config.TracePath = filepath.Join(dir, filepath.Base(config.TracePath))
+	}
+	return config, nil
+}
+
+// Fonction d'affichage de l'application
+func (a *App) Draw(screen *ebiten.Image) {
+	switch a.state {
+	case MenuState:
+		a.menu.draw(screen)
+	case RunningState:
+		a.sim.Draw(screen)
+	case EndedState:
+		a.drawEndScreen(screen)
+	}
+}
+
+// Fonction d'affichage de l'écran de fin: résumé de la simulation et choix de la suite
+func (a *App) drawEndScreen(screen *ebiten.Image) {
+	screen.Fill(color.RGBA{57, 61, 125, 255})
+
+	x := (screen.Bounds().Dx() - EndScreenWidth) / 2
+	y := (screen.Bounds().Dy() - EndScreenHeight) / 2
+	vector.DrawFilledRect(screen, float32(x), float32(y), EndScreenWidth, EndScreenHeight, color.RGBA{0, 0, 0, 180}, false)
+	x += PanelPadding
+	y += PanelPadding
+
+	ebitenutil.DebugPrintAt(screen, "Simulation terminée", x, y)
+	top := y + 2*MenuRowHeight
+	if summary := a.sim.summary; summary != nil {
+		a.drawSummary(screen, *summary, x, top)
+		a.drawEndCharts(screen, x+EndScreenWidth/2, top)
+	}
+
+	// Emplacement des résultats et erreur éventuelle
+	y = top + EndScreenBodyHeight
+	lines := []string{fmt.Sprintf("Graine: %d", a.sim.config.Seed), fmt.Sprintf("Résultats enregistrés dans %s", a.sim.outputDir)}
+	if a.sim.reportPath != "" {
+		lines = append(lines, fmt.Sprintf("Rapport: %s", a.sim.reportPath))
+	}
+	if a.message != "" {
+		lines = append(lines, "Erreur: "+a.message)
+	}
+	for _, line := range lines {
+		ebitenutil.DebugPrintAt(screen, line, x, y)
+		y += PanelLineHeight
+	}
+
+	y += MenuRowHeight
+	labels := []string{"[R] Recommencer", "[M] Configurer", "[Q] Quitter"}
+	for i, label := range labels {
+		a.buttons[i] = image.Rect(x+i*(MenuButtonWidth+10), y, x+i*(MenuButtonWidth+10)+MenuButtonWidth, y+ControlButtonHeight)
+		drawMenuButton(screen, a.buttons[i], label)
+	}
+}
+
+// Fonction d'affichage de la colonne de gauche de l'écran de fin: chiffres clés, répartition des types et indicateurs
+func (a *App) drawSummary(screen *ebiten.Image, summary metrics.Summary, x, y int) {
+	lines := []string{
+		fmt.Sprintf("Durée simulée: %s (%d ticks)", time.Duration(summary.Ticks)*time.Second/TicksPerSecond, summary.Ticks),
+		fmt.Sprintf("Nombre d'agents: %d", summary.Agents),
+		fmt.Sprintf("Opinion moyenne finale: %.3f (variance %.4f)", summary.FinalMean, summary.FinalVariance),
+		"",
+		"Répartition finale des types:",
+	}
+	for _, line := range lines {
+		ebitenutil.DebugPrintAt(screen, line, x, y)
+		y += PanelLineHeight
+	}
+
+	// Une barre par type, proportionnelle à sa part de la population
+	barWidth := EndScreenWidth/2 - 2*PanelPadding - 140
+	for _, t := range metrics.AgentTypes {
+		count := summary.TypeCounts[t]
+		share := 0.0
+		if summary.Agents > 0 {
+			share = float64(count) / float64(summary.Agents)
+		}
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("%-9s %4d", t, count), x, y)
+		vector.DrawFilledRect(screen, float32(x+90), float32(y+3), float32(barWidth), 10, color.RGBA{80, 80, 80, 255}, false)
+		vector.DrawFilledRect(screen, float32(x+90), float32(y+3), float32(float64(barWidth)*share), 10, typeColor(t), false)
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("%5.1f %%", 100*share), x+96+barWidth, y)
+		y += PanelLineHeight
+	}
+
+	consensus := "Consensus non atteint"
+	if summary.ConsensusReached {
+		consensus = fmt.Sprintf("Consensus atteint au tick %d", summary.ConsensusTick)
+	}
+	lines = []string{
+		"",
+		"Indicateurs de polarisation:",
+		fmt.Sprintf("  Bimodalité: %.3f", summary.Indicators.Bimodality),
+		fmt.Sprintf("  Polarisation: %.4f", summary.Indicators.Polarization),
+		fmt.Sprintf("  Entropie: %.3f", summary.Indicators.Entropy),
+		fmt.Sprintf("  Groupes d'opinion: %d", summary.Indicators.Clusters),
+		fmt.Sprintf("  Assortativité: %.3f", summary.Indicators.Assortativity),
+		"  " + consensus,
+	}
+	for _, line := range lines {
+		ebitenutil.DebugPrintAt(screen, line, x, y)
+		y += PanelLineHeight
+	}
+}
+
+// Fonction d'affichage de la colonne de droite de l'écran de fin: histogrammes de début et de fin et agents influents
+func (a *App) drawEndCharts(screen *ebiten.Image, x, y int) {
+	width := (EndScreenWidth/2 - 3*PanelPadding) / 2
+	if samples := a.sim.metrics.Samples; len(samples) > 0 {
+		first, last := samples[0], samples[len(samples)-1]
+		highest := 1
+		for _, count := range append(append([]int(nil), first.Histogram...), last.Histogram...) {
+			highest = max(highest, count)
+		}
+		ebitenutil.DebugPrintAt(screen, "Opinions au début", x, y)
+		ebitenutil.DebugPrintAt(screen, "Opinions à la fin", x+width+PanelPadding, y)
+		y += PanelLineHeight
+		drawEndHistogram(screen, x, y, width, first.Histogram, highest)
+		drawEndHistogram(screen, x+width+PanelPadding, y, width, last.Histogram, highest)
+		y += LiveChartHeight + MenuRowHeight
+	}
+
+	ebitenutil.DebugPrintAt(screen, "Agents les plus influents:", x, y)
+	y += PanelLineHeight
+	influencers := a.sim.topInfluencers(EndScreenInfluencers)
+	if len(influencers) == 0 {
+		ebitenutil.DebugPrintAt(screen, "  aucune opinion modifiée", x, y)
+	}
+	for i, influencer := range influencers {
+		line := fmt.Sprintf("%2d. %-10s %-9s %.3f (%d conv.)", i+1, influencer.ID, influencer.Type, influencer.Total, influencer.Conversions)
+		ebitenutil.DebugPrintAt(screen, line, x, y)
+		y += PanelLineHeight
+	}
+}
+
+// Fonction d'affichage d'un histogramme des opinions sur l'écran de fin
+func drawEndHistogram(screen *ebiten.Image, x, y, width int, histogram []int, highest int) {
+	drawChartFrame(screen, x, y, width)
+	barWidth := float32(width) / float32(len(histogram))
+	for i, count := range histogram {
+		barHeight := float32(LiveChartHeight) * float32(count) / float32(highest)
+		opinion := (float64(i) + 0.5) / float64(len(histogram))
+		vector.DrawFilledRect(screen, float32(x)+float32(i)*barWidth+1, float32(y+LiveChartHeight)-barHeight, barWidth-2, barHeight, opinionColor(opinion), false)
+	}
+}
+
+// Fonction qui retourne les dimentions de la fenêtre d'affichage
+func (a *App) Layout(outsideWidth, outsideHeight int) (int, int) {
+	if a.state == RunningState {
+		return a.sim.Layout(outsideWidth, outsideHeight)
+	}
+	return outsideWidth, outsideHeight
+}
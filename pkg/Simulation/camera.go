@@ -0,0 +1,129 @@
+package simulation
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Constantes de la caméra
+const (
+	MinZoom    = 0.25 // zoom minimal (dézoom)
+	MaxZoom    = 8    // zoom maximal
+	ZoomFactor = 1.1  // facteur de zoom pour un cran de molette
+)
+
+// Caméra qui transforme les coordonnées du monde (pixels de la carte) en coordonnées de l'écran
+type camera struct {
+	X, Y          float64 // coordonnées dans le monde du coin supérieur gauche de l'écran
+	Zoom          float64 // facteur d'agrandissement
+	Follow        bool    // la caméra suit l'agent sélectionné
+	width, height int     // dimensions de l'écran
+	dragging      bool
+	dragX, dragY  int // dernière position du curseur pendant un déplacement
+}
+
+// Création d'une caméra qui affiche le monde sans déplacement ni zoom
+func newCamera() camera {
+	return camera{Zoom: 1, width: WindowWidth, height: WindowHeight}
+}
+
+// Fonction qui renvoie la transformation du monde vers l'écran
+func (c *camera) GeoM() ebiten.GeoM {
+	var geoM ebiten.GeoM
+	geoM.Translate(-c.X, -c.Y)
+	geoM.Scale(c.Zoom, c.Zoom)
+	return geoM
+}
+
+// Fonction qui convertit une position de l'écran en position dans le monde
+func (c *camera) ScreenToWorld(x, y int) (float64, float64) {
+	return c.X + float64(x)/c.Zoom, c.Y + float64(y)/c.Zoom
+}
+
+// Fonction qui convertit une position du monde en position à l'écran
+func (c *camera) WorldToScreen(x, y float64) (float64, float64) {
+	return (x - c.X) * c.Zoom, (y - c.Y) * c.Zoom
+}
+
+// Fonction qui centre la caméra sur une position du monde
+func (c *camera) CenterOn(x, y float64) {
+	c.X = x - float64(c.width)/(2*c.Zoom)
+	c.Y = y - float64(c.height)/(2*c.Zoom)
+}
+
+// Fonction qui change le zoom en gardant fixe le point du monde situé sous la position (x, y) de l'écran
+func (c *camera) ZoomAt(x, y int, factor float64) {
+	worldX, worldY := c.ScreenToWorld(x, y)
+	c.Zoom = max(MinZoom, min(MaxZoom, c.Zoom*factor))
+	c.X = worldX - float64(x)/c.Zoom
+	c.Y = worldY - float64(y)/c.Zoom
+}
+
+// Fonction qui réinitialise la caméra
+func (c *camera) Reset() {
+	c.X, c.Y, c.Zoom, c.Follow = 0, 0, 1, false
+}
+
+// Fonction qui traite le déplacement (clic droit ou molette maintenus), le zoom (molette) et les raccourcis de la caméra.
+// Le zoom n'est appliqué que si wheel est vrai, afin de laisser la molette aux autres éléments de l'interface.
+func (c *camera) handleInput(wheel bool) {
+	x, y := ebiten.CursorPosition()
+
+	// Déplacement en faisant glisser la souris
+	if ebiten.IsMouseButtonPressed(ebiten.MouseButtonRight) || ebiten.IsMouseButtonPressed(ebiten.MouseButtonMiddle) {
+		if c.dragging {
+			c.X -= float64(x-c.dragX) / c.Zoom
+			c.Y -= float64(y-c.dragY) / c.Zoom
+			if x != c.dragX || y != c.dragY {
+				c.Follow = false
+			}
+		}
+		c.dragging = true
+		c.dragX, c.dragY = x, y
+	} else {
+		c.dragging = false
+	}
+
+	// Zoom à la molette, autour du curseur
+	if wheel {
+		if _, wheelY := ebiten.Wheel(); wheelY != 0 {
+			c.ZoomAt(x, y, math.Pow(ZoomFactor, wheelY))
+		}
+	}
+
+	// Raccourcis: suivi de l'agent sélectionné et réinitialisation
+	if inpututil.IsKeyJustPressed(ebiten.KeyF) {
+		c.Follow = !c.Follow
+	}
+	if inpututil.IsKeyJustPressed(ebiten.Key0) {
+		c.Reset()
+	}
+}
+
+// Fonction qui met à jour les dimensions de l'écran et renvoie les dimensions logiques de la fenêtre
+func (c *camera) layout(outsideWidth, outsideHeight int) (int, int) {
+	c.width, c.height = outsideWidth, outsideHeight
+	return outsideWidth, outsideHeight
+}
+
+// Fonction qui dessine le monde dans une image hors écran puis l'affiche à l'écran à travers la caméra.
+// L'image couvre exactement l'étendue de la carte, celle qui borne aussi les déplacements des agents:
+// elle est allouée dans les coordonnées du monde, puis replacée à son origine avant la transformation de la caméra
+func (sim *Simulation) drawWorld(screen *ebiten.Image, draw func(world *ebiten.Image)) {
+	bounds := sim.carte.Bounds
+	if sim.world == nil || sim.world.Bounds() != bounds {
+		sim.world = ebiten.NewImageWithOptions(bounds, nil)
+	}
+	sim.world.Clear()
+	draw(sim.world)
+
+	opts := ebiten.DrawImageOptions{}
+	opts.GeoM.Translate(float64(bounds.Min.X), float64(bounds.Min.Y))
+	opts.GeoM.Concat(sim.camera.GeoM())
+	if sim.camera.Zoom < 1 {
+		opts.Filter = ebiten.FilterLinear
+	}
+	screen.DrawImage(sim.world, &opts)
+}
@@ -0,0 +1,139 @@
+package simulation
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"path/filepath"
+	"time"
+
+	capture "github.com/Tmegaa/The-Gophecy/pkg/Capture"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Période de capture par défaut (en ticks)
+const CaptureEvery = 30
+
+// Capture de l'écran: captures d'écran ponctuelles et enregistrement d'images à intervalle régulier
+type screenCapture struct {
+	format     capture.Format    // format de l'enregistrement
+	every      int               // période de capture (en ticks)
+	dir        string            // dossier où sont écrites les captures
+	recorder   *capture.Recorder // enregistrement en cours, nil sinon
+	last       int64             // dernier tick capturé
+	screenshot bool              // capture d'écran demandée pour la prochaine image
+}
+
+// Création de la capture d'écran
+func newScreenCapture(format capture.Format, every int, dir string) screenCapture {
+	if format == "" {
+		format = capture.GIF
+	}
+	if every < 1 {
+		every = CaptureEvery
+	}
+	return screenCapture{format: format, every: every, dir: dir, last: -1}
+}
+
+// Fonction qui traite les touches de capture: G démarre ou arrête l'enregistrement, F12 fait une capture d'écran
+func (c *screenCapture) handleInput(tick int64) {
+	if inpututil.IsKeyJustPressed(ebiten.KeyG) {
+		if c.recorder != nil {
+			if err := c.stop(); err != nil {
+				log.Printf("Failed to save recording: %v", err)
+			}
+		} else if err := c.start(tick); err != nil {
+			log.Printf("Failed to start recording: %v", err)
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF12) {
+		c.screenshot = true
+	}
+}
+
+// Fonction qui démarre un enregistrement: dossier "capture_<tick>" pour le format PNG, fichier "capture_<tick>.gif" sinon
+func (c *screenCapture) start(tick int64) error {
+	path := filepath.Join(c.dir, fmt.Sprintf("capture_%d", tick))
+	maxWidth := 0
+	if c.format == capture.GIF {
+		path += ".gif"
+		maxWidth = capture.DefaultGIFWidth
+	}
+
+	recorder, err := capture.NewRecorder(path, c.format, time.Duration(c.every)*time.Second/TicksPerSecond, maxWidth)
+	if err != nil {
+		return err
+	}
+	c.recorder = recorder
+	c.last = -1
+	log.Printf("Enregistrement des captures dans %s", path)
+	return nil
+}
+
+// Fonction qui arrête l'enregistrement en cours et attend la fin de l'encodage
+func (c *screenCapture) stop() error {
+	if c.recorder == nil {
+		return nil
+	}
+	recorder := c.recorder
+	c.recorder = nil
+	if err := recorder.Close(); err != nil {
+		return err
+	}
+	log.Printf("%d captures enregistrées dans %s", recorder.Count(), recorder.Path)
+	return nil
+}
+
+// Fonction qui indique si une image doit être enregistrée pour ce tick
+func (c *screenCapture) due(tick int64) bool {
+	return c.recorder != nil && tick != c.last && (c.last < 0 || tick-c.last >= int64(c.every))
+}
+
+// Fonction qui capture l'écran s'il le faut: elle est appelée à la fin de l'affichage
+func (c *screenCapture) capture(screen *ebiten.Image, tick int64) {
+	if !c.screenshot && !c.due(tick) {
+		return
+	}
+	img := image.NewRGBA(screen.Bounds())
+	screen.ReadPixels(img.Pix)
+	c.add(img, tick)
+}
+
+// Fonction qui enregistre une image déjà rendue, pour l'enregistrement et/ou la capture d'écran demandée
+func (c *screenCapture) add(img *image.RGBA, tick int64) {
+	if c.screenshot {
+		c.screenshot = false
+		path := filepath.Join(c.dir, fmt.Sprintf("screenshot_%d.png", tick))
+		if err := capture.SavePNG(path, img); err != nil {
+			log.Printf("Failed to save screenshot: %v", err)
+		} else {
+			log.Printf("Capture d'écran enregistrée dans %s", path)
+		}
+	}
+	if c.due(tick) {
+		c.last = tick
+		// À la limite d'images d'une animation, l'enregistrement s'arrête et garde les images déjà capturées
+		if err := c.recorder.Add(img); err != nil {
+			log.Printf("Recording stopped: %v", err)
+			if err := c.stop(); err != nil {
+				log.Printf("Failed to save recording: %v", err)
+			}
+		}
+	}
+}
+
+// Fonction d'affichage du témoin d'enregistrement, dessiné après la capture pour ne pas y apparaître
+func (c *screenCapture) draw(screen *ebiten.Image) {
+	if c.recorder == nil {
+		return
+	}
+	// En bas à gauche de la carte, à côté du panneau d'informations
+	x, y := PanelWidth+PanelPadding, screen.Bounds().Dy()-PanelPadding-PanelLineHeight
+	vector.DrawFilledCircle(screen, float32(x+6), float32(y+8), 6, color.RGBA{220, 30, 30, 255}, true)
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("REC %d", c.recorder.Count()), x+16, y)
+}
@@ -0,0 +1,145 @@
+package simulation
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Constantes de l'horloge de la simulation
+const (
+	MinSpeed            = 0.25 // vitesse minimale de la simulation
+	MaxSpeed            = 16   // vitesse maximale de la simulation
+	ControlButtonWidth  = 56   // largeur d'un bouton de la barre de contrôle
+	ControlButtonHeight = 20   // hauteur d'un bouton de la barre de contrôle
+)
+
+// Horloge de la simulation: pause, avance tick par tick et multiplicateur de vitesse
+type simClock struct {
+	paused  bool
+	speed   float64
+	pending float64 // fraction de tick accumulée entre deux images
+	single  bool    // un seul tick est demandé alors que la simulation est en pause
+	endTick int64   // tick auquel la simulation se termine
+	buttons []controlButton
+}
+
+// Bouton de la barre de contrôle
+type controlButton struct {
+	label  string
+	rect   image.Rectangle
+	action func(*simClock)
+}
+
+// Création de l'horloge d'une simulation qui doit durer duration à partir du tick start
+func newSimClock(start int64, duration time.Duration) simClock {
+	return simClock{
+		speed:   1,
+		endTick: start + int64(duration.Seconds()*TicksPerSecond),
+	}
+}
+
+// Fonction qui met en pause ou relance la simulation
+func (c *simClock) togglePause() {
+	c.paused = !c.paused
+	c.pending = 0
+}
+
+// Fonction qui demande d'avancer d'un seul tick (met la simulation en pause)
+func (c *simClock) stepOnce() {
+	c.paused = true
+	c.single = true
+}
+
+// Fonction qui multiplie la vitesse de la simulation par factor en restant dans les bornes autorisées
+func (c *simClock) scaleSpeed(factor float64) {
+	c.speed = max(MinSpeed, min(MaxSpeed, c.speed*factor))
+}
+
+// Fonction qui renvoie le nombre de ticks à exécuter pendant l'image courante
+func (c *simClock) ticks() int {
+	if c.paused {
+		if c.single {
+			c.single = false
+			return 1
+		}
+		return 0
+	}
+	c.pending += c.speed
+	n := int(c.pending)
+	c.pending -= float64(n)
+	return n
+}
+
+// Fonction qui renvoie le temps de simulation restant avant la fin
+func (c *simClock) remaining(tick int64) time.Duration {
+	return time.Duration(max(0, c.endTick-tick)) * time.Second / TicksPerSecond
+}
+
+// Fonction qui traite les raccourcis clavier et les clics sur la barre de contrôle; renvoie vrai si le clic a été consommé
+func (c *simClock) handleInput() bool {
+	if inpututil.IsKeyJustPressed(ebiten.KeySpace) || inpututil.IsKeyJustPressed(ebiten.KeyP) {
+		c.togglePause()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyN) || inpututil.IsKeyJustPressed(ebiten.KeyPeriod) {
+		c.stepOnce()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEqual) || inpututil.IsKeyJustPressed(ebiten.KeyNumpadAdd) {
+		c.scaleSpeed(2)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyMinus) || inpututil.IsKeyJustPressed(ebiten.KeyNumpadSubtract) {
+		c.scaleSpeed(0.5)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.Key1) {
+		c.speed = 1
+	}
+
+	cursor := image.Pt(ebiten.CursorPosition())
+	for _, button := range c.buttons {
+		if cursor.In(button.rect) {
+			if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+				button.action(c)
+			}
+			return ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft)
+		}
+	}
+	return false
+}
+
+// Fonction d'affichage de la barre de contrôle en haut à droite de la fenêtre
+func (c *simClock) draw(screen *ebiten.Image, tick int64) {
+	pauseLabel := "Pause"
+	if c.paused {
+		pauseLabel = "Lecture"
+	}
+	labels := []controlButton{
+		{label: pauseLabel, action: (*simClock).togglePause},
+		{label: "+1 tick", action: (*simClock).stepOnce},
+		{label: "-", action: func(c *simClock) { c.scaleSpeed(0.5) }},
+		{label: "+", action: func(c *simClock) { c.scaleSpeed(2) }},
+	}
+
+	width := screen.Bounds().Dx()
+	x := width - len(labels)*(ControlButtonWidth+4) - 4
+	y := 4
+	c.buttons = c.buttons[:0]
+	for _, button := range labels {
+		button.rect = image.Rect(x, y, x+ControlButtonWidth, y+ControlButtonHeight)
+		vector.DrawFilledRect(screen, float32(x), float32(y), ControlButtonWidth, ControlButtonHeight, color.RGBA{0, 0, 0, 180}, false)
+		ebitenutil.DebugPrintAt(screen, button.label, x+(ControlButtonWidth-len([]rune(button.label))*6)/2, y+2)
+		c.buttons = append(c.buttons, button)
+		x += ControlButtonWidth + 4
+	}
+
+	status := fmt.Sprintf("tick %d  x%g", tick, c.speed)
+	if c.paused {
+		status += "  (en pause)"
+	}
+	ebitenutil.DebugPrintAt(screen, status, width-len(labels)*(ControlButtonWidth+4), y+ControlButtonHeight+4)
+}
@@ -0,0 +1,452 @@
+package simulation
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"slices"
+
+	ag "github.com/Tmegaa/The-Gophecy/pkg/Agent"
+	carte "github.com/Tmegaa/The-Gophecy/pkg/Carte"
+	tile "github.com/Tmegaa/The-Gophecy/pkg/Tile"
+	ut "github.com/Tmegaa/The-Gophecy/pkg/Utilitaries"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Constantes de l'éditeur
+const (
+	EditorButtonWidth = 110 // largeur d'un bouton de la barre d'outils
+	OpinionEditStep   = 0.05
+	ParameterEditStep = 0.1
+)
+
+// Outils de l'éditeur
+type EditorTool int
+
+const (
+	SelectTool EditorTool = iota
+	AddAgentTool
+	DeleteAgentTool
+	AddComputerTool
+	AddStatueTool
+	RemoveObjectTool
+)
+
+func (t EditorTool) String() string {
+	return [...]string{"Déplacer", "Ajouter agent", "Supprimer agent", "Ajouter PC", "Ajouter statue", "Retirer objet"}[t]
+}
+
+// Liste des outils dans l'ordre de la barre d'outils, avec leur raccourci
+var editorTools = []EditorTool{SelectTool, AddAgentTool, DeleteAgentTool, AddComputerTool, AddStatueTool, RemoveObjectTool}
+var editorKeys = []ebiten.Key{ebiten.KeyF1, ebiten.KeyF2, ebiten.KeyF3, ebiten.KeyF4, ebiten.KeyF5, ebiten.KeyF6}
+
+// Stratégies de mouvement proposées pour les nouveaux agents
+var editorStrategies = []ag.MovementStrategy{ag.RandomMovement, ag.PatrolMovement, ag.HeatMapMovement, ag.CenterOfMassMovement}
+
+// Éditeur qui permet de modifier la population et les objets pendant la simulation
+type editor struct {
+	Active   bool
+	tool     EditorTool
+	typeAgt  ag.TypeAgent
+	subType  ag.SubTypeAgent
+	strategy ag.MovementStrategy
+	dragging *ag.Agent
+	placed   []ag.InterfaceObjet // objets ajoutés depuis l'éditeur (absents des tuiles de la carte)
+	removed  []image.Rectangle   // rectangles des ordinateurs et statues de la carte retirés, dont les tiles sont masquées
+	pending  []func()            // modifications en attente, appliquées pendant que les agents sont en attente
+	buttons  []image.Rectangle
+}
+
+// Création de l'éditeur, désactivé par défaut
+func newEditor() editor {
+	return editor{tool: SelectTool, typeAgt: ag.Believer, subType: ag.None, strategy: ag.RandomMovement}
+}
+
+// Fonction qui retrouve, parmi les objets restaurés d'une sauvegarde, ceux qui ont été ajoutés depuis l'éditeur (hors des
+// tiles de la carte), ainsi que les ordinateurs et statues de la carte qui ont été retirés
+func (ed *editor) restoreObjects(c *carte.Carte, objets []ag.InterfaceObjet) {
+	atTile := func(obj ag.InterfaceObjet, rect image.Rectangle) bool {
+		pos := obj.ObjPosition()
+		return rect.Min == image.Pt(int(pos.X), int(pos.Y))
+	}
+	tiles := slices.Concat(c.Ordinateurs, c.Statues)
+	for _, obj := range objets {
+		if !slices.ContainsFunc(tiles, func(rect image.Rectangle) bool { return atTile(obj, rect) }) {
+			ed.placed = append(ed.placed, obj)
+		}
+	}
+	for _, rect := range tiles {
+		if !slices.ContainsFunc(objets, func(obj ag.InterfaceObjet) bool { return atTile(obj, rect) }) {
+			ed.removed = append(ed.removed, rect)
+		}
+	}
+}
+
+// Fonction qui traite les raccourcis et les clics de l'éditeur; renvoie vrai si le clic a été consommé
+func (sim *Simulation) handleEditorInput() bool {
+	ed := &sim.editor
+	if inpututil.IsKeyJustPressed(ebiten.KeyE) {
+		ed.Active = !ed.Active
+		ed.dragging = nil
+	}
+	if !ed.Active {
+		return false
+	}
+
+	// Choix de l'outil et des caractéristiques des nouveaux agents
+	for i, key := range editorKeys {
+		if inpututil.IsKeyJustPressed(key) {
+			ed.tool = editorTools[i]
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyT) {
+		ed.typeAgt = cycle(ed.typeAgt, []ag.TypeAgent{ag.Believer, ag.Sceptic, ag.Neutral})
+		if ed.typeAgt == ag.Neutral {
+			ed.subType = ag.None
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyY) && ed.typeAgt != ag.Neutral {
+		ed.subType = cycle(ed.subType, []ag.SubTypeAgent{ag.None, ag.Converter, ag.Pirate})
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyM) {
+		ed.strategy = cycle(ed.strategy, editorStrategies)
+	}
+
+	// Modification de l'agent sélectionné
+	if sim.selected != nil {
+		switch {
+		case inpututil.IsKeyJustPressed(ebiten.KeyBracketLeft):
+			sim.editSelected(func(a *ag.Agent) { a.EditOpinion(a.Opinion - OpinionEditStep) })
+		case inpututil.IsKeyJustPressed(ebiten.KeyBracketRight):
+			sim.editSelected(func(a *ag.Agent) { a.EditOpinion(a.Opinion + OpinionEditStep) })
+		case inpututil.IsKeyJustPressed(ebiten.KeySemicolon):
+			sim.editSelected(func(a *ag.Agent) { a.PersonalParameter = max(0, a.PersonalParameter-ParameterEditStep) })
+		case inpututil.IsKeyJustPressed(ebiten.KeyQuote):
+			sim.editSelected(func(a *ag.Agent) { a.PersonalParameter += ParameterEditStep })
+		}
+	}
+
+	screenX, screenY := ebiten.CursorPosition()
+	pressed := ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft)
+	justPressed := inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft)
+
+	// Clic sur la barre d'outils
+	for i, rect := range ed.buttons {
+		if image.Pt(screenX, screenY).In(rect) {
+			if justPressed {
+				ed.tool = editorTools[i]
+			}
+			return pressed
+		}
+	}
+	if screenX < PanelWidth {
+		return false
+	}
+
+	worldX, worldY := sim.camera.ScreenToWorld(screenX, screenY)
+	switch ed.tool {
+	case SelectTool:
+		if justPressed {
+			ed.dragging = sim.agentAt(worldX, worldY)
+		}
+		if !pressed {
+			ed.dragging = nil
+		}
+		if agent := ed.dragging; agent != nil {
+			sim.queueEdit(func() { sim.moveAgent(agent, worldX-AgentImageSize/2, worldY-AgentImageSize/2) })
+		}
+		return false
+
+	case AddAgentTool:
+		if justPressed {
+			sim.queueEdit(func() { sim.placeAgent(worldX-AgentImageSize/2, worldY-AgentImageSize/2) })
+		}
+	case DeleteAgentTool:
+		if justPressed {
+			if agent := sim.agentAt(worldX, worldY); agent != nil {
+				sim.queueEdit(func() { sim.deleteAgent(agent) })
+			}
+		}
+	case AddComputerTool, AddStatueTool:
+		if justPressed {
+			tool := ed.tool
+			sim.queueEdit(func() { sim.placeObject(tool, worldX-TileSize/2, worldY-TileSize/2) })
+		}
+	case RemoveObjectTool:
+		if justPressed {
+			if obj := sim.objectAt(worldX, worldY); obj != nil {
+				sim.queueEdit(func() { sim.removeObject(obj) })
+			}
+		}
+	}
+	return pressed
+}
+
+// Fonction qui renvoie l'élément suivant de values (le premier si value n'y est pas)
+func cycle[T comparable](value T, values []T) T {
+	return values[(slices.Index(values, value)+1)%len(values)]
+}
+
+// Fonction qui met une modification de l'éditeur en attente: les agents lisent les relations et les positions des autres
+// pendant leur tick, les modifications sont donc appliquées par applyEdits pendant qu'ils attendent le tick suivant
+func (sim *Simulation) queueEdit(edit func()) {
+	sim.editor.pending = append(sim.editor.pending, edit)
+}
+
+// Fonction qui applique les modifications en attente de l'éditeur (voir applyRequests)
+func (sim *Simulation) applyEdits() {
+	for _, edit := range sim.editor.pending {
+		edit()
+	}
+	sim.editor.pending = nil
+}
+
+// Fonction qui applique une modification à l'agent sélectionné, l'environnement étant verrouillé
+func (sim *Simulation) editSelected(edit func(*ag.Agent)) {
+	selected := sim.selected
+	sim.queueEdit(func() {
+		sim.env.Lock()
+		defer sim.env.Unlock()
+		edit(selected)
+	})
+}
+
+// Fonction qui renvoie l'agent situé à la position (x, y) du monde, nil s'il n'y en a pas
+func (sim *Simulation) agentAt(x, y float64) *ag.Agent {
+	for _, agent := range sim.agents {
+		if x >= agent.Position.X && x <= agent.Position.X+AgentImageSize &&
+			y >= agent.Position.Y && y <= agent.Position.Y+AgentImageSize {
+			return agent
+		}
+	}
+	return nil
+}
+
+// Fonction qui renvoie l'objet situé à la position (x, y) du monde, nil s'il n'y en a pas
+func (sim *Simulation) objectAt(x, y float64) ag.InterfaceObjet {
+	for _, obj := range sim.objets {
+		pos := obj.ObjPosition()
+		if x >= pos.X && x <= pos.X+TileSize && y >= pos.Y && y <= pos.Y+TileSize {
+			return obj
+		}
+	}
+	return nil
+}
+
+// Fonction qui renvoie le rectangle occupé par un objet: celui de sa tile pour les objets de la carte, une case sinon
+func (sim *Simulation) objectBounds(obj ag.InterfaceObjet) image.Rectangle {
+	pos := obj.ObjPosition()
+	topLeft := image.Pt(int(pos.X), int(pos.Y))
+	for _, rect := range slices.Concat(sim.carte.Ordinateurs, sim.carte.Statues) {
+		if rect.Min == topLeft {
+			return rect
+		}
+	}
+	return image.Rectangle{Min: topLeft, Max: topLeft.Add(image.Pt(TileSize, TileSize))}
+}
+
+// Fonction qui indique si un agent peut se trouver à la position (x, y) du monde
+func (sim *Simulation) isFreePosition(x, y float64) bool {
+	box := image.Rect(int(x), int(y), int(x)+AgentImageSize, int(y)+AgentImageSize)
+	if x < float64(sim.carte.Bounds.Min.X) || y < float64(sim.carte.Bounds.Min.Y) || !box.In(sim.carte.Bounds) {
+		return false
+	}
+	for _, colider := range sim.carte.Coliders {
+		if colider.Overlaps(box) {
+			return false
+		}
+	}
+	return true
+}
+
+// Fonction qui déplace un agent à la position (x, y) si elle est libre
+func (sim *Simulation) moveAgent(agent *ag.Agent, x, y float64) {
+	if !sim.isFreePosition(x, y) {
+		return
+	}
+	sim.env.Lock()
+	defer sim.env.Unlock()
+	agent.Position.X, agent.Position.Y = x, y
+}
+
+// Fonction qui crée un agent avec les caractéristiques choisies dans l'éditeur à la position (x, y)
+func (sim *Simulation) placeAgent(x, y float64) {
+	if !sim.isFreePosition(x, y) {
+		return
+	}
+	ed := &sim.editor
+
+	sim.env.Lock()
+	defer sim.env.Unlock()
+
+	// Identifiant libre et opinion tirée dans l'intervalle du type choisi
+	var id ag.IdAgent
+	for i := len(sim.env.Ags); ; i++ {
+		id = ag.IdAgent(fmt.Sprintf("Agent%d", i))
+		if sim.env.GetAgentById(id) == nil {
+			break
+		}
+	}
+	var opinion float64
+	switch ed.typeAgt {
+	case ag.Believer:
+		opinion = sim.env.Rng.Float64()*(1./3.) + 2./3.
+	case ag.Neutral:
+		opinion = sim.env.Rng.Float64()*(1./3.) + 1./3.
+	default:
+		opinion = sim.env.Rng.Float64() * (1. / 3.)
+	}
+
+	agent := ag.NewAgent(
+		sim.env,
+		id,
+		sim.env.Rng.Float64(),
+		50.0,
+		ut.Position{X: x, Y: y},
+		opinion,
+		make(map[ag.IdAgent]float64),
+		make(map[ag.IdAgent]float64),
+		0.1+sim.env.Rng.Float64()*4.0-0.1,
+		ed.typeAgt,
+		make(chan ag.Message),
+	)
+	agent.SubType = ed.subType
+	agent.MovementStrategy = ed.strategy
+
+	// Les agents partagent la même carte des visites
+	for _, other := range sim.env.Ags {
+		if other.HeatMap != nil {
+			agent.HeatMap = other.HeatMap
+			break
+		}
+	}
+	if agent.HeatMap == nil {
+		agent.HeatMap = ag.NewVisitationMap(sim.carte.SpawnPositions())
+	}
+
+	sim.env.AddAgent(agent)
+	sim.env.ConnectAgent(agent)
+	sim.agents = sim.env.Ags
+	sim.influence.Register(agent.Id, agent.SubType)
+	sim.env.StartAgent(agent)
+}
+
+// Fonction qui retire un agent de la simulation
+func (sim *Simulation) deleteAgent(agent *ag.Agent) {
+	sim.env.Lock()
+	defer sim.env.Unlock()
+
+	sim.env.RemoveAgent(agent)
+	sim.agents = sim.env.Ags
+	if sim.selected == agent {
+		sim.selected = nil
+	}
+	if sim.editor.dragging == agent {
+		sim.editor.dragging = nil
+	}
+}
+
+// Fonction qui ajoute un ordinateur ou une statue à la position (x, y)
+func (sim *Simulation) placeObject(tool EditorTool, x, y float64) {
+	sim.env.Lock()
+	defer sim.env.Unlock()
+
+	prefix, objType := "Computer", ag.ComputerType
+	if tool == AddStatueTool {
+		prefix, objType = "Statue", ag.StatueType
+	}
+	n := 0
+	for _, obj := range sim.env.Objs {
+		if obj.GetType() == objType {
+			n++
+		}
+	}
+	var id ag.IdObjet
+	for ; ; n++ {
+		id = ag.IdObjet(fmt.Sprintf("%s%d", prefix, n))
+		if !slices.ContainsFunc(sim.env.Objs, func(o ag.InterfaceObjet) bool { return o.ID() == id }) {
+			break
+		}
+	}
+
+	var obj ag.InterfaceObjet
+	if tool == AddStatueTool {
+		obj = ag.NewStatue(sim.env, id, ut.Position{X: x, Y: y})
+	} else {
+		obj = ag.NewComputer(sim.env, id, ut.Position{X: x, Y: y})
+	}
+	sim.env.AddObject(obj)
+	sim.objets = sim.env.Objs
+	sim.editor.placed = append(sim.editor.placed, obj)
+}
+
+// Fonction qui retire un ordinateur ou une statue de la simulation
+func (sim *Simulation) removeObject(obj ag.InterfaceObjet) {
+	sim.env.Lock()
+	defer sim.env.Unlock()
+
+	sim.env.RemoveObject(obj)
+	sim.objets = sim.env.Objs
+	if slices.Contains(sim.editor.placed, obj) {
+		sim.editor.placed = slices.DeleteFunc(sim.editor.placed, func(o ag.InterfaceObjet) bool { return o == obj })
+	} else {
+		// L'objet fait partie des tiles de la carte: elles ne sont plus affichées
+		sim.editor.removed = append(sim.editor.removed, sim.objectBounds(obj))
+	}
+	if sim.selectedPC != nil && ag.InterfaceObjet(sim.selectedPC) == obj {
+		sim.selectedPC = nil
+	}
+}
+
+// Fonction qui indique si la tile de la carte occupant bounds est un ordinateur ou une statue retiré depuis l'éditeur
+func (ed *editor) removedTile(bounds image.Rectangle, hasRole func(role string) bool) bool {
+	return slices.Contains(ed.removed, bounds) && (hasRole(tile.ComputerRole) || hasRole(tile.StatueRole))
+}
+
+// Fonction d'affichage des objets ajoutés depuis l'éditeur (les autres font partie des tuiles de la carte)
+func (sim *Simulation) drawPlacedObjects(world *ebiten.Image) {
+	for _, obj := range sim.editor.placed {
+		pos := obj.ObjPosition()
+		c, label := color.RGBA{80, 120, 255, 255}, "PC"
+		if obj.GetType() == ag.StatueType {
+			c, label = color.RGBA{220, 180, 40, 255}, "St"
+		}
+		vector.DrawFilledRect(world, float32(pos.X), float32(pos.Y), TileSize, TileSize, c, false)
+		vector.StrokeRect(world, float32(pos.X), float32(pos.Y), TileSize, TileSize, 1, color.Black, false)
+		ebitenutil.DebugPrintAt(world, label, int(pos.X)+6, int(pos.Y)+4)
+	}
+}
+
+// Fonction d'affichage de la barre d'outils de l'éditeur en haut de l'écran
+func (ed *editor) draw(screen *ebiten.Image) {
+	ed.buttons = ed.buttons[:0]
+	if !ed.Active {
+		return
+	}
+
+	width := len(editorTools) * (EditorButtonWidth + 4)
+	x := (screen.Bounds().Dx() - width) / 2
+	y := 4
+	for i, tool := range editorTools {
+		rect := image.Rect(x, y, x+EditorButtonWidth, y+ControlButtonHeight)
+		bg := color.RGBA{0, 0, 0, 180}
+		if tool == ed.tool {
+			bg = color.RGBA{120, 120, 40, 220}
+		}
+		vector.DrawFilledRect(screen, float32(rect.Min.X), float32(rect.Min.Y), float32(rect.Dx()), float32(rect.Dy()), bg, false)
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("F%d %s", i+1, tool), x+4, y+2)
+		ed.buttons = append(ed.buttons, rect)
+		x += EditorButtonWidth + 4
+	}
+
+	x = (screen.Bounds().Dx() - width) / 2
+	y += ControlButtonHeight + 4
+	info := fmt.Sprintf("Nouvel agent: [T] %s  [Y] %s  [M] %s    Agent sélectionné: [ ] opinion  ; ' paramètre    [E] quitter l'éditeur",
+		ed.typeAgt, ed.subType, ed.strategy)
+	vector.DrawFilledRect(screen, float32(x), float32(y), float32(width), PanelLineHeight+4, color.RGBA{0, 0, 0, 180}, false)
+	ebitenutil.DebugPrintAt(screen, info, x+4, y+2)
+}
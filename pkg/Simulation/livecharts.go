@@ -0,0 +1,142 @@
+package simulation
+
+import (
+	"fmt"
+	"image/color"
+
+	ag "github.com/Tmegaa/The-Gophecy/pkg/Agent"
+	metrics "github.com/Tmegaa/The-Gophecy/pkg/Metrics"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Constantes du panneau de graphiques en direct
+const (
+	LiveChartsEvery  = 30  // période de mise à jour des graphiques (en ticks)
+	LiveChartsMax    = 300 // nombre maximal de points conservés
+	LiveChartsWidth  = 360 // largeur du panneau
+	LiveChartHeight  = 90  // hauteur d'un graphique
+	LiveChartsMargin = 10  // marge autour des graphiques
+)
+
+// Graphiques de la population mis à jour pendant la simulation
+type liveCharts struct {
+	Visible bool
+	samples []metrics.Sample
+}
+
+// Fonction qui affiche ou masque le panneau des graphiques
+func (lc *liveCharts) handleInput() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyC) {
+		lc.Visible = !lc.Visible
+	}
+}
+
+// Fonction qui mesure la population tous les LiveChartsEvery ticks
+func (sim *Simulation) recordLiveCharts() {
+	tick := sim.env.Tick()
+	if tick%LiveChartsEvery != 0 {
+		return
+	}
+	lc := &sim.liveCharts
+	lc.samples = append(lc.samples, metrics.Measure(tick, sim.agents, sim.objets, HistogramBins))
+	if len(lc.samples) > LiveChartsMax {
+		lc.samples = lc.samples[len(lc.samples)-LiveChartsMax:]
+	}
+}
+
+// Fonction d'affichage du panneau des graphiques en bas à droite de l'écran
+func (lc *liveCharts) draw(screen *ebiten.Image) {
+	if !lc.Visible {
+		return
+	}
+
+	bounds := screen.Bounds()
+	panelHeight := 3*(LiveChartHeight+2*PanelLineHeight) + LiveChartsMargin
+	x := bounds.Dx() - LiveChartsWidth - LiveChartsMargin
+	y := bounds.Dy() - panelHeight - LiveChartsMargin
+	vector.DrawFilledRect(screen, float32(x), float32(y), LiveChartsWidth, float32(panelHeight), color.RGBA{0, 0, 0, 180}, false)
+
+	x += LiveChartsMargin
+	width := LiveChartsWidth - 2*LiveChartsMargin
+	if len(lc.samples) == 0 {
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("En attente de données (tous les %d ticks)...", LiveChartsEvery), x, y+LiveChartsMargin)
+		return
+	}
+	last := lc.samples[len(lc.samples)-1]
+
+	// Opinion moyenne
+	y += PanelLineHeight / 2
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Opinion moyenne: %.3f (variance %.3f)", last.Mean, last.Variance), x, y)
+	y += PanelLineHeight
+	means := make([]float64, len(lc.samples))
+	for i, s := range lc.samples {
+		means[i] = s.Mean
+	}
+	drawChartFrame(screen, x, y, width)
+	drawSeries(screen, x, y, width, means, 1, color.RGBA{255, 200, 0, 255})
+	y += LiveChartHeight + PanelLineHeight
+
+	// Nombre d'agents par type
+	total := 0
+	label := "Types:"
+	for _, t := range metrics.AgentTypes {
+		total += last.TypeCounts[t]
+		label += fmt.Sprintf(" %s %d", t, last.TypeCounts[t])
+	}
+	ebitenutil.DebugPrintAt(screen, label, x, y)
+	y += PanelLineHeight
+	drawChartFrame(screen, x, y, width)
+	for _, t := range metrics.AgentTypes {
+		counts := make([]float64, len(lc.samples))
+		for i, s := range lc.samples {
+			counts[i] = float64(s.TypeCounts[t])
+		}
+		drawSeries(screen, x, y, width, counts, float64(max(total, 1)), typeColor(t))
+	}
+	y += LiveChartHeight + PanelLineHeight
+
+	// Histogramme des opinions
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Histogramme des opinions (tick %d)", last.Tick), x, y)
+	y += PanelLineHeight
+	drawChartFrame(screen, x, y, width)
+	highest := 1
+	for _, count := range last.Histogram {
+		highest = max(highest, count)
+	}
+	barWidth := float32(width) / float32(len(last.Histogram))
+	for i, count := range last.Histogram {
+		barHeight := float32(LiveChartHeight) * float32(count) / float32(highest)
+		opinion := (float64(i) + 0.5) / float64(len(last.Histogram))
+		vector.DrawFilledRect(screen, float32(x)+float32(i)*barWidth+1, float32(y+LiveChartHeight)-barHeight, barWidth-2, barHeight, opinionColor(opinion), false)
+	}
+}
+
+// Fonction qui renvoie la couleur d'un type d'agent dans les graphiques
+func typeColor(t ag.TypeAgent) color.RGBA {
+	c := metrics.TypeColors[t]
+	return color.RGBA{c.R, c.G, c.B, c.A}
+}
+
+// Fonction d'affichage du fond d'un graphique
+func drawChartFrame(screen *ebiten.Image, x, y, width int) {
+	vector.DrawFilledRect(screen, float32(x), float32(y), float32(width), LiveChartHeight, color.RGBA{230, 230, 230, 230}, false)
+}
+
+// Fonction d'affichage d'une série de valeurs comprises entre 0 et maxValue
+func drawSeries(screen *ebiten.Image, x, y, width int, values []float64, maxValue float64, c color.RGBA) {
+	if len(values) < 2 {
+		return
+	}
+	step := float32(width) / float32(len(values)-1)
+	for i := 1; i < len(values); i++ {
+		x0 := float32(x) + step*float32(i-1)
+		x1 := float32(x) + step*float32(i)
+		y0 := float32(y+LiveChartHeight) - float32(LiveChartHeight*values[i-1]/maxValue)
+		y1 := float32(y+LiveChartHeight) - float32(LiveChartHeight*values[i]/maxValue)
+		vector.StrokeLine(screen, x0, y0, x1, y1, 1.5, c, true)
+	}
+}
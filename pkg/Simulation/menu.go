@@ -6,6 +6,7 @@ import (
 	"time"
 
 	ag "github.com/Tmegaa/The-Gophecy/pkg/Agent"
+	capture "github.com/Tmegaa/The-Gophecy/pkg/Capture"
 )
 
 // Type qui gère la configuration de la simulation
@@ -19,11 +20,35 @@ type SimulationConfig struct {
 	ScepticMovement  ag.MovementStrategy // Stratégie de mouvement des sceptiques
 	NeutralMovement  ag.MovementStrategy // Stratégie de mouvement des agents neutres
 	AgentsFilePath   string              // Chemin du fichier JSON contenant les agents
+	EventLogPath     string              // Chemin du journal d'évènements (.jsonl ou .csv), vide pour le désactiver
+	OutputDir        string              // Dossier où sont écrits les résultats de la simulation
+	MetricsEvery     int                 // Période d'échantillonnage des mesures (en ticks)
+	Seed             int64               // Graine du générateur aléatoire (0 pour une graine tirée au hasard)
+	SnapshotPath     string              // Chemin d'une sauvegarde à reprendre
+	TracePath        string              // Chemin de la trace à enregistrer pour la rejouer (.jsonl ou .jsonl.gz)
+	Headless         bool                // Fait tourner la simulation sans fenêtre d'affichage
+	MapFile          string              // Carte: nom d'un fichier du dossier des cartes ou chemin d'un fichier TILED
+	OpinionModel     ag.OpinionModel     // Modèle de dynamique d'opinion
+	CaptureFormat    capture.Format      // Format de l'enregistrement lancé au démarrage (png ou gif), vide pour ne rien enregistrer
+	CaptureEvery     int                 // Période des captures (en ticks)
+}
+
+// Fonction qui renvoie la configuration par défaut de la simulation
+func DefaultConfig() SimulationConfig {
+	return SimulationConfig{
+		NumAgents:      50,
+		SimulationTime: 5 * time.Minute,
+		OutputDir:      ".",
+		MetricsEvery:   1,
+		MapFile:        TilemapJSONFile,
+		OpinionModel:   ag.GophecyModel,
+		CaptureEvery:   CaptureEvery,
+	}
 }
 
 // Fonction qui gère l'initialisation de la simulation avec les valeurs données par l'utilisateur
 func ShowMenu() SimulationConfig {
-	config := SimulationConfig{}
+	config := DefaultConfig()
 
 	fmt.Println("\nBienvenue dans la Simulation github.com/Tmegaa/The-Gophecy/!")
 	fmt.Println("----------------------------------------")
@@ -33,7 +58,8 @@ func ShowMenu() SimulationConfig {
 	fmt.Println("1 - Nombre total d'agents")
 	fmt.Println("2 - Quantité de chaque type d'agent")
 	fmt.Println("3 - Charger les agents depuis un fichier")
-	mode := getChoiceInput("Mode de configuration (1, 2 ou 3)")
+	fmt.Println("4 - Reprendre une simulation sauvegardée")
+	mode := getChoiceInput("Mode de configuration (1, 2, 3 ou 4)")
 
 	if mode == 1 {
 		// Utilisateur donne le nombre total d'agents et la durée de la simulation
@@ -54,11 +80,22 @@ func ShowMenu() SimulationConfig {
 	} else if mode == 3 {
 		// Utilisateur donne le chemin du fichier JSON contenant les agents
 		config.AgentsFilePath = getFilePathInput("Chemin du fichier JSON contenant les agents")
+	} else if mode == 4 {
+		// Utilisateur donne le chemin de la sauvegarde: les agents y conservent leur stratégie de mouvement
+		config.SnapshotPath = getFilePathInput("Chemin de la sauvegarde")
 	}
 
 	durationMinutes := getDurationInput("Durée de la simulation (en minutes)")
 	config.SimulationTime = time.Duration(durationMinutes) * time.Minute
 
+	if mode == 4 {
+		fmt.Println("\nRésumé de la configuration:")
+		fmt.Printf("Sauvegarde reprise: %s\n", config.SnapshotPath)
+		fmt.Printf("Durée: %v\n", config.SimulationTime)
+		fmt.Println("----------------------------------------")
+		return config
+	}
+
 	// Utilisateur choisit les stratégies de mouvement
 	fmt.Println("\nChoisissez la stratégie de mouvement pour chaque type d'agent:")
 	fmt.Println("0 - Random")
@@ -134,10 +171,10 @@ func getChoiceInput(prompt string) int {
 		fmt.Printf("%s: ", prompt)
 		fmt.Scanln(&input)
 		value, err = strconv.Atoi(input)
-		if err == nil && (value == 1 || value == 2 || value == 3) {
+		if err == nil && value >= 1 && value <= 4 {
 			return value
 		}
-		fmt.Println("Veuillez entrer 1, 2 ou 3.")
+		fmt.Println("Veuillez entrer 1, 2, 3 ou 4.")
 	}
 }
 
@@ -0,0 +1,144 @@
+package simulation
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/Tmegaa/The-Gophecy/assets"
+	ag "github.com/Tmegaa/The-Gophecy/pkg/Agent"
+	carte "github.com/Tmegaa/The-Gophecy/pkg/Carte"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// Rendu logiciel de la carte et des agents, sans Ebiten: il sert aux captures des simulations et des traces sans fenêtre
+type offscreenRenderer struct {
+	background *image.RGBA                  // carte dessinée une fois pour toutes
+	sprites    map[ag.TypeAgent]image.Image // image de chaque type d'agent
+}
+
+// Création du rendu logiciel à partir du fichier de la carte
+func newOffscreenRenderer(mapPath string) (*offscreenRenderer, error) {
+	tilemapJSON, err := carte.ReadTilemap(mapPath)
+	if err != nil {
+		return nil, fmt.Errorf("échec du chargement de la carte %s: %v", mapPath, err)
+	}
+	background, err := carte.RenderTilemap(tilemapJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	sprites := make(map[ag.TypeAgent]image.Image)
+	for _, t := range []ag.TypeAgent{ag.Sceptic, ag.Neutral, ag.Believer} {
+		sprite, err := decodeImageFile(AssetsPath + agentImageFile(t))
+		if err != nil {
+			return nil, err
+		}
+		sprites[t] = sprite
+	}
+	return &offscreenRenderer{background: background, sprites: sprites}, nil
+}
+
+// Fonction qui dessine la carte, les discussions et les agents, avec une légende en haut à gauche
+func (o *offscreenRenderer) render(agents []*ag.Agent, caption string) *image.RGBA {
+	img := image.NewRGBA(o.background.Bounds())
+	copy(img.Pix, o.background.Pix)
+
+	// Lignes reliant les agents en discussion
+	half := float64(AgentImageSize) / 2
+	for _, agent := range agents {
+		if agent.CurrentAction == ag.DiscussAct && agent.DiscussingWith != nil {
+			drawLine(img,
+				int(agent.Position.X+half), int(agent.Position.Y+half),
+				int(agent.DiscussingWith.Position.X+half), int(agent.DiscussingWith.Position.Y+half),
+				color.RGBA{150, 150, 150, 255})
+		}
+	}
+
+	for _, agent := range agents {
+		sprite := o.sprites[agent.TypeAgt]
+		if sprite == nil {
+			continue
+		}
+		src := image.Rect(0, 0, AgentImageSize, AgentImageSize).Add(sprite.Bounds().Min)
+		dst := image.Rect(0, 0, AgentImageSize, AgentImageSize).Add(image.Pt(int(agent.Position.X), int(agent.Position.Y)))
+		draw.Draw(img, dst, sprite, src.Min, draw.Over)
+	}
+
+	if caption != "" {
+		drawCaption(img, caption)
+	}
+	return img
+}
+
+// Fonction qui écrit une légende sur fond noir en haut à gauche de l'image
+func drawCaption(img *image.RGBA, caption string) {
+	face := basicfont.Face7x13
+	width := font.MeasureString(face, caption).Ceil()
+	origin := img.Bounds().Min
+	box := image.Rect(0, 0, width+2*PanelPadding, face.Height+PanelPadding).Add(origin)
+	draw.Draw(img, box, image.NewUniform(color.RGBA{0, 0, 0, 200}), image.Point{}, draw.Over)
+
+	drawer := font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(color.White),
+		Face: face,
+		Dot:  fixed.P(origin.X+PanelPadding, origin.Y+face.Ascent+PanelPadding/2),
+	}
+	drawer.DrawString(caption)
+}
+
+// Fonction qui trace un segment d'un pixel de large (algorithme de Bresenham)
+func drawLine(img *image.RGBA, x0, y0, x1, y1 int, c color.RGBA) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	err := dx + dy
+	for {
+		img.SetRGBA(x0, y0, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+// Fonction qui renvoie la valeur absolue d'un entier
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// Fonction qui décode une image depuis un fichier, sans passer par Ebiten
+func decodeImageFile(path string) (image.Image, error) {
+	file, err := assets.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("échec du décodage de l'image %s: %v", path, err)
+	}
+	return img, nil
+}
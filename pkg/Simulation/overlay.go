@@ -0,0 +1,240 @@
+package simulation
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+
+	ag "github.com/Tmegaa/The-Gophecy/pkg/Agent"
+	ut "github.com/Tmegaa/The-Gophecy/pkg/Utilitaries"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/colorm"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Nombre de relations affichées par la couche des liens
+const MaxDisplayedLinks = 5
+
+// Couches d'informations superposées à la carte, activables au clavier
+type overlays struct {
+	Acuite      bool // zone de perception des agents
+	Opinion     bool // teinte des agents selon leur opinion
+	Visits      bool // carte de chaleur des visites de l'équipe
+	Discussions bool // densité des discussions
+	Links       bool // relations les plus fortes de l'agent sélectionné
+	Colliders   bool // obstacles et cases d'apparition de la carte
+	Zones       bool // zones nommées de la carte
+}
+
+// Touches associées à chaque couche
+type overlayToggle struct {
+	key   ebiten.Key
+	label string
+	value *bool
+}
+
+// Fonction qui renvoie la liste des couches avec leur touche et leur nom
+func (o *overlays) toggles() []overlayToggle {
+	return []overlayToggle{
+		{ebiten.KeyA, "Acuité", &o.Acuite},
+		{ebiten.KeyO, "Opinion", &o.Opinion},
+		{ebiten.KeyV, "Visites", &o.Visits},
+		{ebiten.KeyD, "Discussions", &o.Discussions},
+		{ebiten.KeyL, "Liens", &o.Links},
+		{ebiten.KeyX, "Obstacles", &o.Colliders},
+		{ebiten.KeyZ, "Zones", &o.Zones},
+	}
+}
+
+// Fonction qui active ou désactive les couches dont la touche vient d'être pressée
+func (o *overlays) handleInput() {
+	for _, toggle := range o.toggles() {
+		if inpututil.IsKeyJustPressed(toggle.key) {
+			*toggle.value = !*toggle.value
+		}
+	}
+}
+
+// Fonction d'affichage de l'état des couches sous la barre de contrôle
+func (o *overlays) draw(screen *ebiten.Image) {
+	toggles := o.toggles()
+	x := screen.Bounds().Dx() - 4*(ControlButtonWidth+4)
+	y := 2*ControlButtonHeight + 12
+	for _, toggle := range toggles {
+		state := "non"
+		if *toggle.value {
+			state = "oui"
+		}
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("[%s] %s: %s", toggle.key, toggle.label, state), x, y)
+		y += PanelLineHeight
+	}
+}
+
+// Fonction qui renvoie la couleur associée à une opinion: rouge pour 0, noir pour 1
+func opinionColor(opinion float64) color.RGBA {
+	opinion = max(0, min(1, opinion))
+	return color.RGBA{uint8(255 * (1 - opinion)), 0, 0, 255}
+}
+
+// Fonction qui dessine le sprite d'un agent teinté par son opinion (la transparence du sprite est conservée)
+func drawTintedAgent(screen, sprite *ebiten.Image, geoM ebiten.GeoM, opinion float64) {
+	c := opinionColor(opinion)
+	var cm colorm.ColorM
+	cm.Scale(0.3, 0.3, 0.3, 1)
+	cm.Translate(float64(c.R)/255*0.7, float64(c.G)/255*0.7, float64(c.B)/255*0.7, 0)
+	colorm.DrawImage(screen, sprite, cm, &colorm.DrawImageOptions{GeoM: geoM})
+}
+
+// Fonction qui compte les discussions en cours par case de la carte
+func (sim *Simulation) recordDiscussions() {
+	width := sim.carte.TilemapJSON.Width
+	height := sim.carte.TilemapJSON.Height
+	if len(sim.discussionDensity) != width*height {
+		sim.discussionDensity = make([]int, width*height)
+	}
+
+	for _, agent := range sim.agents {
+		if agent.CurrentAction != ag.DiscussAct {
+			continue
+		}
+		x := int(agent.Position.X+AgentImageSize/2) / TileSize
+		y := int(agent.Position.Y+AgentImageSize/2) / TileSize
+		if x >= 0 && x < width && y >= 0 && y < height {
+			sim.discussionDensity[y*width+x]++
+		}
+	}
+}
+
+// Fonction d'affichage des couches activées dans le monde
+func (sim *Simulation) drawOverlays(world *ebiten.Image) {
+	if sim.overlays.Visits {
+		sim.drawVisits(world)
+	}
+	if sim.overlays.Discussions {
+		sim.drawDiscussionDensity(world)
+	}
+	if sim.overlays.Acuite {
+		sim.drawAcuite(world)
+	}
+	if sim.overlays.Links {
+		sim.drawLinks(world)
+	}
+	if sim.overlays.Colliders {
+		sim.drawColliders(world)
+	}
+	if sim.overlays.Zones {
+		sim.drawZones(world)
+	}
+}
+
+// Fonction d'affichage des obstacles (en rouge) et des cases d'apparition (en vert), pour repérer les objets mal placés
+func (sim *Simulation) drawColliders(world *ebiten.Image) {
+	for _, spawn := range sim.carte.Spawns {
+		vector.DrawFilledRect(world, float32(spawn.Min.X+TileSize/2-2), float32(spawn.Min.Y+TileSize/2-2), 4, 4, color.RGBA{30, 170, 60, 200}, false)
+	}
+	for _, colider := range sim.carte.Coliders {
+		vector.DrawFilledRect(world, float32(colider.Min.X), float32(colider.Min.Y), float32(colider.Dx()), float32(colider.Dy()), color.RGBA{80, 0, 0, 80}, false)
+		vector.StrokeRect(world, float32(colider.Min.X), float32(colider.Min.Y), float32(colider.Dx()), float32(colider.Dy()), 1.0, color.RGBA{200, 30, 30, 255}, false)
+	}
+}
+
+// Fonction d'affichage du contour et du nom des zones de la carte
+func (sim *Simulation) drawZones(world *ebiten.Image) {
+	for _, zone := range sim.carte.Zones {
+		bounds := zone.Bounds
+		vector.StrokeRect(world, float32(bounds.Min.X), float32(bounds.Min.Y), float32(bounds.Dx()), float32(bounds.Dy()), 2.0, color.RGBA{40, 90, 220, 255}, false)
+		ebitenutil.DebugPrintAt(world, zoneLabel(&zone), bounds.Min.X+4, bounds.Min.Y+4)
+	}
+}
+
+// Fonction d'affichage de la carte de chaleur des visites, cumulée sur toutes les cartes de visites des agents
+func (sim *Simulation) drawVisits(world *ebiten.Image) {
+	visits := make(map[ut.Position]int)
+	seen := make(map[*ag.VisitationMap]bool)
+	for _, agent := range sim.agents {
+		if agent.HeatMap == nil || seen[agent.HeatMap] {
+			continue
+		}
+		seen[agent.HeatMap] = true
+		agent.HeatMap.ForEach(func(pos ut.Position, count int) {
+			visits[pos] += count
+		})
+	}
+
+	maxVisits := 0
+	for _, count := range visits {
+		maxVisits = max(maxVisits, count)
+	}
+	if maxVisits == 0 {
+		return
+	}
+
+	for pos, count := range visits {
+		if count == 0 {
+			continue
+		}
+		ratio := float64(count) / float64(maxVisits)
+		c := color.RGBA{255, uint8(255 * (1 - ratio)), 0, uint8(40 + 140*ratio)}
+		vector.DrawFilledRect(world, float32(pos.X), float32(pos.Y), TileSize, TileSize, c, false)
+	}
+}
+
+// Fonction d'affichage de la densité des discussions
+func (sim *Simulation) drawDiscussionDensity(world *ebiten.Image) {
+	maxCount := 0
+	for _, count := range sim.discussionDensity {
+		maxCount = max(maxCount, count)
+	}
+	if maxCount == 0 {
+		return
+	}
+
+	width := sim.carte.TilemapJSON.Width
+	for i, count := range sim.discussionDensity {
+		if count == 0 {
+			continue
+		}
+		ratio := float64(count) / float64(maxCount)
+		x, y := (i%width)*TileSize, (i/width)*TileSize
+		vector.DrawFilledRect(world, float32(x), float32(y), TileSize, TileSize, color.RGBA{0, 200, 255, uint8(40 + 160*ratio)}, false)
+	}
+}
+
+// Fonction d'affichage des relations les plus fortes de l'agent sélectionné
+func (sim *Simulation) drawLinks(world *ebiten.Image) {
+	if sim.selected == nil {
+		return
+	}
+
+	others := make([]*ag.Agent, 0, len(sim.agents))
+	for _, agent := range sim.agents {
+		if agent.Id != sim.selected.Id {
+			others = append(others, agent)
+		}
+	}
+	sort.SliceStable(others, func(i, j int) bool {
+		return sim.selected.Relation[others[i].Id] > sim.selected.Relation[others[j].Id]
+	})
+
+	startX := float32(sim.selected.Position.X + AgentImageSize/2)
+	startY := float32(sim.selected.Position.Y + AgentImageSize/2)
+	for _, other := range others[:min(MaxDisplayedLinks, len(others))] {
+		var c color.RGBA
+		switch relation := sim.selected.Relation[other.Id]; {
+		case relation >= 1.5:
+			c = color.RGBA{255, 215, 0, 255} // Famille
+		case relation >= 1.25:
+			c = color.RGBA{0, 220, 0, 255} // Amis
+		case relation >= 1:
+			c = color.RGBA{200, 200, 200, 255} // Pas de lien direct
+		default:
+			c = color.RGBA{255, 0, 0, 255} // Ennemi
+		}
+		endX := float32(other.Position.X + AgentImageSize/2)
+		endY := float32(other.Position.Y + AgentImageSize/2)
+		vector.StrokeLine(world, startX, startY, endX, endY, 2, c, true)
+	}
+}
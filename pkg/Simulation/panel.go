@@ -0,0 +1,517 @@
+package simulation
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"sort"
+	"sync"
+	"time"
+
+	ag "github.com/Tmegaa/The-Gophecy/pkg/Agent"
+	carte "github.com/Tmegaa/The-Gophecy/pkg/Carte"
+	events "github.com/Tmegaa/The-Gophecy/pkg/Events"
+	metrics "github.com/Tmegaa/The-Gophecy/pkg/Metrics"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Constantes du panneau d'informations
+const (
+	PanelWidth           = 240 // largeur du panneau
+	PanelPadding         = 10  // marge intérieure du panneau
+	PanelLineHeight      = 15  // hauteur d'une ligne de texte
+	PanelScrollStep      = 30  // défilement (en pixels) pour un cran de molette ou une touche
+	OpinionHistoryEvery  = 10  // période d'enregistrement de l'opinion des agents (en ticks)
+	OpinionHistoryMax    = 600 // nombre maximal de points d'opinion conservés par agent
+	ConversationsMax     = 50  // nombre maximal de conversations conservées par agent
+	ReceivedInfluenceMax = 10  // nombre maximal de sources d'influence affichées pour un agent
+)
+
+// Onglets du panneau d'informations
+type PanelTab int
+
+const (
+	OverviewTab PanelTab = iota
+	RelationsTab
+	WeightsTab
+	ConversationsTab
+	OpinionTab
+)
+
+func (t PanelTab) String() string {
+	return [...]string{"Infos", "Relat.", "Poids", "Discus.", "Opinion"}[t]
+}
+
+// Liste des onglets dans l'ordre d'affichage
+var panelTabs = []PanelTab{OverviewTab, RelationsTab, WeightsTab, ConversationsTab, OpinionTab}
+
+// État du panneau d'informations: onglet courant et défilement
+type infoPanel struct {
+	tab           PanelTab
+	scroll        int
+	contentHeight int               // hauteur du contenu lors du dernier affichage
+	tabsY         int               // ordonnée de la barre d'onglets lors du dernier affichage
+	tabRects      []image.Rectangle // zones cliquables des onglets
+}
+
+// Conversation terminée par un agent
+type conversation struct {
+	Tick   int64   // tick de fin de la conversation
+	With   string  // partenaire de discussion
+	Before float64 // opinion de l'agent avant la conversation
+	After  float64 // opinion de l'agent après la conversation
+}
+
+// Sink d'évènements qui conserve l'historique des conversations de chaque agent
+type conversationLog struct {
+	mutex   sync.Mutex
+	pending map[string]events.Event   // dernière variation d'opinion due à une discussion, par agent
+	byAgent map[string][]conversation // conversations par agent, de la plus récente à la plus ancienne
+}
+
+// Création d'un nouvel historique de conversations
+func newConversationLog() *conversationLog {
+	return &conversationLog{
+		pending: make(map[string]events.Event),
+		byAgent: make(map[string][]conversation),
+	}
+}
+
+// Fonction qui reçoit les évènements: les variations d'opinion précèdent toujours la fin de la discussion
+func (c *conversationLog) Write(e events.Event) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	switch e.Kind {
+	case events.OpinionChange:
+		if e.Other != "" {
+			c.pending[e.Agent] = e
+		}
+	case events.DiscussionEnd:
+		c.add(e.Agent, e.Other, e.Tick)
+		c.add(e.Other, e.Agent, e.Tick)
+	}
+	return nil
+}
+
+// Fonction qui rajoute une conversation à l'historique d'un agent
+func (c *conversationLog) add(agent, with string, tick int64) {
+	conv := conversation{Tick: tick, With: with}
+	if delta, ok := c.pending[agent]; ok && delta.Other == with {
+		conv.Before, conv.After = delta.Before, delta.After
+		delete(c.pending, agent)
+	}
+
+	history := append([]conversation{conv}, c.byAgent[agent]...)
+	if len(history) > ConversationsMax {
+		history = history[:ConversationsMax]
+	}
+	c.byAgent[agent] = history
+}
+
+// Fonction qui renvoie une copie de l'historique des conversations d'un agent
+func (c *conversationLog) History(agent ag.IdAgent) []conversation {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return append([]conversation(nil), c.byAgent[string(agent)]...)
+}
+
+// Fonction qui ne fait rien: l'historique est conservé en mémoire
+func (c *conversationLog) Close() error { return nil }
+
+// Fonction qui enregistre l'opinion de chaque agent pour les courbes du panneau d'informations
+func (sim *Simulation) recordOpinionHistory() {
+	if sim.env.Tick()%OpinionHistoryEvery != 0 {
+		return
+	}
+	for _, agent := range sim.agents {
+		history := append(sim.opinionHistory[agent.Id], agent.Opinion)
+		if len(history) > OpinionHistoryMax {
+			history = history[len(history)-OpinionHistoryMax:]
+		}
+		sim.opinionHistory[agent.Id] = history
+	}
+}
+
+// Fonction qui traite le défilement et le changement d'onglet du panneau d'informations
+func (sim *Simulation) handlePanelInput() {
+	panel := &sim.panel
+	cursorX, cursorY := ebiten.CursorPosition()
+
+	// Changement d'onglet au clavier ou en cliquant sur un onglet
+	if inpututil.IsKeyJustPressed(ebiten.KeyTab) {
+		panel.setTab(PanelTab((int(panel.tab) + 1) % len(panelTabs)))
+	}
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		for i, rect := range panel.tabRects {
+			if image.Pt(cursorX, cursorY).In(rect) {
+				panel.setTab(panelTabs[i])
+			}
+		}
+	}
+
+	// Défilement à la molette lorsque le curseur est sur le panneau, ou au clavier
+	if cursorX < PanelWidth {
+		_, wheelY := ebiten.Wheel()
+		panel.scroll -= int(wheelY * PanelScrollStep)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyPageDown) || inpututil.IsKeyJustPressed(ebiten.KeyArrowDown) {
+		panel.scroll += PanelScrollStep
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyPageUp) || inpututil.IsKeyJustPressed(ebiten.KeyArrowUp) {
+		panel.scroll -= PanelScrollStep
+	}
+
+	// Le défilement est limité à la hauteur du contenu
+	visible := sim.camera.height - 20 - panel.tabsY - 2*PanelLineHeight
+	panel.scroll = max(0, min(panel.scroll, panel.contentHeight-visible))
+}
+
+// Fonction qui change l'onglet affiché et revient en haut du contenu
+func (p *infoPanel) setTab(tab PanelTab) {
+	if p.tab != tab {
+		p.tab = tab
+		p.scroll = 0
+	}
+}
+
+// Fonction qui affiche les informations de la simulation (nombre d'agents, temps écoulé...) dans un cadre de la fenêtre d'affichage
+func (sim *Simulation) drawInfoPanel(screen *ebiten.Image) {
+	panelX, panelY := 0, 0
+	panelHeight := screen.Bounds().Dy() - 20
+	padding := PanelPadding
+
+	// Dessine le panneau de fond
+	vector.DrawFilledRect(screen, float32(panelX), float32(panelY), float32(PanelWidth), float32(panelHeight), color.RGBA{0, 0, 0, 180}, false)
+
+	// Titre du panneau
+	ebitenutil.DebugPrintAt(screen, "Informations de la simulation", panelX+padding, panelY+padding)
+
+	y := panelY + 30
+
+	// Informations de la simulation: temps simulé écoulé et restant
+	tick := sim.env.Tick()
+	elapsed := time.Duration(tick) * time.Second / TicksPerSecond
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Temps écoulé: %s (tick %d)", elapsed.Round(time.Second), tick), panelX+padding, y)
+	y += PanelLineHeight
+	remaining := fmt.Sprintf("Temps restant: %s", sim.clock.remaining(tick).Round(time.Second))
+	if sim.clock.paused {
+		remaining += " (pause)"
+	}
+	ebitenutil.DebugPrintAt(screen, remaining, panelX+padding, y)
+	y += 30
+
+	// Nombre d'agents par type
+	ebitenutil.DebugPrintAt(screen, "Nombre d'agents:", panelX+padding, y)
+	y += 20
+	agentTypes := []ag.TypeAgent{ag.Sceptic, ag.Believer, ag.Neutral}
+	for _, agentType := range agentTypes {
+		count, _ := sim.env.NbrAgents.Load(agentType)
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("  %s: %d", agentType, count), panelX+padding, y)
+		y += PanelLineHeight
+	}
+	y += 10
+
+	// Comptage des ordinateurs par programme None ou Go
+	ebitenutil.DebugPrintAt(screen, "Nombre d'ordinateurs:", panelX+padding, y)
+	y += 20
+	computerTypes := []ag.Programm{ag.NoPgm, ag.GoPgm}
+	pcs := sim.env.Objs
+	for _, computerType := range computerTypes {
+		count := 0
+		for _, pc := range pcs {
+			if pc.GetType() != ag.ComputerType {
+				continue
+			}
+			if pc.GetProgramm() == computerType {
+				count++
+			}
+		}
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("  %s: %d", computerType, count), panelX+padding, y)
+		y += PanelLineHeight
+	}
+	y += 10
+
+	// Barre d'onglets
+	sim.drawPanelTabs(screen, y)
+	y += 2 * PanelLineHeight
+
+	// Contenu défilant de l'onglet: on dessine dans une sous-image pour découper ce qui dépasse
+	area := image.Rect(panelX, y, PanelWidth, panelHeight)
+	content := screen.SubImage(area).(*ebiten.Image)
+	top := y - sim.panel.scroll
+
+	var height int
+	switch sim.panel.tab {
+	case OpinionTab:
+		height = sim.drawOpinionTab(content, top)
+	default:
+		lines := sim.panelLines()
+		for i, line := range lines {
+			ebitenutil.DebugPrintAt(content, line, panelX+padding, top+i*PanelLineHeight)
+		}
+		height = len(lines) * PanelLineHeight
+	}
+	sim.panel.contentHeight = height
+
+	// Barre de défilement
+	if height > area.Dy() {
+		barHeight := float32(area.Dy()) * float32(area.Dy()) / float32(height)
+		barY := float32(area.Min.Y) + float32(sim.panel.scroll)*float32(area.Dy())/float32(height)
+		vector.DrawFilledRect(screen, float32(PanelWidth-4), barY, 3, barHeight, color.RGBA{200, 200, 200, 200}, false)
+	}
+}
+
+// Fonction d'affichage de la barre d'onglets du panneau d'informations
+func (sim *Simulation) drawPanelTabs(screen *ebiten.Image, y int) {
+	sim.panel.tabsY = y
+	sim.panel.tabRects = sim.panel.tabRects[:0]
+
+	x := PanelPadding
+	for _, tab := range panelTabs {
+		label := tab.String()
+		width := len([]rune(label))*6 + 6
+		rect := image.Rect(x-3, y-2, x-3+width, y+PanelLineHeight)
+
+		bg := color.RGBA{60, 60, 60, 255}
+		if tab == sim.panel.tab {
+			bg = color.RGBA{120, 120, 40, 255}
+		}
+		vector.DrawFilledRect(screen, float32(rect.Min.X), float32(rect.Min.Y), float32(rect.Dx()), float32(rect.Dy()), bg, false)
+		ebitenutil.DebugPrintAt(screen, label, x, y)
+
+		sim.panel.tabRects = append(sim.panel.tabRects, rect)
+		x += width + 2
+	}
+}
+
+// Fonction qui renvoie les lignes de texte de l'onglet courant
+func (sim *Simulation) panelLines() []string {
+	if sim.panel.tab == OverviewTab {
+		return sim.overviewLines()
+	}
+
+	if sim.selected == nil {
+		return []string{"Aucun agent sélectionné."}
+	}
+
+	switch sim.panel.tab {
+	case RelationsTab:
+		return sim.relationLines()
+	case WeightsTab:
+		return sim.weightLines()
+	case ConversationsTab:
+		return sim.conversationLines()
+	}
+	return nil
+}
+
+// Fonction qui renvoie le nom et le type d'une zone pour l'affichage
+func zoneLabel(zone *carte.Zone) string {
+	if zone == nil {
+		return "aucune"
+	}
+	if zone.Name == zone.Kind {
+		return zone.Name
+	}
+	return fmt.Sprintf("%s (%s)", zone.Name, zone.Kind)
+}
+
+// Fonction qui renvoie les informations générales de l'agent ou de l'ordinateur sélectionné
+func (sim *Simulation) overviewLines() []string {
+	lines := make([]string, 0)
+
+	// Informations de l'agent sélectionné
+	if sim.selected != nil {
+		lines = append(lines,
+			"Agent sélectionné:",
+			fmt.Sprintf("  ID: %s", sim.selected.Id),
+			fmt.Sprintf("  Type: %s", sim.selected.TypeAgt),
+			fmt.Sprintf("  Sous-Type: %s", sim.selected.SubType),
+			fmt.Sprintf("  Paramètre Personnel: %.2f", sim.selected.PersonalParameter),
+			fmt.Sprintf("  Opinion: %.2f", sim.selected.Opinion),
+			fmt.Sprintf("  Vivant: %t", sim.selected.Vivant),
+			fmt.Sprintf("  Temps de Dialogue: %d", sim.selected.DialogTimer),
+			fmt.Sprintf("  Action: %s", sim.selected.CurrentAction),
+			fmt.Sprintf("  Zone: %s", zoneLabel(sim.selected.Zone())),
+			fmt.Sprintf("  Stratégie de mouvement: %s", sim.selected.MovementStrategy),
+			fmt.Sprintf("  Occupé : %t", sim.selected.Occupied),
+			fmt.Sprintf("  Dernière prière : %.2f", float64(sim.env.Tick()-sim.selected.TickLastStatue)/TicksPerSecond),
+			"",
+		)
+
+		// Informations sur la discussion actuelle
+		if sim.selected.DiscussingWith != nil {
+			lines = append(lines,
+				"  En discussion avec:",
+				fmt.Sprintf("  ID: %s", sim.selected.DiscussingWith.Id),
+				fmt.Sprintf("  Type: %s", sim.selected.DiscussingWith.TypeAgt),
+				"",
+			)
+		}
+
+		// Derniers interlocuteurs dont l'agent se souvient
+		lines = append(lines, "  Dernières conversations avec:")
+		for i, lastTalked := range sim.selected.LastTalkedTo {
+			lines = append(lines, fmt.Sprintf("    %d. %s (%s)", i+1, lastTalked.Id, lastTalked.TypeAgt))
+		}
+	}
+
+	// Informations de l'ordinateur sélectionné
+	if sim.selectedPC != nil {
+		lines = append(lines,
+			"Ordinateur sélectionné:",
+			fmt.Sprintf("  ID: %s", sim.selectedPC.Id),
+			fmt.Sprintf("  En utilisation: %t", sim.selectedPC.Used),
+			fmt.Sprintf("  Langage de programmation: %s", sim.selectedPC.Programm),
+		)
+	}
+
+	if len(lines) == 0 {
+		lines = append(lines, "Cliquez sur un agent ou un", "ordinateur pour le sélectionner.")
+	}
+
+	// Occupation et opinion moyenne des zones de la carte
+	if zones := metrics.MeasureZones(sim.agents, sim.env.Carte.Zones); len(zones) > 0 {
+		lines = append(lines, "", "Zones:")
+		for _, zone := range zones {
+			if zone.Agents == 0 {
+				lines = append(lines, fmt.Sprintf("  %s: vide", zone.Name))
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("  %s: %d agents, opinion %.2f", zone.Name, zone.Agents, zone.Mean))
+		}
+	}
+	lines = append(lines, "", "[Tab] onglet suivant", "[Molette/PgUp/PgDn] défilement")
+	return lines
+}
+
+// Fonction qui renvoie les relations de l'agent sélectionné, de la plus forte à la plus faible
+func (sim *Simulation) relationLines() []string {
+	keys := make([]ag.IdAgent, 0, len(sim.selected.Relation))
+	for otherId := range sim.selected.Relation {
+		if otherId != sim.selected.Id {
+			keys = append(keys, otherId)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		ri, rj := sim.selected.Relation[keys[i]], sim.selected.Relation[keys[j]]
+		if ri == rj {
+			return keys[i] < keys[j]
+		}
+		return ri > rj
+	})
+
+	lines := []string{fmt.Sprintf("Relations de %s (%d):", sim.selected.Id, len(keys))}
+	for _, otherId := range keys {
+		relation := sim.selected.Relation[otherId]
+		lines = append(lines, fmt.Sprintf("  %s: %.2f %s", otherId, relation, getRelationType(relation)))
+	}
+	return lines
+}
+
+// Fonction qui renvoie les poids absolus et relatifs de l'agent sélectionné, du plus fort au plus faible
+func (sim *Simulation) weightLines() []string {
+	self := sim.selected.Id
+	keys := make([]ag.IdAgent, 0, len(sim.selected.Poids_abs))
+	for otherId := range sim.selected.Poids_abs {
+		if otherId != self {
+			keys = append(keys, otherId)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		wi, wj := sim.selected.Poids_abs[keys[i]], sim.selected.Poids_abs[keys[j]]
+		if wi == wj {
+			return keys[i] < keys[j]
+		}
+		return wi > wj
+	})
+
+	lines := []string{
+		fmt.Sprintf("Confiance en soi: %.4f", sim.selected.Poids_abs[self]),
+		"",
+		"Agent    abs     rel(soi) rel(autre)",
+	}
+	for _, otherId := range keys {
+		rel := sim.selected.Poids_rel[otherId]
+		lines = append(lines, fmt.Sprintf("%-8s %.4f  %.3f    %.3f", otherId, sim.selected.Poids_abs[otherId], rel.First, rel.Second))
+	}
+	return lines
+}
+
+// Fonction qui renvoie l'historique des conversations de l'agent sélectionné
+func (sim *Simulation) conversationLines() []string {
+	history := sim.conversations.History(sim.selected.Id)
+	lines := []string{fmt.Sprintf("Conversations de %s (%d):", sim.selected.Id, len(history))}
+	if len(history) == 0 {
+		lines = append(lines, "  Aucune conversation terminée.")
+	}
+	for _, conv := range history {
+		lines = append(lines, fmt.Sprintf("  t%-6d %-8s %+.3f", conv.Tick, conv.With, conv.After-conv.Before))
+	}
+	return append(lines, sim.receivedInfluenceLines()...)
+}
+
+// Fonction qui renvoie les lignes de l'influence reçue par l'agent sélectionné, de la source la plus influente à la moins influente
+func (sim *Simulation) receivedInfluenceLines() []string {
+	received := sim.influence.Received(sim.selected.Id)
+	lines := []string{"", "Influences reçues:"}
+	if len(received) == 0 {
+		return append(lines, "  Aucune.")
+	}
+	for _, contribution := range received[:min(len(received), ReceivedInfluenceMax)] {
+		source := contribution.ID
+		if source == "" {
+			source = string(contribution.Kind)
+		}
+		lines = append(lines, fmt.Sprintf("  %-10s %+.3f (%d)", source, contribution.Delta, contribution.Changes))
+	}
+	return lines
+}
+
+// Fonction qui dessine la courbe de l'opinion de l'agent sélectionné et renvoie la hauteur utilisée
+func (sim *Simulation) drawOpinionTab(screen *ebiten.Image, top int) int {
+	x := PanelPadding
+	if sim.selected == nil {
+		ebitenutil.DebugPrintAt(screen, "Aucun agent sélectionné.", x, top)
+		return PanelLineHeight
+	}
+
+	history := sim.opinionHistory[sim.selected.Id]
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Opinion de %s: %.3f", sim.selected.Id, sim.selected.Opinion), x, top)
+
+	// Cadre de la courbe et seuils des types d'agents
+	chartX, chartY := float32(x), float32(top+2*PanelLineHeight)
+	chartW, chartH := float32(PanelWidth-2*PanelPadding), float32(120)
+	vector.DrawFilledRect(screen, chartX, chartY, chartW, chartH, color.RGBA{30, 30, 30, 255}, false)
+	for _, threshold := range []float32{1. / 3., 2. / 3.} {
+		ty := chartY + chartH*(1-threshold)
+		vector.StrokeLine(screen, chartX, ty, chartX+chartW, ty, 1, color.RGBA{90, 90, 90, 255}, false)
+	}
+
+	// Courbe de l'opinion
+	for i := 1; i < len(history); i++ {
+		x0 := chartX + chartW*float32(i-1)/float32(len(history)-1)
+		x1 := chartX + chartW*float32(i)/float32(len(history)-1)
+		y0 := chartY + chartH*float32(1-history[i-1])
+		y1 := chartY + chartH*float32(1-history[i])
+		vector.StrokeLine(screen, x0, y0, x1, y1, 1.5, color.RGBA{255, 255, 0, 255}, true)
+	}
+
+	y := int(chartY+chartH) + 10
+	if len(history) > 0 {
+		lo, hi := history[0], history[0]
+		for _, o := range history {
+			lo, hi = min(lo, o), max(hi, o)
+		}
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("min %.3f  max %.3f", lo, hi), x, y)
+		y += PanelLineHeight
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("%d points (1 tous les %d ticks)", len(history), OpinionHistoryEvery), x, y)
+		y += PanelLineHeight
+	}
+	return y - top
+}
@@ -0,0 +1,287 @@
+package simulation
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+
+	ag "github.com/Tmegaa/The-Gophecy/pkg/Agent"
+	capture "github.com/Tmegaa/The-Gophecy/pkg/Capture"
+	carte "github.com/Tmegaa/The-Gophecy/pkg/Carte"
+	trace "github.com/Tmegaa/The-Gophecy/pkg/Trace"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Constantes du lecteur de traces
+const (
+	ReplayBarHeight = 40   // hauteur de la barre de contrôle
+	ReplayMinSpeed  = 0.25 // vitesse de lecture minimale
+	ReplayMaxSpeed  = 16   // vitesse de lecture maximale
+)
+
+// Options de capture du lecteur de traces
+type CaptureOptions struct {
+	Format capture.Format // format de l'enregistrement (png ou gif)
+	Every  int            // période des captures (en ticks)
+	Dir    string         // dossier où sont écrites les captures
+}
+
+// Lecteur de traces: rejoue une simulation enregistrée avec le même affichage que la simulation
+type Replay struct {
+	sim       *Simulation             // simulation utilisée pour l'affichage (carte, agents, police)
+	header    trace.Header            // en-tête de la trace
+	frames    []trace.Frame           // images de la trace
+	current   int                     // index de l'image affichée
+	playing   bool                    // indique si la lecture est en cours
+	speed     float64                 // nombre d'images avancées par tick d'affichage
+	progress  float64                 // avancement fractionnaire vers l'image suivante
+	agents    map[string]*ag.Agent    // agents reconstruits à partir de la trace
+	computers map[string]*ag.Computer // ordinateurs de la carte
+}
+
+// Fonction qui charge une trace et prépare son affichage
+func NewReplay(path string, options CaptureOptions) (*Replay, error) {
+	header, frames, err := trace.Read(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("la trace %s ne contient aucune image", path)
+	}
+
+	initializeWindow()
+	ebiten.SetWindowTitle("Simulation - Replay")
+
+	mapPath := header.Map
+	if mapPath == "" {
+		mapPath = MapsPath + TilemapJSONFile
+	}
+	carte, err := carte.LoadCarte(mapPath)
+	if err != nil {
+		return nil, err
+	}
+	env := ag.NewEnvironment(make([]*ag.Agent, 0), carte, make([]ag.InterfaceObjet, 0))
+	objets := loadObjects(env)
+
+	r := &Replay{
+		sim: &Simulation{
+			env:        env,
+			objets:     objets,
+			carte:      carte,
+			tilesets:   loadTilesets(&carte.TilemapJSON),
+			dialogFont: loadDialogFont(),
+			camera:     newCamera(),
+			sprites:    newSpriteRegistry(),
+			capture:    newScreenCapture(options.Format, options.Every, options.Dir),
+		},
+		header:    header,
+		frames:    frames,
+		playing:   true,
+		speed:     1,
+		agents:    make(map[string]*ag.Agent),
+		computers: make(map[string]*ag.Computer),
+	}
+	for _, obj := range objets {
+		if computer, ok := obj.(*ag.Computer); ok {
+			r.computers[string(computer.Id)] = computer
+		}
+	}
+	r.apply(r.frames[0])
+	return r, nil
+}
+
+// Fonction qui met à jour les agents et les ordinateurs affichés à partir d'une image de la trace
+func (r *Replay) apply(frame trace.Frame) {
+	agents := make([]*ag.Agent, 0, len(frame.Agents))
+	for _, state := range frame.Agents {
+		agent, ok := r.agents[state.Id]
+		if !ok {
+			agent = &ag.Agent{Env: r.sim.env, Id: ag.IdAgent(state.Id), Vivant: true}
+			r.agents[state.Id] = agent
+		}
+		agent.Position.X = state.X
+		agent.Position.Y = state.Y
+		agent.Opinion = state.Opinion
+		agent.TypeAgt = ag.TypeAgent(state.Type)
+		agent.SubType = ag.SubTypeAgent(state.SubType)
+		agent.CurrentAction = ag.ActionType(state.Action)
+		agent.DialogTimer = state.Timer
+		agents = append(agents, agent)
+	}
+
+	// Les partenaires de discussion sont résolus une fois tous les agents connus
+	for _, state := range frame.Agents {
+		r.agents[state.Id].DiscussingWith = r.agents[state.With]
+	}
+
+	for _, state := range frame.Computers {
+		if computer, ok := r.computers[state.Id]; ok {
+			computer.Programm = ag.Programm(state.Program)
+			computer.Used = state.Used
+		}
+	}
+
+	r.sim.agents = agents
+	r.sim.env.Ags = agents
+}
+
+// Fonction qui affiche l'image d'index donné
+func (r *Replay) seek(idx int) {
+	idx = max(0, min(len(r.frames)-1, idx))
+	if idx != r.current {
+		r.current = idx
+		r.apply(r.frames[idx])
+	}
+}
+
+// Fonction de mise à jour du lecteur: traite les commandes et avance la lecture
+func (r *Replay) Update() error {
+	// Lecture et pause
+	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+		r.playing = !r.playing
+		if r.current == len(r.frames)-1 {
+			r.seek(0)
+		}
+	}
+
+	// Avance ou recule d'une image
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowRight) {
+		r.playing = false
+		r.seek(r.current + 1)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft) {
+		r.playing = false
+		r.seek(r.current - 1)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyHome) {
+		r.seek(0)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnd) {
+		r.seek(len(r.frames) - 1)
+	}
+
+	// Vitesse de lecture
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowUp) {
+		r.speed = min(ReplayMaxSpeed, r.speed*2)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowDown) {
+		r.speed = max(ReplayMinSpeed, r.speed/2)
+	}
+
+	// Déplacement dans la trace en cliquant sur la barre de progression
+	_, cursorY := ebiten.CursorPosition()
+	onBar := cursorY >= r.sim.camera.height-ReplayBarHeight
+	if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) && onBar {
+		x, _ := ebiten.CursorPosition()
+		r.seek(x * len(r.frames) / r.sim.camera.width)
+	}
+
+	// Déplacement et zoom de la caméra
+	r.sim.camera.handleInput(!onBar)
+	r.sim.capture.handleInput(r.frames[r.current].Tick)
+
+	if r.playing {
+		r.progress += r.speed
+		for r.progress >= 1 {
+			r.progress--
+			if r.current == len(r.frames)-1 {
+				r.playing = false
+				r.progress = 0
+				break
+			}
+			r.seek(r.current + 1)
+		}
+	}
+	return nil
+}
+
+// Fonction d'affichage du lecteur
+func (r *Replay) Draw(screen *ebiten.Image) {
+	screen.Fill(color.RGBA{57, 61, 125, 255})
+	r.sim.drawWorld(screen, func(world *ebiten.Image) {
+		r.sim.drawMap(world)
+		r.sim.drawAgents(world)
+	})
+	// La barre de contrôle n'apparaît pas sur les captures
+	r.sim.capture.capture(screen, r.frames[r.current].Tick)
+	r.drawControls(screen)
+	r.sim.capture.draw(screen)
+}
+
+// Fonction d'affichage de la barre de contrôle du lecteur
+func (r *Replay) drawControls(screen *ebiten.Image) {
+	width := float32(screen.Bounds().Dx())
+	y := float32(screen.Bounds().Dy() - ReplayBarHeight)
+	vector.DrawFilledRect(screen, 0, y, width, ReplayBarHeight, color.RGBA{0, 0, 0, 200}, false)
+
+	// Barre de progression
+	progress := float32(1)
+	if len(r.frames) > 1 {
+		progress = float32(r.current) / float32(len(r.frames)-1)
+	}
+	vector.DrawFilledRect(screen, 0, y, width*progress, 6, color.RGBA{255, 255, 0, 255}, false)
+
+	state := "Pause"
+	if r.playing {
+		state = "Lecture"
+	}
+	info := fmt.Sprintf("%s - tick %d / %d - vitesse x%.2f    [Espace] lecture/pause  [Gauche/Droite] image par image  [Haut/Bas] vitesse  [Début/Fin] aller au début/à la fin  [Clic] se déplacer",
+		state, r.frames[r.current].Tick, r.frames[len(r.frames)-1].Tick, r.speed)
+	ebitenutil.DebugPrintAt(screen, info, 10, int(y)+14)
+}
+
+// Fonction qui retourne les dimentions de la fenêtre d'affichage
+func (r *Replay) Layout(outsideWidth, outsideHeight int) (int, int) {
+	return r.sim.camera.layout(outsideWidth, outsideHeight)
+}
+
+// Fonction qui ouvre la fenêtre et rejoue la trace
+func (r *Replay) Run() error {
+	err := ebiten.RunGame(r)
+	if stopErr := r.sim.capture.stop(); stopErr != nil {
+		log.Printf("Failed to save recording: %v", stopErr)
+	}
+	return err
+}
+
+// Fonction qui rend une trace sans fenêtre et enregistre ses images (PNG ou GIF), par exemple pour une présentation
+func RenderTrace(path string, options CaptureOptions) error {
+	header, frames, err := trace.Read(path)
+	if err != nil {
+		return err
+	}
+	if len(frames) == 0 {
+		return fmt.Errorf("la trace %s ne contient aucune image", path)
+	}
+
+	mapPath := header.Map
+	if mapPath == "" {
+		mapPath = MapsPath + TilemapJSONFile
+	}
+	offscreen, err := newOffscreenRenderer(mapPath)
+	if err != nil {
+		return err
+	}
+
+	// Les agents sont reconstruits comme dans le lecteur, sans environnement ni images Ebiten
+	r := &Replay{
+		sim:       &Simulation{capture: newScreenCapture(options.Format, options.Every, options.Dir)},
+		agents:    make(map[string]*ag.Agent),
+		computers: make(map[string]*ag.Computer),
+	}
+	if err := r.sim.capture.start(frames[0].Tick); err != nil {
+		return err
+	}
+	for _, frame := range frames {
+		if !r.sim.capture.due(frame.Tick) {
+			continue
+		}
+		r.apply(frame)
+		r.sim.capture.add(offscreen.render(r.sim.agents, fmt.Sprintf("tick %d", frame.Tick)), frame.Tick)
+	}
+	return r.sim.capture.stop()
+}
@@ -0,0 +1,99 @@
+package simulation
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	ag "github.com/Tmegaa/The-Gophecy/pkg/Agent"
+	influence "github.com/Tmegaa/The-Gophecy/pkg/Influence"
+	report "github.com/Tmegaa/The-Gophecy/pkg/Report"
+)
+
+// Nombre d'agents influents affichés dans le rapport et sur l'écran de fin
+const TopInfluencers = 10
+
+// Fonction qui renvoie les paramètres de la simulation à afficher dans le rapport
+func (sim *Simulation) reportFields() []report.Field {
+	config := sim.config
+	fields := make([]report.Field, 0)
+	switch {
+	case config.SnapshotPath != "":
+		fields = append(fields, report.Field{Name: "Sauvegarde reprise", Value: config.SnapshotPath})
+	case config.AgentsFilePath != "":
+		fields = append(fields, report.Field{Name: "Fichier des agents", Value: config.AgentsFilePath})
+	default:
+		fields = append(fields, report.Field{Name: "Nombre d'agents", Value: strconv.Itoa(config.NumAgents)})
+		if config.NumBelievers+config.NumSceptics+config.NumNeutrals > 0 {
+			fields = append(fields,
+				report.Field{Name: "Croyants", Value: strconv.Itoa(config.NumBelievers)},
+				report.Field{Name: "Sceptiques", Value: strconv.Itoa(config.NumSceptics)},
+				report.Field{Name: "Neutres", Value: strconv.Itoa(config.NumNeutrals)},
+			)
+		}
+		fields = append(fields,
+			report.Field{Name: "Mouvement des croyants", Value: config.BelieverMovement.String()},
+			report.Field{Name: "Mouvement des sceptiques", Value: config.ScepticMovement.String()},
+			report.Field{Name: "Mouvement des neutres", Value: config.NeutralMovement.String()},
+		)
+	}
+	model := sim.env.OpinionModel
+	if model == "" {
+		model = ag.GophecyModel
+	}
+	fields = append(fields,
+		report.Field{Name: "Durée prévue", Value: config.SimulationTime.String()},
+		report.Field{Name: "Modèle d'opinion", Value: string(model)},
+		report.Field{Name: "Carte", Value: config.MapFile},
+		report.Field{Name: "Période d'échantillonnage", Value: strconv.Itoa(sim.metrics.Every) + " ticks"},
+		report.Field{Name: "Graine", Value: strconv.FormatInt(config.Seed, 10)},
+	)
+	return fields
+}
+
+// Fonction qui renvoie les agents les plus influents, accompagnés de leur type final
+func (sim *Simulation) topInfluencers(n int) []report.Influencer {
+	return sim.influencers(sim.influence.Top(n))
+}
+
+// Fonction qui complète des scores d'influence avec le type final des agents
+func (sim *Simulation) influencers(scores []influence.Score) []report.Influencer {
+	byId := make(map[string]*ag.Agent, len(sim.agents))
+	for _, agent := range sim.agents {
+		byId[string(agent.Id)] = agent
+	}
+
+	influencers := make([]report.Influencer, 0, len(scores))
+	for _, score := range scores {
+		influencer := report.Influencer{Score: score}
+		if agent, ok := byId[score.ID]; ok {
+			influencer.Type, influencer.SubType = agent.TypeAgt, agent.SubType
+		}
+		influencers = append(influencers, influencer)
+	}
+	return influencers
+}
+
+// Fonction qui écrit le rapport HTML de la simulation dans le dossier des résultats
+func (sim *Simulation) writeReport() error {
+	sim.reportPath = filepath.Join(sim.outputDir, report.FileName)
+	var graph strings.Builder
+	if err := sim.influence.WriteDOT(&graph); err != nil {
+		return err
+	}
+	return report.Write(sim.reportPath, report.Data{
+		Title:       "Rapport de simulation — The Gophecy",
+		Seed:        sim.config.Seed,
+		TPS:         TicksPerSecond,
+		Config:      sim.reportFields(),
+		Summary:     *sim.summary,
+		Recorder:    sim.metrics,
+		Influencers: sim.topInfluencers(TopInfluencers),
+		Programs:    sim.programs.Changes(),
+		Tactics:     sim.influence.Tactics(),
+		Converters:  sim.influencers(sim.influence.Ranking(ag.Converter, TopInfluencers)),
+		Pirates:     sim.influencers(sim.influence.Ranking(ag.Pirate, TopInfluencers)),
+		Conversions: sim.influence.Conversions(),
+		Graph:       graph.String(),
+	})
+}
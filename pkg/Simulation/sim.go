@@ -1,115 +1,220 @@
 package simulation
 
 import (
-	"context"
-	"encoding/json"
 	"fmt"
 	"image"
 	"image/color"
-	"io/ioutil"
 	"log"
-	"math/rand"
 	"os"
-	"sort"
+	"path/filepath"
 	"time"
 
+	"github.com/Tmegaa/The-Gophecy/assets"
 	ag "github.com/Tmegaa/The-Gophecy/pkg/Agent"
 	carte "github.com/Tmegaa/The-Gophecy/pkg/Carte"
+	events "github.com/Tmegaa/The-Gophecy/pkg/Events"
+	influence "github.com/Tmegaa/The-Gophecy/pkg/Influence"
+	metrics "github.com/Tmegaa/The-Gophecy/pkg/Metrics"
+	report "github.com/Tmegaa/The-Gophecy/pkg/Report"
 	tile "github.com/Tmegaa/The-Gophecy/pkg/Tile"
+	trace "github.com/Tmegaa/The-Gophecy/pkg/Trace"
 	ut "github.com/Tmegaa/The-Gophecy/pkg/Utilitaries"
 
 	"github.com/golang/freetype/truetype"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/text"
 	"github.com/hajimehoshi/ebiten/v2/vector"
-	"github.com/wcharczuk/go-chart/v2"
 	"golang.org/x/image/font"
 	"golang.org/x/image/font/gofont/goregular"
 )
 
 // Constantes de la simulation
 const (
-	TileSize               = 24
+	TileSize               = carte.TileSize
 	AgentImageSize         = 16
 	WindowWidth            = 1920
 	WindowHeight           = 1080
-	NumComputers           = 6
-	NumStatues             = 1
 	AssetsPath             = "assets/images/"
-	MapsPath               = "assets/maps/"
+	MapsPath               = carte.MapsPath
 	AgentBelieverImageFile = "ninja.png"
 	AgentScepticImageFile  = "sceptic.png"
 	AgentNeutralImageFile  = "neutre.png"
-	TilemapImage           = "img.png"
 	TilemapJSONFile        = "spawn.json"
 	DiscussionBubbleWidth  = 100
 	DiscussionBubbleHeight = 40
 	ProbabilityConverter   = 0.2
 	ProbabilityPirate      = 0.15
+	HistogramBins          = 10
+	TicksPerSecond         = 60
 )
 
 type Simulation struct {
 	env                *ag.Environnement
 	agents             []*ag.Agent
 	objets             []ag.InterfaceObjet
-	maxDuration        time.Duration
 	start              time.Time
+	clock              simClock
+	camera             camera
+	world              *ebiten.Image // image hors écran dans laquelle le monde est dessiné avant de passer par la caméra
+	overlays           overlays
+	discussionDensity  []int // nombre de ticks de discussion par case de la carte
+	liveCharts         liveCharts
+	editor             editor
+	summary            *metrics.Summary // résumé calculé à la fin de la simulation
+	influence          *influence.Ledger
+	programs           *report.ProgramLog
+	reportPath         string // chemin du rapport HTML écrit à la fin de la simulation
+	capture            screenCapture
+	offscreen          *offscreenRenderer // rendu sans fenêtre pour les captures de la simulation sans affichage
 	carte              *carte.Carte
+	tilesets           []*tilesetImages // images des jeux de tiles de la carte
 	selected           *ag.Agent
 	selectedPC         *ag.Computer
-	ctx                context.Context
-	cancel             context.CancelFunc
 	dialogFont         font.Face
 	selectionIndicator *ebiten.Image
-	opinionAverages    []float64
+	sprites            *spriteRegistry // images des agents, chargées une fois par type
+	metrics            *metrics.Recorder
+	outputDir          string
+	config             SimulationConfig
+	trace              *trace.Writer
+	panel              infoPanel
+	opinionHistory     map[ag.IdAgent][]float64
+	conversations      *conversationLog
+	snapshotRequested  bool // une sauvegarde est demandée: elle est enregistrée entre deux ticks
 }
 
 // Fonction qui initialize une nouvelle simulation
-func NewSimulation(config SimulationConfig) *Simulation {
-	initializeWindow()
-	carte := loadMap()
-	env := createEnvironment(carte)
-	var agents []*ag.Agent
-	var err error
+func NewSimulation(config SimulationConfig) (*Simulation, error) {
+	if config.MapFile == "" {
+		config.MapFile = TilemapJSONFile
+	}
 
-	if config.AgentsFilePath != "" {
-		// Load agents from file
-		agents, err = createAgentsFromFile(env, config.AgentsFilePath)
+	// Une sauvegarde est reprise sur la carte où elle a été enregistrée
+	var snap *ag.Snapshot
+	if config.SnapshotPath != "" {
+		var err error
+		if snap, err = ag.LoadSnapshot(config.SnapshotPath); err != nil {
+			return nil, fmt.Errorf("échec de la reprise de la sauvegarde: %v", err)
+		}
+		config.MapFile = snap.Map
+	}
+
+	path := carte.MapPath(config.MapFile)
+	carte, err := carte.LoadCarte(path)
+	if err != nil {
+		return nil, err
+	}
+	var env *ag.Environnement
+	var agents []*ag.Agent
+	var obj []ag.InterfaceObjet
+	bus, err := createEventBus(config)
+	if err != nil {
+		return nil, err
+	}
+	conversations := newConversationLog()
+	bus.Attach(conversations)
+	ledger := influence.NewLedger()
+	bus.Attach(ledger)
+	programs := report.NewProgramLog()
+	bus.Attach(programs)
+
+	if config.SnapshotPath != "" {
+		// Reprise d'une simulation sauvegardée
+		env, err = restoreEnvironment(carte, snap, config)
 		if err != nil {
-			log.Fatalf("Failed to create agents from file: %v", err)
+			return nil, fmt.Errorf("échec de la reprise de la sauvegarde: %v", err)
 		}
+		env.Events = bus
+		agents = env.Ags
+		obj = env.Objs
 	} else {
-		// Create agents normally
-		agents = createAgents(env, carte, config)
+		env = createEnvironment(carte, &config)
+		env.Events = bus
+
+		if config.AgentsFilePath != "" {
+			// Load agents from file
+			agents, err = ag.CreateAgentsFromFile(env, config.AgentsFilePath)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			// Create agents normally
+			agents, err = createAgents(env, carte, config)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		obj = loadObjects(env)
 	}
 
-	obj := loadObjects(env)
-	ctx, cancel := context.WithTimeout(context.Background(), config.SimulationTime)
-	tt, err := truetype.Parse(goregular.TTF)
-	if err != nil {
-		log.Fatal(err)
+	// Sous-types initiaux des agents pour attribuer leur influence à une tactique
+	for _, agent := range agents {
+		ledger.Register(agent.Id, agent.SubType)
+	}
+
+	// Les objets ajoutés ou retirés depuis l'éditeur avant la sauvegarde restent affichés comme tels
+	editor := newEditor()
+	if snap != nil {
+		editor.restoreObjects(carte, obj)
 	}
 
 	selectionIndicator := ebiten.NewImage(TileSize, TileSize)
 	selectionIndicator.Fill(color.RGBA{255, 255, 0, 128})
 
+	// Trace de la simulation pour pouvoir la rejouer
+	var traceWriter *trace.Writer
+	if config.TracePath != "" {
+		traceWriter, err = trace.NewWriter(config.TracePath, trace.Header{
+			Map:  path,
+			Seed: config.Seed,
+			TPS:  TicksPerSecond,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("échec de la création de la trace: %v", err)
+		}
+	}
+
 	return &Simulation{
 		env:    env,
 		agents: agents,
 		objets: obj,
 		// maxStep:     10,
-		maxDuration: config.SimulationTime,
-		start:       time.Now(),
-		carte:       carte,
-		ctx:         ctx,
-		cancel:      cancel,
-		dialogFont: truetype.NewFace(tt, &truetype.Options{
-			Size: 12,
-			DPI:  72,
-		}),
+		start:              time.Now(),
+		clock:              newSimClock(env.Tick(), config.SimulationTime),
+		camera:             newCamera(),
+		overlays:           overlays{Acuite: true},
+		liveCharts:         liveCharts{Visible: true},
+		editor:             editor,
+		carte:              carte,
+		tilesets:           loadTilesets(&carte.TilemapJSON),
+		dialogFont:         loadDialogFont(),
 		selectionIndicator: selectionIndicator,
+		sprites:            newSpriteRegistry(),
+		metrics:            metrics.NewRecorder(config.MetricsEvery, HistogramBins),
+		outputDir:          config.OutputDir,
+		config:             config,
+		trace:              traceWriter,
+		opinionHistory:     make(map[ag.IdAgent][]float64),
+		conversations:      conversations,
+		influence:          ledger,
+		capture:            newScreenCapture(config.CaptureFormat, config.CaptureEvery, config.OutputDir),
+		programs:           programs,
+	}, nil
+}
+
+// Fonction qui charge la police utilisée dans les boîtes de dialogue
+func loadDialogFont() font.Face {
+	tt, err := truetype.Parse(goregular.TTF)
+	if err != nil {
+		log.Fatal(err)
 	}
+	return truetype.NewFace(tt, &truetype.Options{
+		Size: 12,
+		DPI:  72,
+	})
 }
 
 // Fonction qui initialise la fenêtre d'affichage de la simulation
@@ -119,28 +224,100 @@ func initializeWindow() {
 	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
 }
 
-// Fonction qui crée et retourne un nouvel environnement
-func createEnvironment(carte *carte.Carte) *ag.Environnement {
-	return ag.NewEnvironment(make([]*ag.Agent, 0), carte, make([]ag.InterfaceObjet, 0))
+// Fonction qui crée et retourne un nouvel environnement: une graine est tirée et rajoutée à la configuration si besoin
+func createEnvironment(carte *carte.Carte, config *SimulationConfig) *ag.Environnement {
+	if config.Seed == 0 {
+		config.Seed = time.Now().UnixNano()
+	}
+	log.Printf("Graine de la simulation: %d", config.Seed)
+
+	env := ag.NewEnvironment(make([]*ag.Agent, 0), carte, make([]ag.InterfaceObjet, 0))
+	env.Rng = ut.NewRng(uint64(config.Seed))
+	env.OpinionModel = config.OpinionModel
+	return env
+}
+
+// Fonction qui reconstruit l'environnement à partir de la sauvegarde, sur la carte qu'elle désigne
+func restoreEnvironment(carte *carte.Carte, snap *ag.Snapshot, config SimulationConfig) (*ag.Environnement, error) {
+	env, err := ag.RestoreEnvironment(snap, carte)
+	if err != nil {
+		return nil, err
+	}
+
+	// Une nouvelle graine permet de faire diverger plusieurs reprises d'une même sauvegarde
+	if config.Seed != 0 {
+		env.Rng = ut.NewRng(uint64(config.Seed))
+	}
+	return env, nil
 }
 
-// Fonction qui charge la carte
-func loadMap() *carte.Carte {
-	tilemapImg := loadImage(AssetsPath + TilemapImage)
-	tilemapJSON := loadTilemapJSON(MapsPath + TilemapJSONFile)
-	tilesets := loadTilesets(tilemapJSON)
-	coliders := generateColliders(tilemapJSON, tilesets)
-	computers := generateComputers(tilemapJSON, tilesets)
-	statues := generateStatues(tilemapJSON, tilesets)
+// Fonction qui renvoie le nom du fichier image associé à un type d'agent
+func agentImageFile(typeAgt ag.TypeAgent) string {
+	switch typeAgt {
+	case ag.Sceptic:
+		return AgentScepticImageFile
+	case ag.Neutral:
+		return AgentNeutralImageFile
+	default:
+		return AgentBelieverImageFile
+	}
+}
+
+// Fonction qui sauvegarde l'état complet de la simulation dans le dossier de résultats.
+// Elle est appelée entre deux ticks, pendant que les agents attendent, pour obtenir un état cohérent
+func (sim *Simulation) saveSnapshot() (string, error) {
+	sim.env.Lock()
+	snap, err := sim.env.Snapshot()
+	sim.env.Unlock()
+	if err != nil {
+		return "", err
+	}
+	snap.Map = carte.MapPath(sim.config.MapFile)
+
+	if err := os.MkdirAll(sim.outputDir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(sim.outputDir, fmt.Sprintf("snapshot_%d.json", snap.Tick))
+	return path, snap.Save(path)
+}
+
+// Fonction qui indique si des demandes de l'utilisateur (modifications de l'éditeur, sauvegarde) sont en attente
+func (sim *Simulation) hasRequests() bool {
+	return len(sim.editor.pending) > 0 || sim.snapshotRequested
+}
+
+// Fonction qui applique les demandes de l'utilisateur en attente, dans la fonction update d'AdvanceTick ou de BetweenTicks
+func (sim *Simulation) applyRequests() {
+	sim.applyEdits()
+	if sim.snapshotRequested {
+		sim.snapshotRequested = false
+		if path, err := sim.saveSnapshot(); err != nil {
+			log.Printf("Failed to save snapshot: %v", err)
+		} else {
+			log.Printf("Simulation sauvegardée dans %s", path)
+		}
+	}
+}
 
-	return carte.NewCarte(*tilemapJSON, tilesets, tilemapImg, coliders, computers, statues)
+// Fonction qui crée le bus d'évènements et y rattache le journal demandé dans la configuration
+func createEventBus(config SimulationConfig) (*events.Bus, error) {
+	bus := events.NewBus()
+	if config.EventLogPath != "" {
+		sink, err := events.NewFileSink(config.EventLogPath)
+		if err != nil {
+			return nil, fmt.Errorf("échec de la création du journal d'évènements: %v", err)
+		}
+		bus.Attach(sink)
+	}
+	return bus, nil
 }
 
 // Fonction qui charge les objets dans la carte
 func loadObjects(env *ag.Environnement) []ag.InterfaceObjet {
-	obj := make([]ag.InterfaceObjet, NumComputers+NumStatues)
+	numComputers, numStatues := len(env.Carte.Ordinateurs), len(env.Carte.Statues)
+	obj := make([]ag.InterfaceObjet, numComputers+numStatues)
 
-	for i := 0; i < NumComputers; i++ {
+	for i := 0; i < numComputers; i++ {
 		obj[i] = ag.NewComputer(
 			env,
 			ag.IdObjet(fmt.Sprintf("Computer%d", i)),
@@ -149,65 +326,43 @@ func loadObjects(env *ag.Environnement) []ag.InterfaceObjet {
 		env.Objs = append(env.Objs, obj[i])
 	}
 
-	for i := 0; i < NumStatues; i++ {
-		obj[i+NumComputers] = ag.NewStatue(
+	for i := 0; i < numStatues; i++ {
+		obj[i+numComputers] = ag.NewStatue(
 			env,
 			ag.IdObjet(fmt.Sprintf("Statue%d", i)),
 			ut.Position{X: float64(env.Carte.Statues[i].Min.X), Y: float64(env.Carte.Statues[i].Min.Y)},
 		)
-		env.Objs = append(env.Objs, obj[i+NumComputers])
+		env.Objs = append(env.Objs, obj[i+numComputers])
 	}
 	return obj
 }
 
-// Fonction qui renvoie une liste des positions possibles que peuvent prendre les objets ou les agents
-func getValidSpawnPositions(carte *carte.Carte) []ut.Position {
-	validPositions := []ut.Position{}
-	for layerIdx, layer := range carte.TilemapJSON.Layers {
-		for i, tileID := range layer.Data {
-			if tileID == 5 || tileID == 6 || tileID == 21 || tileID == 22 { // Assumindo que 0 representa um tile vazio
-				x := float64((i % layer.Width) * TileSize)
-				y := float64((i / layer.Width) * TileSize)
-				img := carte.Tilesets[layerIdx].Img(tileID)
-				offsetY := -(img.Bounds().Dy() + TileSize)
-				y += float64(offsetY)
-
-				validPositions = append(validPositions, ut.Position{X: x, Y: y})
-			}
-		}
-	}
-	return validPositions
-}
-
 // Fonction qui crée et rajoute à la carte les nouveaux agents
-func createAgents(env *ag.Environnement, carte *carte.Carte, config SimulationConfig) []*ag.Agent {
+func createAgents(env *ag.Environnement, carte *carte.Carte, config SimulationConfig) ([]*ag.Agent, error) {
 	agents := make([]*ag.Agent, config.NumAgents)
-	validPositions := getValidSpawnPositions(carte)
+	validPositions := carte.SpawnPositions()
 	visitationMap := ag.NewVisitationMap(validPositions)
 
 	if len(validPositions) < config.NumAgents {
-		log.Fatalf("Not enough valid spawn positions for all agents")
+		return nil, fmt.Errorf("pas assez de positions de spawn valides pour tous les agents")
 	}
 
-	rand.Shuffle(len(validPositions), func(i, j int) {
+	env.Rng.Shuffle(len(validPositions), func(i, j int) {
 		validPositions[i], validPositions[j] = validPositions[j], validPositions[i]
 	})
 
-	// Image par défaut
-	agentsImg := loadImage(AssetsPath + AgentBelieverImageFile)
-
 	for i := 0; i < config.NumAgents; i++ {
 		// Génère des valeurs aléatoires en rescpectant les contraintes de type s'il y en a
 
 		var Opinion float64
 		if i < config.NumBelievers {
-			Opinion = rand.Float64()*(1./3.) + 2./3.
+			Opinion = env.Rng.Float64()*(1./3.) + 2./3.
 		} else if i < config.NumBelievers+config.NumSceptics {
-			Opinion = rand.Float64() * (1. / 3.)
+			Opinion = env.Rng.Float64() * (1. / 3.)
 		} else if i < config.NumBelievers+config.NumSceptics+config.NumNeutrals {
-			Opinion = rand.Float64()*(1./3.) + 1./3.
+			Opinion = env.Rng.Float64()*(1./3.) + 1./3.
 		} else {
-			Opinion = rand.Float64()
+			Opinion = env.Rng.Float64()
 		}
 
 		// Détermine le type de base de l'agent
@@ -220,10 +375,10 @@ func createAgents(env *ag.Environnement, carte *carte.Carte, config SimulationCo
 			TypeChoosen = ag.Sceptic
 		}
 		id := ag.IdAgent(fmt.Sprintf("Agent%d", i))
-		velocite := rand.Float64()
+		velocite := env.Rng.Float64()
 		acuite := 50.0
 		position := validPositions[i]
-		personalParameter := 0.1 + rand.Float64()*4.0 - 0.1
+		personalParameter := 0.1 + env.Rng.Float64()*4.0 - 0.1
 		// Crée une carte de charisme
 		charisme := make(map[ag.IdAgent]float64)
 
@@ -235,13 +390,10 @@ func createAgents(env *ag.Environnement, carte *carte.Carte, config SimulationCo
 		switch TypeChoosen {
 		case ag.Believer:
 			strategy = config.BelieverMovement
-			agentsImg = loadImage(AssetsPath + AgentBelieverImageFile)
 		case ag.Sceptic:
 			strategy = config.ScepticMovement
-			agentsImg = loadImage(AssetsPath + AgentScepticImageFile)
 		case ag.Neutral:
 			strategy = config.NeutralMovement
-			agentsImg = loadImage(AssetsPath + AgentNeutralImageFile)
 		}
 
 		// Créez l'agent à l'aide de NewAgent
@@ -257,7 +409,6 @@ func createAgents(env *ag.Environnement, carte *carte.Carte, config SimulationCo
 			personalParameter,
 			TypeChoosen,
 			make(chan ag.Message),
-			agentsImg,
 		)
 
 		// Configure les champs supplémentaires
@@ -268,211 +419,44 @@ func createAgents(env *ag.Environnement, carte *carte.Carte, config SimulationCo
 	}
 	env.SetRelations()
 	env.SetPoids()
-	return env.Ags
-}
-
-// Structure pour contenir les données des agents à partir d'un fichier
-type AgentData struct {
-	Id                string             `json:"id"`
-	Opinion           float64            `json:"opinion"`
-	Charisme          map[string]float64 `json:"charisme"`
-	Relation          map[string]float64 `json:"relation"`
-	PersonalParameter float64            `json:"personalParameter"`
-	SubType           string             `json:"subType"`
-}
-
-// Fonction pour créer des agents à partir d'un fichier
-func createAgentsFromFile(env *ag.Environnement, filePath string) ([]*ag.Agent, error) {
-	// Lire le contenu du fichier
-	data, err := ioutil.ReadFile(filePath)
-	if err != nil {
-		return nil, fmt.Errorf("échec de la lecture du fichier: %v", err)
-	}
-
-	// Analyser les données JSON
-	var agentsData []AgentData
-	err = json.Unmarshal(data, &agentsData)
-	if err != nil {
-		return nil, fmt.Errorf("échec de l'analyse des données JSON: %v", err)
-	}
-
-	// Générer des positions valides
-	carte := env.Carte
-	validPositions := getValidSpawnPositions(carte)
-	if len(validPositions) < len(agentsData) {
-		return nil, fmt.Errorf("pas assez de positions de spawn valides pour tous les agents")
-	}
-
-	rand.Shuffle(len(validPositions), func(i, j int) {
-		validPositions[i], validPositions[j] = validPositions[j], validPositions[i]
-	})
-
-	// Créer des agents à partir des données analysées
-	agents := make([]*ag.Agent, len(agentsData))
-	for i, agentData := range agentsData {
-		id := ag.IdAgent(agentData.Id)
-		charisme := make(map[ag.IdAgent]float64)
-		for k, v := range agentData.Charisme {
-			charisme[ag.IdAgent(k)] = v
-		}
-		relation := make(map[ag.IdAgent]float64)
-		for k, v := range agentData.Relation {
-			relation[ag.IdAgent(k)] = v
-		}
-
-		// Déterminer le type en fonction de l'opinion
-		var typeAgt ag.TypeAgent
-		if agentData.Opinion > 2.0/3.0 {
-			typeAgt = ag.Believer
-		} else if agentData.Opinion > 1.0/3.0 {
-			typeAgt = ag.Neutral
-		} else {
-			typeAgt = ag.Sceptic
-		}
-
-		subType := ag.SubTypeAgent(agentData.SubType)
-
-		// Générer la vélocité, l'acuité et la position
-		velocite := rand.Float64()
-		acuite := 50.0
-		position := validPositions[i]
-
-		// Charger l'image appropriée en fonction du type d'agent
-		var agentsImg *ebiten.Image
-		switch typeAgt {
-		case ag.Believer:
-			agentsImg = loadImage(AssetsPath + AgentBelieverImageFile)
-		case ag.Sceptic:
-			agentsImg = loadImage(AssetsPath + AgentScepticImageFile)
-		case ag.Neutral:
-			agentsImg = loadImage(AssetsPath + AgentNeutralImageFile)
-		default:
-			return nil, fmt.Errorf("type d'agent inconnu: %s", typeAgt)
-		}
-
-		if agentsImg == nil {
-			return nil, fmt.Errorf("échec du chargement de l'image pour le type d'agent: %s", typeAgt)
-		}
-
-		// Créer l'agent
-		agent := ag.NewAgent(
-			env,
-			id,
-			velocite,
-			acuite,
-			position,
-			agentData.Opinion,
-			charisme,
-			relation,
-			agentData.PersonalParameter,
-			typeAgt,
-			make(chan ag.Message),
-			agentsImg,
-		)
-		agent.SubType = subType
-
-		agents[i] = agent
-		env.AddAgent(agent)
-	}
-	env.SetPoids()
-	return agents, nil
+	return env.Ags, nil
 }
 
 // Fonction qui affiche une image sur la fenêtre d'affichage
 func loadImage(path string) *ebiten.Image {
-	img, _, err := ebitenutil.NewImageFromFile(path)
+	file, err := assets.Open(path)
 	if err != nil {
 		log.Fatalf("Failed to load image: %s, error: %v", path, err)
 	}
-	return img
-}
-
-// Fonction qui charge la carte des tiles
-func loadTilemapJSON(path string) *tile.TilemapJSON {
-	tilemap, err := tile.NewTilemapJSON(path)
-	if err != nil {
-		log.Fatalf("Failed to load tilemap JSON: %s, error: %v", path, err)
-	}
-	return tilemap
-}
+	defer file.Close()
 
-// Fonction qui charge les jeux de tiles
-func loadTilesets(tilemapJSON *tile.TilemapJSON) []tile.Tileset {
-	tilesets, err := tilemapJSON.GenTilesets()
+	img, _, err := ebitenutil.NewImageFromReader(file)
 	if err != nil {
-		log.Fatalf("Failed to generate tilesets, error: %v", err)
-	}
-	return tilesets
-}
-
-// Fonction de génération des statues
-func generateStatues(tilemapJSON *tile.TilemapJSON, tilesets []tile.Tileset) []image.Rectangle {
-	var computersPositions []image.Rectangle
-	for layerIdx, layer := range tilemapJSON.Layers {
-		for i, tileID := range layer.Data {
-			if tileID == 0 || layerIdx == 0 || layerIdx == 1 || layerIdx == 2 || layerIdx == 4 {
-				continue
-			}
-
-			x, y := (i%layer.Width)*TileSize, (i/layer.Width)*TileSize
-			img := tilesets[layerIdx].Img(tileID)
-			offsetY := -(img.Bounds().Dy() + TileSize)
-			y += offsetY
-			computersPositions = append(computersPositions, image.Rect(x, y, x+img.Bounds().Dx(), y+img.Bounds().Dy()))
-		}
-	}
-	return computersPositions
-}
-
-// Fonction de génération des ordinateurs
-func generateComputers(tilemapJSON *tile.TilemapJSON, tilesets []tile.Tileset) []image.Rectangle {
-	var computersPositions []image.Rectangle
-	for layerIdx, layer := range tilemapJSON.Layers {
-		for i, tileID := range layer.Data {
-			if tileID == 0 || layerIdx == 0 || layerIdx == 1 || layerIdx == 4 || layerIdx == 3 {
-				continue
-			}
-
-			x, y := (i%layer.Width)*TileSize, (i/layer.Width)*TileSize
-			img := tilesets[layerIdx].Img(tileID)
-			offsetY := -(img.Bounds().Dy() + TileSize)
-			y += offsetY
-			computersPositions = append(computersPositions, image.Rect(x, y, x+img.Bounds().Dx(), y+img.Bounds().Dy()))
-		}
-	}
-
-	return computersPositions
-}
-
-// Fonction de génération des bornes d'une zone de collision (où deux éléments ne peuvent pas coexister)
-func generateColliders(tilemapJSON *tile.TilemapJSON, tilesets []tile.Tileset) []image.Rectangle {
-	var coliders []image.Rectangle
-	for layerIdx, layer := range tilemapJSON.Layers {
-		for i, tileID := range layer.Data {
-			if tileID == 0 || layerIdx == 0 {
-				continue
-			}
-
-			x, y := (i%layer.Width)*TileSize, (i/layer.Width)*TileSize
-			img := tilesets[layerIdx].Img(tileID)
-			offsetY := -(img.Bounds().Dy() + TileSize)
-			y += offsetY
-			coliders = append(coliders, image.Rect(x, y, x+img.Bounds().Dx(), y+img.Bounds().Dy()))
-		}
+		log.Fatalf("Failed to load image: %s, error: %v", path, err)
 	}
-	return coliders
+	return img
 }
 
 // Fonction qui affiche les éléments dans la fenêtre d'affichage
 func (sim *Simulation) Draw(screen *ebiten.Image) {
 	// Dessine l'arrière-plan et les agents rgba(57,61,125,255)
 	screen.Fill(color.RGBA{57, 61, 125, 255})
-	sim.drawMap(screen)
-	sim.drawAgents(screen)
-	sim.drawAcuite(screen)
-	sim.drawColliders(screen)
+	sim.drawWorld(screen, func(world *ebiten.Image) {
+		sim.drawMap(world)
+		sim.drawPlacedObjects(world)
+		sim.drawOverlays(world)
+		sim.drawAgents(world)
+		sim.drawSelectionIndicator(world)
+	})
 	sim.drawInfoPanel(screen)
-	sim.drawSelectionIndicator(screen)
+	sim.clock.draw(screen, sim.env.Tick())
+	sim.overlays.draw(screen)
+	sim.liveCharts.draw(screen)
+	sim.editor.draw(screen)
+
+	// Capture de l'écran complet, avant le témoin d'enregistrement
+	sim.capture.capture(screen, sim.env.Tick())
+	sim.capture.draw(screen)
 }
 
 // Fonction qui affiche dans la fenêtre d'affichage un indicateur de la séléction de l'utilisateur
@@ -484,13 +468,9 @@ func (sim *Simulation) drawSelectionIndicator(screen *ebiten.Image) {
 		height := float64(AgentImageSize)
 		vector.StrokeRect(screen, float32(x), float32(y), float32(width), float32(height), 2, color.RGBA{255, 255, 0, 255}, false)
 	} else if sim.selectedPC != nil {
-		x := sim.selectedPC.Position.X
-		y := sim.selectedPC.Position.Y
-
-		width := float64(sim.carte.Ordinateurs[0].Max.X - sim.carte.Ordinateurs[0].Min.X)
-		height := float64(sim.carte.Ordinateurs[0].Max.Y - sim.carte.Ordinateurs[0].Min.Y)
-
-		vector.StrokeRect(screen, float32(x), float32(y), float32(width), float32(height), 2, color.RGBA{255, 255, 0, 255}, false)
+		// Rectangle de l'ordinateur sélectionné: il peut avoir été ajouté depuis l'éditeur, la carte n'en contient pas forcément
+		bounds := sim.objectBounds(sim.selectedPC)
+		vector.StrokeRect(screen, float32(bounds.Min.X), float32(bounds.Min.Y), float32(bounds.Dx()), float32(bounds.Dy()), 2, color.RGBA{255, 255, 0, 255}, false)
 	}
 }
 
@@ -525,149 +505,55 @@ func (sim Simulation) drawAcuite(screen *ebiten.Image) {
 	}
 }
 
-// Fonction qui affiche les informations de la simulation (nombre d'agents, temps écoulé...) dans un cadre de la fenêtre d'affichage
-func (sim *Simulation) drawInfoPanel(screen *ebiten.Image) {
-	panelX, panelY := 0, 0
-	panelWidth, panelHeight := 240, WindowHeight-20
-	padding := 10
-
-	// Dessine le panneau de fond
-	vector.DrawFilledRect(screen, float32(panelX), float32(panelY), float32(panelWidth), float32(panelHeight), color.RGBA{0, 0, 0, 180}, false)
-
-	// Titre du panneau
-	ebitenutil.DebugPrintAt(screen, "Informations de la simulation", panelX+padding, panelY+padding)
-
-	y := panelY + 30
-
-	// Informations de la simulation
-	elapsed := time.Since(sim.start)
-	simInfo := fmt.Sprintf("Temps écoulé: %s", elapsed.Round(time.Second))
-	ebitenutil.DebugPrintAt(screen, simInfo, panelX+padding, y)
-	y += 40
-
-	// Nombre d'agents par type
-	ebitenutil.DebugPrintAt(screen, "Nombre d'agents:", panelX+padding, y)
-	y += 20
-	agentTypes := []ag.TypeAgent{ag.Sceptic, ag.Believer, ag.Neutral}
-	for _, agentType := range agentTypes {
-		count, _ := sim.env.NbrAgents.Load(agentType)
-		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("  %s: %d", agentType, count), panelX+padding, y)
-		y += 20
-	}
-	y += 20
-
-	// Comptage des ordinateurs par programme None ou Go
-	ebitenutil.DebugPrintAt(screen, "Nombre d'ordinateurs:", panelX+padding, y)
-	y += 20
-	computerTypes := []ag.Programm{ag.NoPgm, ag.GoPgm}
-	pcs := sim.env.Objs
-	for _, computerType := range computerTypes {
-		count := 0
-		for _, pc := range pcs {
-			if pc.GetType() != ag.ComputerType {
-				continue
-			}
-			if pc.GetProgramm() == computerType {
-				count++
-			}
-		}
-		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("  %s: %d", computerType, count), panelX+padding, y)
-		y += 20
-	}
-	y += 20
-
-	// Informations de l'agent sélectionné
-	if sim.selected != nil {
-		ebitenutil.DebugPrintAt(screen, "Agent sélectionné:", panelX+padding, y)
-		y += 20
-		agentInfo := fmt.Sprintf("  ID: %s\n  Type: %s\n  Sous-Type: %s\n  Paramètre Personnel: %.2f\n  Opinion: %.2f\n  Vivant: %t\n  Temps de Dialogue: %d\n  Action: %s\n  Stratégie de mouvement: %s\n  Occupé : %t\n  Dernière prière : %.2f",
-			sim.selected.Id,
-			sim.selected.TypeAgt,
-			sim.selected.SubType,
-			sim.selected.PersonalParameter,
-			sim.selected.Opinion,
-			sim.selected.Vivant,
-			sim.selected.DialogTimer,
-			sim.selected.CurrentAction,
-			sim.selected.MovementStrategy,
-			sim.selected.Occupied,
-			time.Since(sim.selected.TimeLastStatue).Seconds(),
-		)
-		ebitenutil.DebugPrintAt(screen, agentInfo, panelX+padding, y)
-		y += 180
-
-		// Informations sur la discussion actuelle
-		if sim.selected.DiscussingWith != nil {
-			discussInfo := fmt.Sprintf("  En discussion avec:\n  ID: %s\n  Type: %s",
-				sim.selected.DiscussingWith.Id,
-				sim.selected.DiscussingWith.TypeAgt,
-			)
-			ebitenutil.DebugPrintAt(screen, discussInfo, panelX+padding, y)
-			y += 60
-		}
-
-		// Historique des conversations
-		ebitenutil.DebugPrintAt(screen, "  Dernières conversations avec:", panelX+padding, y)
-		y += 20
-		for i, lastTalked := range sim.selected.LastTalkedTo {
-			talkInfo := fmt.Sprintf("    %d. %s (%s)", i+1, lastTalked.Id, lastTalked.TypeAgt)
-			ebitenutil.DebugPrintAt(screen, talkInfo, panelX+padding, y)
-			y += 15
-		}
-		y += 20
-
-		// Relations avec les autres agents
-		ebitenutil.DebugPrintAt(screen, "Relations:", panelX+padding, y)
-		y += 20
-
-		// Récupére les clés de la carte
-		keys := make([]string, 0, len(sim.selected.Relation))
-		for otherId := range sim.selected.Relation {
-			keys = append(keys, string(otherId))
-		}
-
-		// Trie les clés
-		sort.Strings(keys)
-
-		// Itére sur les clés triées
-		for _, otherId := range keys {
-			relation := sim.selected.Relation[ag.IdAgent(otherId)]
-			relationType := getRelationType(relation)
-			ebitenutil.DebugPrintAt(
-				screen,
-				fmt.Sprintf("  %s: %.2f %s ", otherId, relation, relationType),
-				panelX+padding,
-				y,
-			)
-			y += 15
-		}
-	}
-
-	// Informations de l'ordinateur sélectionné
-	if sim.selectedPC != nil {
-		ebitenutil.DebugPrintAt(screen, "Ordinateur sélectionné:", panelX+padding, y)
-		y += 20
-		pcInfo := fmt.Sprintf("  ID: %s\n  En utilisation: %t\n  Langage de programmation: %s",
-			sim.selectedPC.Id,
-			sim.selectedPC.Used,
-			sim.selectedPC.Programm,
-		)
-		ebitenutil.DebugPrintAt(screen, pcInfo, panelX+padding, y)
-	}
-}
-
 // Fonction d'affichage de la carte dans la fenêtre d'affichage
 func (sim *Simulation) drawMap(screen *ebiten.Image) {
 	opts := ebiten.DrawImageOptions{}
+	// Les ordinateurs et statues retirés depuis l'éditeur sont reconnus par leur rôle
+	var properties map[int]tile.Properties
+	if len(sim.editor.removed) > 0 {
+		properties = sim.carte.TilemapJSON.TileProperties()
+	}
 	// Gestion par couche
-	for layerIdx, layer := range sim.carte.TilemapJSON.Layers {
+	for _, layer := range sim.carte.TilemapJSON.Layers {
 		for i, tileID := range layer.Data {
 			if tileID == 0 {
 				continue
 			}
-			x, y := (i%layer.Width)*TileSize, (i/layer.Width)*TileSize
-			img := sim.carte.Tilesets[layerIdx].Img(tileID)
-			opts.GeoM.Translate(float64(x), float64(y-img.Bounds().Dy()-TileSize))
+			img := tileImage(&sim.carte.TilemapJSON, sim.tilesets, tileID)
+			if img == nil {
+				continue
+			}
+			size := img.Bounds().Size()
+			bounds := carte.TileBounds(i, layer.Width, tile.FlippedSize(tileID, size))
+			if sim.editor.removedTile(bounds, func(role string) bool {
+				return tile.HasRole(layer, properties[tile.TileID(tileID)], role)
+			}) {
+				continue
+			}
+			flipGeoM(&opts.GeoM, tileID, size)
+			opts.GeoM.Translate(float64(bounds.Min.X), float64(bounds.Min.Y))
+			screen.DrawImage(img, &opts)
+			opts.GeoM.Reset()
+		}
+
+		// Tiles placées librement dans les couches d'objets, à la taille de l'objet
+		for _, object := range layer.Objects {
+			if object.GID == 0 {
+				continue
+			}
+			img := tileImage(&sim.carte.TilemapJSON, sim.tilesets, object.GID)
+			if img == nil || object.Width <= 0 || object.Height <= 0 {
+				continue
+			}
+			if sim.editor.removedTile(object.Bounds(), func(role string) bool {
+				return tile.HasObjectRole(layer, object, properties[tile.TileID(object.GID)], role)
+			}) {
+				continue
+			}
+			flipGeoM(&opts.GeoM, object.GID, img.Bounds().Size())
+			size := tile.FlippedSize(object.GID, img.Bounds().Size())
+			opts.GeoM.Scale(object.Width/float64(size.X), object.Height/float64(size.Y))
+			opts.GeoM.Translate(object.X, object.Y-object.Height)
 			screen.DrawImage(img, &opts)
 			opts.GeoM.Reset()
 		}
@@ -708,8 +594,12 @@ func (sim *Simulation) drawAgents(screen *ebiten.Image) {
 		opts.GeoM.Translate(agent.Position.X, agent.Position.Y)
 
 		// Suppression de l'effet d'éclairage pour les agents en discussion
-		subImg := agent.Img.SubImage(image.Rect(0, 0, AgentImageSize, AgentImageSize)).(*ebiten.Image)
-		screen.DrawImage(subImg, &opts)
+		subImg := sim.sprites.agent(agent).SubImage(image.Rect(0, 0, AgentImageSize, AgentImageSize)).(*ebiten.Image)
+		if sim.overlays.Opinion {
+			drawTintedAgent(screen, subImg, opts.GeoM, agent.Opinion)
+		} else {
+			screen.DrawImage(subImg, &opts)
+		}
 
 		sim.drawDialogBox(screen, *agent)
 	}
@@ -778,113 +668,203 @@ func (sim *Simulation) drawDialogBox(screen *ebiten.Image, agent ag.Agent) {
 	}
 }
 
-// Fonction d'affichage des bornes d'une zone de collision dans la fenêtre d'affichage
-func (sim *Simulation) drawColliders(screen *ebiten.Image) {
-	for _, colider := range sim.carte.Coliders {
-		vector.StrokeRect(screen, float32(colider.Min.X), float32(colider.Min.Y), float32(colider.Dx()), float32(colider.Dy()), 1.0, color.RGBA{0, 0, 0, 0}, true)
-	}
-}
-
 // Fonction qui retourne les dimentions de la fenêtre d'affichage
 func (sim *Simulation) Layout(outsideWidth, outsideHeight int) (int, int) {
-	return WindowWidth, WindowHeight
+	return sim.camera.layout(outsideWidth, outsideHeight)
 }
 
 // Fonction de mise à jour de la simulation
 func (sim *Simulation) Update() error {
-	select {
-	case <-sim.ctx.Done():
-		return ebiten.Termination
-	default:
-		// Position du curseur
-		cursorX, cursorY := ebiten.CursorPosition()
-
-		// Détection d'un clic
-		if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
-			// Vérifie si le clic se situe dans la zone agent
-			for i := range sim.agents {
-				agent := sim.agents[i]
-				if cursorX >= int(agent.Position.X) &&
-					cursorX <= int(agent.Position.X+AgentImageSize) &&
-					cursorY >= int(agent.Position.Y) &&
-					cursorY <= int(agent.Position.Y+AgentImageSize) {
-					sim.selected = sim.env.GetAgentById(agent.Id)
-					sim.selectedPC = nil
-					sim.selectionIndicator = ebiten.NewImage(AgentImageSize, AgentImageSize)
-					sim.selectionIndicator.Fill(color.RGBA{255, 255, 0, 128})
-					break
-				}
-			}
+	// Les contrôles de l'horloge et de l'éditeur sont prioritaires sur la sélection
+	if !sim.clock.handleInput() && !sim.handleEditorInput() {
+		sim.handleInput()
+	}
+	sim.handlePanelInput()
+	sim.overlays.handleInput()
+	sim.liveCharts.handleInput()
+	sim.capture.handleInput(sim.env.Tick())
+	cursorX, _ := ebiten.CursorPosition()
+	sim.camera.handleInput(cursorX >= PanelWidth)
+
+	// Exécute autant de ticks que le demande la vitesse de la simulation
+	for n := sim.clock.ticks(); n > 0; n-- {
+		if sim.finished() {
+			return ebiten.Termination
+		}
+		sim.step()
+	}
+	// En pause (ou entre deux ticks à vitesse réduite), les demandes sont appliquées sans avancer l'horloge
+	if sim.hasRequests() {
+		sim.env.BetweenTicks(sim.applyRequests)
+	}
 
-			// Vérifie si le clic se situe sur un ordinateur
-			for i := range sim.objets {
-				if sim.objets[i].GetType() != ag.ComputerType {
-					continue
-				}
+	// La caméra suit l'agent sélectionné
+	if sim.camera.Follow && sim.selected != nil {
+		sim.camera.CenterOn(sim.selected.Position.X+AgentImageSize/2, sim.selected.Position.Y+AgentImageSize/2)
+	}
+	return nil
+}
 
-				pc := &sim.objets[i]
-				if computer, ok := (*pc).(*ag.Computer); ok {
-					if cursorX >= int(computer.ObjPosition().X) &&
-						cursorX <= int(computer.ObjPosition().X+TileSize) &&
-						cursorY >= int(computer.ObjPosition().Y) &&
-						cursorY <= int(computer.ObjPosition().Y+TileSize) {
-						sim.selectedPC = computer
-						sim.selected = nil
-						sim.selectionIndicator = ebiten.NewImage(TileSize, TileSize)
-						sim.selectionIndicator.Fill(color.RGBA{255, 255, 0, 128})
-						break
-					}
-				}
+// Fonction qui indique si la durée de la simulation est écoulée
+func (sim *Simulation) finished() bool {
+	return sim.env.Tick() >= sim.clock.endTick
+}
+
+// Fonction qui traite les actions de l'utilisateur (clavier et souris)
+func (sim *Simulation) handleInput() {
+	// Sauvegarde de l'état de la simulation, enregistrée entre deux ticks
+	if inpututil.IsKeyJustPressed(ebiten.KeyS) {
+		sim.snapshotRequested = true
+	}
+
+	// Position du curseur dans le monde, à travers la caméra
+	screenX, screenY := ebiten.CursorPosition()
+	x, y := sim.camera.ScreenToWorld(screenX, screenY)
+	cursorX, cursorY := int(x), int(y)
+
+	// Détection d'un clic sur la carte (le panneau d'informations a ses propres contrôles)
+	if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) && screenX >= PanelWidth {
+		// Vérifie si le clic se situe dans la zone agent
+		for i := range sim.agents {
+			agent := sim.agents[i]
+			if cursorX >= int(agent.Position.X) &&
+				cursorX <= int(agent.Position.X+AgentImageSize) &&
+				cursorY >= int(agent.Position.Y) &&
+				cursorY <= int(agent.Position.Y+AgentImageSize) {
+				sim.selected = sim.env.GetAgentById(agent.Id)
+				sim.selectedPC = nil
+				sim.selectionIndicator = ebiten.NewImage(AgentImageSize, AgentImageSize)
+				sim.selectionIndicator.Fill(color.RGBA{255, 255, 0, 128})
+				break
 			}
 		}
 
-		// Mettre à jour du timer et les états
-		for i := range sim.agents {
-			if sim.agents[i].DialogTimer > 0 {
-				sim.agents[i].DialogTimer--
-				if sim.agents[i].DialogTimer == 0 {
-					sim.agents[i].ClearAction()
-					if sim.agents[i].UseComputer != nil {
-						sim.agents[i].UseComputer.Release()
-						sim.agents[i].UseComputer = nil
-					}
-					sim.agents[i].Occupied = false
+		// Vérifie si le clic se situe sur un ordinateur
+		for i := range sim.objets {
+			if sim.objets[i].GetType() != ag.ComputerType {
+				continue
+			}
+
+			pc := &sim.objets[i]
+			if computer, ok := (*pc).(*ag.Computer); ok {
+				if cursorX >= int(computer.ObjPosition().X) &&
+					cursorX <= int(computer.ObjPosition().X+TileSize) &&
+					cursorY >= int(computer.ObjPosition().Y) &&
+					cursorY <= int(computer.ObjPosition().Y+TileSize) {
+					sim.selectedPC = computer
+					sim.selected = nil
+					sim.selectionIndicator = ebiten.NewImage(TileSize, TileSize)
+					sim.selectionIndicator.Fill(color.RGBA{255, 255, 0, 128})
+					break
 				}
 			}
 		}
+	}
+}
 
-		// Met à jour l'agent sélectionné s'il existe
-		if sim.selected != nil {
-			// Met à jour la référence pour garantir que les données soient à jour
-			sim.selected = sim.env.GetAgentById(sim.selected.Id)
+// Fonction qui fait avancer la simulation d'un tick: elle est indépendante de l'affichage
+func (sim *Simulation) step() {
+	sim.env.AdvanceTick(sim.update)
+}
+
+// Fonction qui met à jour l'état de la simulation au début d'un tick, pendant que les agents sont en attente
+func (sim *Simulation) update() {
+	// Mettre à jour du timer et les états
+	sim.env.UpdateTimers()
+	sim.applyRequests()
+
+	// Met à jour l'agent sélectionné s'il existe
+	if sim.selected != nil {
+		// Met à jour la référence pour garantir que les données soient à jour
+		sim.selected = sim.env.GetAgentById(sim.selected.Id)
+	}
+
+	// Échantillonne les mesures de la population
+	sim.metrics.Record(sim.env.Tick(), sim.env)
+	sim.recordOpinionHistory()
+	sim.recordDiscussions()
+	sim.recordLiveCharts()
+
+	// Enregistre l'état de la simulation dans la trace
+	if sim.trace != nil {
+		if err := sim.trace.WriteFrame(trace.Capture(sim.env.Tick(), sim.agents, sim.objets)); err != nil {
+			log.Printf("Failed to write trace frame: %v", err)
+			sim.trace.Close()
+			sim.trace = nil
 		}
+	}
+}
+
+// Fonction qui lance l'environnement et les agents
+func (sim *Simulation) startAgents() {
+	go sim.env.Listen()
+	for i := range sim.agents {
+		sim.agents[i].Start()
+	}
+	sim.start = time.Now()
 
-		// Calcule l'avis moyen
-		totalOpinion := 0.0
-		for _, agent := range sim.agents {
-			totalOpinion += agent.Opinion
+	// Enregistrement demandé dès le lancement
+	if sim.config.CaptureFormat != "" {
+		if err := sim.capture.start(sim.env.Tick()); err != nil {
+			log.Printf("Failed to start recording: %v", err)
 		}
-		averageOpinion := totalOpinion / float64(len(sim.agents))
-		sim.opinionAverages = append(sim.opinionAverages, averageOpinion)
 	}
-	return nil
 }
 
 // Fonction qui fait tourner la simulation
 func (sim *Simulation) Run() error {
-	defer sim.cancel() // On s'assure que le contexte est annulé lorsque Run() se termine
+	initializeWindow()
+	sim.startAgents()
 
-	go sim.env.Listen()
-	go func() {
-		for i := range sim.agents {
-			go sim.agents[i].Start()
+	if err := ebiten.RunGame(sim); err != nil && err != ebiten.Termination {
+		return err
+	}
+
+	return sim.finish()
+}
+
+// Fonction qui fait tourner la simulation sans fenêtre d'affichage, au même rythme que la version graphique
+func (sim *Simulation) RunHeadless() error {
+	// Sans fenêtre, les captures passent par le rendu logiciel
+	if sim.config.CaptureFormat != "" {
+		offscreen, err := newOffscreenRenderer(carte.MapPath(sim.config.MapFile))
+		if err != nil {
+			return err
 		}
-		sim.start = time.Now()
-	}()
+		sim.offscreen = offscreen
+	}
+	sim.startAgents()
 
-	if err := ebiten.RunGame(sim); err != nil && err != ebiten.Termination {
+	ticker := time.NewTicker(time.Second / TicksPerSecond)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if sim.finished() {
+			break
+		}
+		sim.step()
+		if tick := sim.env.Tick(); sim.offscreen != nil && sim.capture.due(tick) {
+			sim.capture.add(sim.offscreen.render(sim.agents, fmt.Sprintf("tick %d", tick)), tick)
+		}
+	}
+	return sim.finish()
+}
+
+// Fonction qui ferme les fichiers de la simulation, affiche le compte-rendu et enregistre les résultats
+func (sim *Simulation) finish() error {
+	// Arrêt des agents, puis fermeture du journal d'évènements et de la trace
+	sim.env.Stop()
+	if err := sim.capture.stop(); err != nil {
+		log.Printf("Failed to save recording: %v", err)
+	}
+	if err := sim.env.Events.Close(); err != nil {
 		return err
 	}
+	if sim.trace != nil {
+		if err := sim.trace.Close(); err != nil {
+			return err
+		}
+	}
 
 	// Affichages de finalisation
 	fmt.Println("\n--- Simulation Terminée ---")
@@ -908,31 +888,31 @@ func (sim *Simulation) Run() error {
 	averageOpinion := totalOpinion / float64(len(sim.agents))
 	fmt.Printf("\nOpinion moyenne des agents: %.2f\n", averageOpinion)
 
-	// Générer et enregistrer le graphique
-	xValues := make([]float64, len(sim.opinionAverages))
-	for i := range xValues {
-		xValues[i] = float64(i)
-	}
+	// Indicateurs de polarisation et de consensus
+	summary := sim.metrics.Summarize(sim.env.Tick(), sim.agents)
+	summary.Print()
+	sim.summary = &summary
 
-	graph := chart.Chart{
-		Series: []chart.Series{
-			chart.ContinuousSeries{
-				XValues: xValues,
-				YValues: sim.opinionAverages,
-			},
-		},
+	// Enregistre les séries temporelles et les graphiques
+	if err := os.MkdirAll(sim.outputDir, 0o755); err != nil {
+		return err
 	}
-
-	file, err := os.Create("opinion_averages.png")
-	if err != nil {
+	if err := sim.metrics.WriteCSV(sim.outputDir); err != nil {
 		return err
 	}
-	defer file.Close()
-	err = graph.Render(chart.PNG, file)
-	if err != nil {
+	if err := summary.Append(sim.outputDir); err != nil {
 		return err
 	}
-
+	if err := sim.metrics.SaveCharts(sim.outputDir); err != nil {
+		return err
+	}
+	if err := sim.influence.Save(sim.outputDir); err != nil {
+		return err
+	}
+	if err := sim.writeReport(); err != nil {
+		return err
+	}
+	fmt.Printf("\nRapport enregistré dans %s\n", sim.reportPath)
 	return nil
 }
 
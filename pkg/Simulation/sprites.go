@@ -0,0 +1,27 @@
+package simulation
+
+import (
+	ag "github.com/Tmegaa/The-Gophecy/pkg/Agent"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Registre des images des agents: chaque image est chargée une seule fois, au premier agent qui en a besoin,
+// puis partagée par tous les agents du même type. Les agents ne connaissent que leur type
+type spriteRegistry struct {
+	agents map[ag.TypeAgent]*ebiten.Image
+}
+
+// Fonction de création d'un registre vide
+func newSpriteRegistry() *spriteRegistry {
+	return &spriteRegistry{agents: make(map[ag.TypeAgent]*ebiten.Image)}
+}
+
+// Fonction qui renvoie l'image à afficher pour un agent, selon son type
+func (s *spriteRegistry) agent(agent *ag.Agent) *ebiten.Image {
+	img, ok := s.agents[agent.TypeAgt]
+	if !ok {
+		img = loadImage(AssetsPath + agentImageFile(agent.TypeAgt))
+		s.agents[agent.TypeAgt] = img
+	}
+	return img
+}
@@ -0,0 +1,468 @@
+package simulation
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Tmegaa/The-Gophecy/assets"
+	ag "github.com/Tmegaa/The-Gophecy/pkg/Agent"
+	mapgen "github.com/Tmegaa/The-Gophecy/pkg/Mapgen"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Constantes de l'écran de démarrage
+const (
+	MenuWidth       = 620 // largeur du formulaire
+	MenuRowHeight   = 24  // hauteur d'une ligne du formulaire
+	MenuLabelWidth  = 260 // largeur des libellés
+	MenuArrowWidth  = 20  // largeur des boutons < et >
+	MenuButtonWidth = 140 // largeur des boutons Lancer et Quitter
+	MaxAgents       = 1911
+	AgentsFilesDir  = "tests"
+)
+
+// Modes de configuration, identiques à ceux du menu du terminal
+var menuModes = []string{
+	"Nombre total d'agents",
+	"Quantité de chaque type d'agent",
+	"Charger les agents depuis un fichier",
+	"Reprendre une simulation sauvegardée",
+}
+
+// Ligne du formulaire de l'écran de démarrage
+type menuField struct {
+	label   string
+	visible func() bool             // la ligne n'est affichée que dans certains modes (nil: toujours)
+	value   func() string           // valeur affichée
+	step    func(delta int)         // modification avec les flèches ou les boutons < et >
+	set     func(text string) error // saisie au clavier (nil: valeur non saisissable)
+}
+
+// Écran de démarrage: formulaire qui construit la configuration de la simulation
+type startMenu struct {
+	config  SimulationConfig
+	mode    int // mode de configuration (index dans menuModes)
+	fields  []menuField
+	focus   int // index de la ligne sélectionnée parmi les lignes visibles
+	editing bool
+	text    string // texte en cours de saisie
+	message string // message d'erreur affiché sous le formulaire
+	launch  bool   // l'utilisateur a demandé à lancer la simulation
+	quit    bool   // l'utilisateur a demandé à quitter
+
+	agentFiles []string
+	snapshots  []string
+	maps       []string
+
+	rows    []menuRow
+	buttons [2]image.Rectangle // Lancer, Quitter
+}
+
+// Zones cliquables d'une ligne affichée
+type menuRow struct {
+	field       *menuField
+	prev, next  image.Rectangle
+	valueRect   image.Rectangle
+	visibleRank int
+}
+
+// Création de l'écran de démarrage à partir d'une configuration initiale
+func newStartMenu(config SimulationConfig) *startMenu {
+	m := &startMenu{config: config}
+	switch {
+	case config.SnapshotPath != "":
+		m.mode = 3
+	case config.AgentsFilePath != "":
+		m.mode = 2
+	case config.NumBelievers+config.NumSceptics+config.NumNeutrals > 0:
+		m.mode = 1
+	}
+	if m.config.NumAgents == 0 {
+		m.config.NumAgents = DefaultConfig().NumAgents
+	}
+	if m.config.SimulationTime == 0 {
+		m.config.SimulationTime = DefaultConfig().SimulationTime
+	}
+	m.refreshFiles()
+	m.fields = m.buildFields()
+	return m
+}
+
+// Fonction qui recherche les fichiers proposés dans les sélecteurs (agents, sauvegardes, cartes)
+func (m *startMenu) refreshFiles() {
+	m.agentFiles, _ = filepath.Glob(filepath.Join(AgentsFilesDir, "*.json"))
+	m.snapshots, _ = filepath.Glob(filepath.Join(m.config.OutputDir, "snapshot_*.json"))
+	// Cartes du dossier des cartes et cartes intégrées au programme, plus celle choisie en ligne de commande
+	maps := append(assets.Glob(MapsPath+"*.json"), assets.Glob(MapsPath+"*.tmx")...)
+	m.maps = make([]string, len(maps))
+	for i, path := range maps {
+		m.maps[i] = filepath.Base(path)
+	}
+	if m.config.MapFile != "" && !slices.Contains(m.maps, m.config.MapFile) {
+		m.maps = append(m.maps, m.config.MapFile)
+	}
+	if len(m.maps) == 0 {
+		m.maps = []string{TilemapJSONFile}
+	}
+	// Une carte générée avec les paramètres par défaut
+	m.maps = append(m.maps, mapgen.Prefix+mapgen.DefaultParams().String())
+}
+
+// Fonction qui construit les lignes du formulaire
+func (m *startMenu) buildFields() []menuField {
+	c := &m.config
+	inMode := func(modes ...int) func() bool {
+		return func() bool { return slices.Contains(modes, m.mode) }
+	}
+	notResume := func() bool { return m.mode != 3 }
+
+	fields := []menuField{
+		{
+			label: "Mode de configuration",
+			value: func() string { return menuModes[m.mode] },
+			step:  func(delta int) { m.mode = wrap(m.mode+sign(delta), len(menuModes)) },
+		},
+		intField("Nombre total d'agents", inMode(0), &c.NumAgents, 1, MaxAgents),
+		intField("Nombre d'agents croyants", inMode(1), &c.NumBelievers, 0, MaxAgents),
+		intField("Nombre d'agents sceptiques", inMode(1), &c.NumSceptics, 0, MaxAgents),
+		intField("Nombre d'agents neutres", inMode(1), &c.NumNeutrals, 0, MaxAgents),
+		fileField("Fichier des agents", inMode(2), &c.AgentsFilePath, &m.agentFiles),
+		fileField("Sauvegarde à reprendre", inMode(3), &c.SnapshotPath, &m.snapshots),
+		{
+			label: "Durée (en minutes)",
+			value: func() string { return strconv.Itoa(int(c.SimulationTime.Minutes())) },
+			step: func(delta int) {
+				c.SimulationTime = time.Duration(max(1, int(c.SimulationTime.Minutes())+delta)) * time.Minute
+			},
+			set: func(text string) error {
+				minutes, err := strconv.Atoi(text)
+				if err != nil || minutes <= 0 {
+					return fmt.Errorf("veuillez entrer un nombre valide supérieur à 0")
+				}
+				c.SimulationTime = time.Duration(minutes) * time.Minute
+				return nil
+			},
+		},
+		strategyField(ag.Believer, notResume, &c.BelieverMovement),
+		strategyField(ag.Sceptic, notResume, &c.ScepticMovement),
+		strategyField(ag.Neutral, notResume, &c.NeutralMovement),
+		{
+			label:   "Modèle d'opinion",
+			visible: notResume,
+			value:   func() string { return string(c.OpinionModel) },
+			step: func(delta int) {
+				i := max(0, slices.Index(ag.OpinionModels, c.OpinionModel))
+				c.OpinionModel = ag.OpinionModels[wrap(i+sign(delta), len(ag.OpinionModels))]
+			},
+		},
+		{
+			// Une sauvegarde est reprise sur sa propre carte
+			label:   "Carte",
+			visible: notResume,
+			value:   func() string { return c.MapFile },
+			step: func(delta int) {
+				i := max(0, slices.Index(m.maps, c.MapFile))
+				c.MapFile = m.maps[wrap(i+sign(delta), len(m.maps))]
+			},
+		},
+		{
+			label: "Graine (0: au hasard)",
+			value: func() string { return strconv.FormatInt(c.Seed, 10) },
+			step:  func(delta int) { c.Seed = max(0, c.Seed+int64(delta)) },
+			set: func(text string) error {
+				seed, err := strconv.ParseInt(text, 10, 64)
+				if err != nil || seed < 0 {
+					return fmt.Errorf("veuillez entrer un entier positif")
+				}
+				c.Seed = seed
+				return nil
+			},
+		},
+	}
+	return fields
+}
+
+// Fonction qui crée une ligne de saisie d'un entier compris entre lo et hi
+func intField(label string, visible func() bool, value *int, lo, hi int) menuField {
+	return menuField{
+		label:   label,
+		visible: visible,
+		value:   func() string { return strconv.Itoa(*value) },
+		step:    func(delta int) { *value = max(lo, min(hi, *value+delta)) },
+		set: func(text string) error {
+			n, err := strconv.Atoi(text)
+			if err != nil || n < lo || n > hi {
+				return fmt.Errorf("veuillez entrer un nombre entre %d et %d", lo, hi)
+			}
+			*value = n
+			return nil
+		},
+	}
+}
+
+// Fonction qui crée une ligne de choix de fichier: les flèches parcourent les fichiers trouvés, le clavier permet de saisir un chemin
+func fileField(label string, visible func() bool, path *string, files *[]string) menuField {
+	return menuField{
+		label:   label,
+		visible: visible,
+		value: func() string {
+			if *path == "" {
+				return "(aucun fichier)"
+			}
+			return *path
+		},
+		step: func(delta int) {
+			if len(*files) == 0 {
+				return
+			}
+			i := slices.Index(*files, *path)
+			if i < 0 && delta < 0 {
+				i = 0
+			}
+			*path = (*files)[wrap(i+sign(delta), len(*files))]
+		},
+		set: func(text string) error {
+			*path = text
+			return nil
+		},
+	}
+}
+
+// Fonction qui crée une ligne de choix de la stratégie de mouvement d'un type d'agent
+func strategyField(typeAgt ag.TypeAgent, visible func() bool, strategy *ag.MovementStrategy) menuField {
+	return menuField{
+		label:   fmt.Sprintf("Stratégie des %ss", strings.ToLower(string(typeAgt))),
+		visible: visible,
+		value:   func() string { return strategy.String() },
+		step: func(delta int) {
+			*strategy = editorStrategies[wrap(int(*strategy)+sign(delta), len(editorStrategies))]
+		},
+	}
+}
+
+// Fonction qui ramène i dans l'intervalle [0, n)
+func wrap(i, n int) int {
+	return ((i % n) + n) % n
+}
+
+// Fonction qui renvoie le signe d'un entier
+func sign(n int) int {
+	switch {
+	case n > 0:
+		return 1
+	case n < 0:
+		return -1
+	}
+	return 0
+}
+
+// Fonction qui renvoie les lignes visibles dans le mode courant
+func (m *startMenu) visibleFields() []*menuField {
+	visible := make([]*menuField, 0, len(m.fields))
+	for i := range m.fields {
+		if m.fields[i].visible == nil || m.fields[i].visible() {
+			visible = append(visible, &m.fields[i])
+		}
+	}
+	return visible
+}
+
+// Fonction qui vérifie la configuration et renvoie celle qui correspond au mode choisi
+func (m *startMenu) finalConfig() (SimulationConfig, error) {
+	config := m.config
+	config.NumAgents, config.NumBelievers, config.NumSceptics, config.NumNeutrals = 0, 0, 0, 0
+	config.AgentsFilePath, config.SnapshotPath = "", ""
+
+	switch m.mode {
+	case 0:
+		config.NumAgents = m.config.NumAgents
+	case 1:
+		config.NumBelievers, config.NumSceptics, config.NumNeutrals = m.config.NumBelievers, m.config.NumSceptics, m.config.NumNeutrals
+		config.NumAgents = config.NumBelievers + config.NumSceptics + config.NumNeutrals
+		if config.NumAgents == 0 || config.NumAgents > MaxAgents {
+			return config, fmt.Errorf("la somme des agents doit être comprise entre 1 et %d", MaxAgents)
+		}
+	case 2:
+		config.AgentsFilePath = m.config.AgentsFilePath
+		if _, err := os.Stat(config.AgentsFilePath); err != nil {
+			return config, fmt.Errorf("fichier des agents introuvable: %q", config.AgentsFilePath)
+		}
+	case 3:
+		config.SnapshotPath = m.config.SnapshotPath
+		if _, err := os.Stat(config.SnapshotPath); err != nil {
+			return config, fmt.Errorf("sauvegarde introuvable: %q", config.SnapshotPath)
+		}
+	}
+	return config, nil
+}
+
+// Fonction qui traite les actions de l'utilisateur sur l'écran de démarrage
+func (m *startMenu) update() {
+	fields := m.visibleFields()
+	m.focus = max(0, min(m.focus, len(fields)-1))
+	focused := fields[m.focus]
+
+	// Saisie au clavier dans la ligne sélectionnée
+	if chars := ebiten.AppendInputChars(nil); len(chars) > 0 && focused.set != nil {
+		if !m.editing {
+			m.editing, m.text = true, ""
+		}
+		m.text += string(chars)
+	}
+	if m.editing {
+		switch {
+		case inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && len(m.text) > 0:
+			m.text = string([]rune(m.text)[:len([]rune(m.text))-1])
+		case inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+			m.commit(focused)
+		case inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+			m.editing = false
+		}
+		return
+	}
+
+	// Navigation au clavier
+	delta := 1
+	if ebiten.IsKeyPressed(ebiten.KeyShift) {
+		delta = 10
+	}
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowUp):
+		m.focus = wrap(m.focus-1, len(fields))
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowDown), inpututil.IsKeyJustPressed(ebiten.KeyTab):
+		m.focus = wrap(m.focus+1, len(fields))
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft):
+		focused.step(-delta)
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowRight):
+		focused.step(delta)
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		m.submit()
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+		m.quit = true
+	}
+
+	// Clics sur les lignes et les boutons
+	if !inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		return
+	}
+	cursor := image.Pt(ebiten.CursorPosition())
+	for _, row := range m.rows {
+		switch {
+		case cursor.In(row.prev):
+			m.focus = row.visibleRank
+			row.field.step(-delta)
+		case cursor.In(row.next):
+			m.focus = row.visibleRank
+			row.field.step(delta)
+		case cursor.In(row.valueRect):
+			m.focus = row.visibleRank
+			if row.field.set != nil {
+				m.editing, m.text = true, ""
+			}
+		}
+	}
+	if cursor.In(m.buttons[0]) {
+		m.submit()
+	}
+	if cursor.In(m.buttons[1]) {
+		m.quit = true
+	}
+}
+
+// Fonction qui valide la saisie en cours dans une ligne
+func (m *startMenu) commit(field *menuField) {
+	m.editing = false
+	if err := field.set(strings.TrimSpace(m.text)); err != nil {
+		m.message = err.Error()
+		return
+	}
+	m.message = ""
+}
+
+// Fonction qui demande le lancement de la simulation si la configuration est valide
+func (m *startMenu) submit() {
+	if _, err := m.finalConfig(); err != nil {
+		m.message = err.Error()
+		return
+	}
+	m.message = ""
+	m.launch = true
+}
+
+// Fonction d'affichage de l'écran de démarrage
+func (m *startMenu) draw(screen *ebiten.Image) {
+	screen.Fill(color.RGBA{57, 61, 125, 255})
+
+	fields := m.visibleFields()
+	height := (len(fields)+6)*MenuRowHeight + 2*PanelPadding
+	x := (screen.Bounds().Dx() - MenuWidth) / 2
+	y := (screen.Bounds().Dy() - height) / 2
+	vector.DrawFilledRect(screen, float32(x), float32(y), MenuWidth, float32(height), color.RGBA{0, 0, 0, 180}, false)
+
+	x += PanelPadding
+	y += PanelPadding
+	ebitenutil.DebugPrintAt(screen, "Bienvenue dans la simulation de la Gophétie !", x, y)
+	y += MenuRowHeight
+	ebitenutil.DebugPrintAt(screen, "[Haut/Bas] ligne  [Gauche/Droite] valeur (Maj: x10)  clavier: saisie  [Entrée] lancer", x, y)
+	y += 2 * MenuRowHeight
+
+	m.rows = m.rows[:0]
+	valueX := x + MenuLabelWidth
+	valueWidth := MenuWidth - MenuLabelWidth - 2*PanelPadding - 2*MenuArrowWidth - 8
+	for rank, field := range fields {
+		if rank == m.focus {
+			vector.DrawFilledRect(screen, float32(x-4), float32(y-4), MenuWidth-2*PanelPadding+8, MenuRowHeight-2, color.RGBA{120, 120, 40, 200}, false)
+		}
+		ebitenutil.DebugPrintAt(screen, field.label, x, y)
+
+		row := menuRow{field: field, visibleRank: rank}
+		row.prev = image.Rect(valueX, y-2, valueX+MenuArrowWidth, y+PanelLineHeight)
+		row.valueRect = image.Rect(row.prev.Max.X+4, y-2, row.prev.Max.X+4+valueWidth, y+PanelLineHeight)
+		row.next = image.Rect(row.valueRect.Max.X+4, y-2, row.valueRect.Max.X+4+MenuArrowWidth, y+PanelLineHeight)
+		drawMenuButton(screen, row.prev, "<")
+		drawMenuButton(screen, row.next, ">")
+
+		value := field.value()
+		if m.editing && rank == m.focus {
+			value = m.text + "_"
+		}
+		if maxRunes := valueWidth/6 - 1; len([]rune(value)) > maxRunes {
+			value = "..." + string([]rune(value)[len([]rune(value))-maxRunes+3:])
+		}
+		ebitenutil.DebugPrintAt(screen, value, row.valueRect.Min.X+4, y)
+
+		m.rows = append(m.rows, row)
+		y += MenuRowHeight
+	}
+
+	// Message d'erreur
+	y += MenuRowHeight / 2
+	if m.message != "" {
+		ebitenutil.DebugPrintAt(screen, "Erreur: "+m.message, x, y)
+	}
+	y += MenuRowHeight
+
+	// Boutons de lancement et de sortie
+	m.buttons[0] = image.Rect(x, y, x+MenuButtonWidth, y+ControlButtonHeight)
+	m.buttons[1] = image.Rect(x+MenuButtonWidth+10, y, x+2*MenuButtonWidth+10, y+ControlButtonHeight)
+	drawMenuButton(screen, m.buttons[0], "Lancer")
+	drawMenuButton(screen, m.buttons[1], "Quitter")
+}
+
+// Fonction d'affichage d'un bouton avec son libellé centré
+func drawMenuButton(screen *ebiten.Image, rect image.Rectangle, label string) {
+	vector.DrawFilledRect(screen, float32(rect.Min.X), float32(rect.Min.Y), float32(rect.Dx()), float32(rect.Dy()), color.RGBA{60, 60, 60, 255}, false)
+	vector.StrokeRect(screen, float32(rect.Min.X), float32(rect.Min.Y), float32(rect.Dx()), float32(rect.Dy()), 1, color.RGBA{200, 200, 200, 255}, false)
+	ebitenutil.DebugPrintAt(screen, label, rect.Min.X+(rect.Dx()-len([]rune(label))*6)/2, rect.Min.Y+(rect.Dy()-PanelLineHeight)/2+1)
+}
@@ -0,0 +1,76 @@
+package simulation
+
+import (
+	"image"
+	"log"
+
+	tile "github.com/Tmegaa/The-Gophecy/pkg/Tile"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Jeu de tiles affiché par Ebiten: les tiles décodées par le package tile sont converties en images Ebiten
+// la première fois qu'elles sont dessinées
+type tilesetImages struct {
+	tileset *tile.ImageTileset
+	imgs    map[int]*ebiten.Image
+}
+
+// Fonction qui renvoie l'image Ebiten d'une tile à partir de son identifiant global, ou nil si elle n'a pas d'image
+func (t *tilesetImages) Img(gid int) *ebiten.Image {
+	if img, ok := t.imgs[gid]; ok {
+		return img
+	}
+	var img *ebiten.Image
+	if decoded := t.tileset.Image(gid); decoded != nil && !decoded.Bounds().Empty() {
+		img = ebiten.NewImageFromImage(decoded)
+	}
+	t.imgs[gid] = img
+	return img
+}
+
+// Fonction qui charge les jeux de tiles de la carte pour l'affichage, dans l'ordre de ses Tilesets
+func loadTilesets(tilemapJSON *tile.TilemapJSON) []*tilesetImages {
+	decoded, err := tilemapJSON.GenImageTilesets()
+	if err != nil {
+		log.Fatalf("Failed to generate tilesets, error: %v", err)
+	}
+	tilesets := make([]*tilesetImages, len(decoded))
+	for i, tileset := range decoded {
+		tilesets[i] = &tilesetImages{tileset: tileset, imgs: make(map[int]*ebiten.Image)}
+	}
+	return tilesets
+}
+
+// Fonction qui renvoie l'image d'une tile à partir de son identifiant global, ou nil si aucun jeu de tiles ne la contient.
+// L'image n'est pas retournée: flipGeoM applique les retournements au dessin
+func tileImage(tilemapJSON *tile.TilemapJSON, tilesets []*tilesetImages, gid int) *ebiten.Image {
+	index := tilemapJSON.TilesetIndex(gid)
+	if index < 0 {
+		return nil
+	}
+	return tilesets[index].Img(tile.TileID(gid))
+}
+
+// Fonction qui ajoute à geoM les retournements d'une tile dont l'image a la taille size: comme dans TILED, les axes
+// sont d'abord échangés, puis l'image est retournée horizontalement et verticalement dans le rectangle qu'elle occupe
+func flipGeoM(geoM *ebiten.GeoM, gid int, size image.Point) {
+	horizontal, vertical, diagonal := tile.Flips(gid)
+	width, height := float64(size.X), float64(size.Y)
+	if diagonal {
+		var transpose ebiten.GeoM
+		transpose.SetElement(0, 0, 0)
+		transpose.SetElement(0, 1, 1)
+		transpose.SetElement(1, 0, 1)
+		transpose.SetElement(1, 1, 0)
+		geoM.Concat(transpose)
+		width, height = height, width
+	}
+	if horizontal {
+		geoM.Scale(-1, 1)
+		geoM.Translate(width, 0)
+	}
+	if vertical {
+		geoM.Scale(1, -1)
+		geoM.Translate(0, height)
+	}
+}
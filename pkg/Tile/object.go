@@ -0,0 +1,89 @@
+package tile
+
+import (
+	"image"
+	"math"
+	"strings"
+)
+
+// Point d'un polygone TILED, relatif à la position de son objet
+type PointJSON struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Objet d'une couche d'objets TILED: rectangle, ellipse, point, polygone ou tile placée librement.
+// Les coordonnées sont en pixels; pour une tile (GID non nul), (X, Y) est le coin inférieur gauche
+type ObjectJSON struct {
+	Id         int         `json:"id"`
+	Name       string      `json:"name"`
+	Type       string      `json:"type"`
+	Class      string      `json:"class"`
+	X          float64     `json:"x"`
+	Y          float64     `json:"y"`
+	Width      float64     `json:"width"`
+	Height     float64     `json:"height"`
+	GID        int         `json:"gid"`
+	Point      bool        `json:"point"`
+	Ellipse    bool        `json:"ellipse"`
+	Polygon    []PointJSON `json:"polygon"`
+	Properties Properties  `json:"properties"`
+}
+
+// Fonction qui renvoie la classe de l'objet ("type" avant TILED 1.9, "class" ensuite), en minuscules
+func (o ObjectJSON) Kind() string {
+	if o.Class != "" {
+		return strings.ToLower(o.Class)
+	}
+	return strings.ToLower(o.Type)
+}
+
+// Fonction qui renvoie le rectangle englobant l'objet, vide pour un point
+func (o ObjectJSON) Bounds() image.Rectangle {
+	switch {
+	case o.Point:
+		x, y := int(math.Round(o.X)), int(math.Round(o.Y))
+		return image.Rect(x, y, x, y)
+	case len(o.Polygon) > 0:
+		minX, minY := math.Inf(1), math.Inf(1)
+		maxX, maxY := math.Inf(-1), math.Inf(-1)
+		for _, p := range o.Polygon {
+			minX, minY = math.Min(minX, o.X+p.X), math.Min(minY, o.Y+p.Y)
+			maxX, maxY = math.Max(maxX, o.X+p.X), math.Max(maxY, o.Y+p.Y)
+		}
+		return image.Rect(int(math.Floor(minX)), int(math.Floor(minY)), int(math.Ceil(maxX)), int(math.Ceil(maxY)))
+	case o.GID != 0:
+		return image.Rect(int(o.X), int(o.Y-o.Height), int(o.X+o.Width), int(o.Y))
+	default:
+		return image.Rect(int(o.X), int(o.Y), int(o.X+o.Width), int(o.Y+o.Height))
+	}
+}
+
+// Fonction qui indique si le point donné (en pixels) est à l'intérieur de l'objet; un point ne contient rien
+func (o ObjectJSON) Contains(x, y float64) bool {
+	switch {
+	case o.Point:
+		return false
+	case len(o.Polygon) > 0:
+		// Règle pair-impair: on compte les côtés croisés par une demi-droite horizontale
+		inside := false
+		for i, j := 0, len(o.Polygon)-1; i < len(o.Polygon); j, i = i, i+1 {
+			xi, yi := o.X+o.Polygon[i].X, o.Y+o.Polygon[i].Y
+			xj, yj := o.X+o.Polygon[j].X, o.Y+o.Polygon[j].Y
+			if (yi > y) != (yj > y) && x < (xj-xi)*(y-yi)/(yj-yi)+xi {
+				inside = !inside
+			}
+		}
+		return inside
+	case o.Ellipse:
+		if o.Width <= 0 || o.Height <= 0 {
+			return false
+		}
+		dx := (x - o.X - o.Width/2) / (o.Width / 2)
+		dy := (y - o.Y - o.Height/2) / (o.Height / 2)
+		return dx*dx+dy*dy <= 1
+	default:
+		bounds := o.Bounds()
+		return x >= float64(bounds.Min.X) && x < float64(bounds.Max.X) && y >= float64(bounds.Min.Y) && y < float64(bounds.Max.Y)
+	}
+}
@@ -0,0 +1,117 @@
+package tile
+
+import "strings"
+
+// Rôles d'une couche, d'une tile ou d'un objet: ils sont donnés par une propriété personnalisée booléenne de TILED
+// portant le nom du rôle, ou à défaut par la classe de l'objet ou le nom de la couche ("collider", "computers", "statue"...)
+const (
+	ColliderRole = "collider" // obstacle que les agents ne peuvent pas traverser
+	ComputerRole = "computer" // ordinateur que les agents peuvent utiliser
+	StatueRole   = "statue"   // statue devant laquelle les agents peuvent prier
+	SpawnRole    = "spawn"    // case où les agents peuvent apparaître
+	WalkableRole = "walkable" // sol sur lequel les agents peuvent marcher
+	ZoneRole     = "zone"     // zone nommée (salle, chapelle...) dont la classe donne le type et les propriétés les règles
+)
+
+// Propriété personnalisée d'une couche, d'un jeu de tiles ou d'une tile
+type PropertyJSON struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value any    `json:"value"`
+}
+
+// Liste de propriétés personnalisées
+type Properties []PropertyJSON
+
+// Fonction qui renvoie la valeur d'une propriété booléenne et indique si elle est définie
+func (p Properties) Bool(name string) (bool, bool) {
+	for _, property := range p {
+		if property.Name == name {
+			value, ok := property.Value.(bool)
+			return value, ok
+		}
+	}
+	return false, false
+}
+
+// Fonction qui renvoie la valeur d'une propriété numérique (int ou float) et indique si elle est définie
+func (p Properties) Float(name string) (float64, bool) {
+	for _, property := range p {
+		if property.Name == name {
+			value, ok := property.Value.(float64)
+			return value, ok
+		}
+	}
+	return 0, false
+}
+
+// Fonction qui indique si la couche a le rôle donné: la propriété personnalisée est prioritaire sur le nom de la couche.
+// Les couches d'ordinateurs et de statues sont aussi des obstacles, sauf si leur propriété "collider" vaut false
+func (l TilemapLayerJSON) Has(role string) bool {
+	if value, ok := l.Properties.Bool(role); ok {
+		return value
+	}
+	if role == ColliderRole && (l.Has(ComputerRole) || l.Has(StatueRole)) {
+		return true
+	}
+	return namesRole(l.Name, role)
+}
+
+// Fonction qui renvoie les propriétés personnalisées des tiles de la carte, indexées par identifiant global
+// sans bits de retournement (voir TileID)
+func (t *TilemapJSON) TileProperties() map[int]Properties {
+	properties := make(map[int]Properties)
+	for _, tileset := range t.Tilesets {
+		for _, tile := range tileset.Tiles {
+			if len(tile.Properties) > 0 {
+				properties[tileset.FirstGID+tile.Id] = tile.Properties
+			}
+		}
+	}
+	return properties
+}
+
+// Fonction qui indique si une tile a le rôle donné, d'après sa couche ou ses propriétés personnalisées
+func HasRole(layer TilemapLayerJSON, properties Properties, role string) bool {
+	if value, ok := properties.Bool(role); ok {
+		return value
+	}
+	if role == ColliderRole {
+		computer, _ := properties.Bool(ComputerRole)
+		statue, _ := properties.Bool(StatueRole)
+		if computer || statue {
+			return true
+		}
+	}
+	return layer.Has(role)
+}
+
+// Fonction qui indique si un objet a le rôle donné: ses propriétés et celles de sa tile sont prioritaires,
+// puis sa classe ("spawn", "computer"...) et enfin sa couche
+func HasObjectRole(layer TilemapLayerJSON, object ObjectJSON, tileProperties Properties, role string) bool {
+	properties := append(append(Properties{}, object.Properties...), tileProperties...)
+	if value, ok := properties.Bool(role); ok {
+		return value
+	}
+	if kind := object.Kind(); kind != "" {
+		if role == ColliderRole && (namesRole(kind, ComputerRole) || namesRole(kind, StatueRole)) {
+			return true
+		}
+		return namesRole(kind, role)
+	}
+	return HasRole(layer, properties, role)
+}
+
+// Fonction qui indique si un objet est une zone nommée: sa propriété "zone" est prioritaire sur sa couche
+func IsZone(layer TilemapLayerJSON, object ObjectJSON) bool {
+	if value, ok := object.Properties.Bool(ZoneRole); ok {
+		return value
+	}
+	return layer.Type == ObjectLayer && layer.Has(ZoneRole)
+}
+
+// Fonction qui indique si un nom de couche ou de classe désigne le rôle donné, au singulier ou au pluriel
+func namesRole(name, role string) bool {
+	name = strings.ToLower(name)
+	return name == role || name == role+"s"
+}
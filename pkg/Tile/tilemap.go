@@ -1,36 +1,167 @@
 package tile
 
 import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
 	"encoding/json"
-	"os"
+	"fmt"
+	"image"
+	"io"
+	"io/fs"
 	"path"
+	"strconv"
+	"strings"
+
+	"github.com/Tmegaa/The-Gophecy/assets"
+)
+
+// Types de couches TILED
+const (
+	TileLayer   = "tilelayer"
+	ObjectLayer = "objectgroup"
+	GroupLayer  = "group"
+	ImageLayer  = "imagelayer"
 )
 
-// Données que nous voulons pour une couche dans notre liste de couches
+// Les trois bits de poids fort d'un identifiant global indiquent les retournements de la tile: ils sont conservés
+// dans les données des couches et masqués à chaque recherche dans les jeux de tiles
+const (
+	FlipHorizontal = 0x80000000 // retournement horizontal
+	FlipVertical   = 0x40000000 // retournement vertical
+	FlipDiagonal   = 0x20000000 // échange des axes, appliqué avant les deux autres retournements
+	gidMask        = 0x1FFFFFFF
+)
+
+// Fonction qui renvoie l'identifiant global d'une tile sans ses bits de retournement
+func TileID(gid int) int {
+	return gid & gidMask
+}
+
+// Fonction qui renvoie les retournements indiqués par les bits de poids fort d'un identifiant global
+func Flips(gid int) (horizontal, vertical, diagonal bool) {
+	return gid&FlipHorizontal != 0, gid&FlipVertical != 0, gid&FlipDiagonal != 0
+}
+
+// Fonction qui renvoie la taille occupée par une tile dont l'image a la taille size, une fois retournée:
+// l'échange des axes intervertit la largeur et la hauteur
+func FlippedSize(gid int, size image.Point) image.Point {
+	if _, _, diagonal := Flips(gid); diagonal {
+		return image.Pt(size.Y, size.X)
+	}
+	return size
+}
+
+// Données que nous voulons pour une couche dans notre liste de couches.
+// Les couches de groupe sont mises à plat au chargement: Layers n'est renseigné que pendant la lecture
 type TilemapLayerJSON struct {
-	Data   []int  `json:"data"`
-	Width  int    `json:"width"`
-	Height int    `json:"height"`
-	Name   string `json:"name"`
+	Data        []int              `json:"data"`
+	Encoding    string             `json:"encoding"`
+	Compression string             `json:"compression"`
+	Width       int                `json:"width"`
+	Height      int                `json:"height"`
+	Name        string             `json:"name"`
+	Type        string             `json:"type"`
+	Class       string             `json:"class"`
+	Properties  Properties         `json:"properties"`
+	Objects     []ObjectJSON       `json:"objects"`
+	Layers      []TilemapLayerJSON `json:"layers"`
 }
 
-// Toutes les couches dans un jeu de tiles
+// Fonction de désérialisation d'une couche: les données des tiles peuvent être un tableau ou une chaîne encodée en base64
+func (l *TilemapLayerJSON) UnmarshalJSON(contents []byte) error {
+	type layerJSON TilemapLayerJSON
+	var raw struct {
+		layerJSON
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(contents, &raw); err != nil {
+		return err
+	}
+	*l = TilemapLayerJSON(raw.layerJSON)
+
+	if len(raw.Data) == 0 {
+		return nil
+	}
+	if raw.Data[0] != '"' {
+		return json.Unmarshal(raw.Data, &l.Data)
+	}
+	var data string
+	if err := json.Unmarshal(raw.Data, &data); err != nil {
+		return err
+	}
+	var err error
+	l.Data, err = decodeLayerData(data, l.Encoding, l.Compression)
+	return err
+}
+
+// Carte TILED, lue depuis un fichier JSON (.json, .tmj) ou XML (.tmx)
 type TilemapJSON struct {
-	Layers   []TilemapLayerJSON `json:"layers"`
-	Tilesets []map[string]any   `json:"tilesets"`
+	Width      int                `json:"width"`
+	Height     int                `json:"height"`
+	TileWidth  int                `json:"tilewidth"`
+	TileHeight int                `json:"tileheight"`
+	Infinite   bool               `json:"infinite"`
+	Layers     []TilemapLayerJSON `json:"layers"`
+	Tilesets   []TilesetJSON      `json:"tilesets"`
+	Properties Properties         `json:"properties"`
+
+	fsys fs.FS // système de fichiers d'où la carte a été lue, pour charger ses jeux de tiles et leurs images
 }
 
-// Fonction de génération des jeux de tiles
-func (t *TilemapJSON) GenTilesets() ([]Tileset, error) {
+// Fonction qui renvoie l'indice du jeu de tiles contenant l'identifiant global donné, ou -1
+func (t *TilemapJSON) TilesetIndex(gid int) int {
+	gid = TileID(gid)
+	index := -1
+	for i, tileset := range t.Tilesets {
+		if tileset.FirstGID <= gid && (index < 0 || tileset.FirstGID >= t.Tilesets[index].FirstGID) {
+			index = i
+		}
+	}
+	return index
+}
 
-	tilesets := make([]Tileset, 0)
+// Fonction qui renvoie les données de la tile d'identifiant global donné, si son jeu de tiles les décrit
+func (t *TilemapJSON) Tile(gid int) (TileJSON, bool) {
+	index := t.TilesetIndex(gid)
+	if index < 0 {
+		return TileJSON{}, false
+	}
+	tileset := &t.Tilesets[index]
+	for _, tile := range tileset.Tiles {
+		if tileset.FirstGID+tile.Id == TileID(gid) {
+			return tile, true
+		}
+	}
+	return TileJSON{}, false
+}
 
-	for _, tilesetData := range t.Tilesets {
-		tilesetPath := path.Join("assets/maps", tilesetData["source"].(string))
+// Fonction qui renvoie la taille occupée par la tile d'identifiant global donné, retournements compris, sans charger d'image:
+// celle des tiles d'un jeu à image unique, ou celle de l'image propre de la tile dans une collection d'images
+func (t *TilemapJSON) TileSize(gid int) (image.Point, bool) {
+	index := t.TilesetIndex(gid)
+	if index < 0 {
+		return image.Point{}, false
+	}
+	tileset := &t.Tilesets[index]
+	if !tileset.IsCollection() {
+		return FlippedSize(gid, image.Pt(tileset.TileWidth, tileset.TileHeight)), true
+	}
+	tile, ok := t.Tile(gid)
+	if !ok || tile.Path == "" {
+		return image.Point{}, false
+	}
+	return FlippedSize(gid, image.Pt(tile.Width, tile.Height)), true
+}
 
-		tileset, err := NewTileset(tilesetPath, int(tilesetData["firstgid"].(float64)))
+// Fonction de génération des jeux de tiles décodés en images Go standard, dans l'ordre de Tilesets
+func (t *TilemapJSON) GenImageTilesets() ([]*ImageTileset, error) {
+	tilesets := make([]*ImageTileset, 0, len(t.Tilesets))
+	for i := range t.Tilesets {
+		tileset, err := NewImageTileset(t.fsys, &t.Tilesets[i])
 		if err != nil {
-
 			return nil, err
 		}
 		tilesets = append(tilesets, tileset)
@@ -38,18 +169,187 @@ func (t *TilemapJSON) GenTilesets() ([]Tileset, error) {
 	return tilesets, nil
 }
 
-// Ouvre le fichier, l'analyse et renvoie l'objet json et erreur potentielle
+// Ouvre le fichier, l'analyse et renvoie la carte et erreur potentielle.
+// Le fichier est lu sur le disque s'il existe, sinon parmi les ressources intégrées au programme
 func NewTilemapJSON(filepath string) (*TilemapJSON, error) {
-	contents, err := os.ReadFile(filepath)
+	fsys, name, err := assets.Resolve(filepath)
 	if err != nil {
 		return nil, err
 	}
+	return LoadTilemap(fsys, name)
+}
+
+// Fonction qui lit une carte dans un système de fichiers; ses jeux de tiles et ses images sont cherchés relativement à elle.
+// Le format est déduit de l'extension: XML pour .tmx, JSON sinon
+func LoadTilemap(fsys fs.FS, name string) (*TilemapJSON, error) {
+	contents, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	return ParseTilemap(fsys, name, contents)
+}
 
-	var tilemapJSON TilemapJSON
-	err = json.Unmarshal(contents, &tilemapJSON)
+// Fonction qui analyse le contenu d'une carte comme si elle était le fichier name du système de fichiers,
+// par exemple pour une carte générée en mémoire qui utilise les jeux de tiles fournis
+func ParseTilemap(fsys fs.FS, name string, contents []byte) (*TilemapJSON, error) {
+	var tilemapJSON *TilemapJSON
+	var err error
+	if strings.EqualFold(path.Ext(name), ".tmx") {
+		tilemapJSON, err = parseTMX(contents)
+	} else {
+		tilemapJSON = &TilemapJSON{}
+		err = json.Unmarshal(contents, tilemapJSON)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	return &tilemapJSON, nil
+	tilemapJSON.fsys = fsys
+	if err := tilemapJSON.resolve(path.Dir(name)); err != nil {
+		return nil, err
+	}
+	return tilemapJSON, nil
+}
+
+// Fonction qui complète la carte lue: couches de groupe mises à plat,
+// jeux de tiles externes chargés et chemins des images rendus relatifs à la racine du système de fichiers
+func (t *TilemapJSON) resolve(dir string) error {
+	if t.Infinite {
+		return fmt.Errorf("les cartes infinies ne sont pas prises en charge")
+	}
+
+	t.Layers = flattenLayers(t.Layers)
+	for i := range t.Layers {
+		layer := &t.Layers[i]
+		if layer.Type == "" {
+			layer.Type = TileLayer
+		}
+	}
+
+	for i := range t.Tilesets {
+		tileset := &t.Tilesets[i]
+		tilesetDir := dir
+		if tileset.Source != "" {
+			source := path.Join(dir, slashPath(tileset.Source))
+			external, err := loadTilesetFile(t.fsys, source)
+			if err != nil {
+				return fmt.Errorf("échec du chargement du jeu de tiles %s: %v", source, err)
+			}
+			external.FirstGID, external.Source = tileset.FirstGID, tileset.Source
+			*tileset = *external
+			tilesetDir = path.Dir(source)
+		}
+
+		if tileset.Path != "" {
+			tileset.Path = path.Join(tilesetDir, slashPath(tileset.Path))
+		}
+		for j := range tileset.Tiles {
+			tile := &tileset.Tiles[j]
+			if tile.Path == "" {
+				continue
+			}
+			tile.Path = path.Join(tilesetDir, slashPath(tile.Path))
+
+			// La taille des tiles sert à placer les obstacles: on la lit dans l'image si le jeu de tiles ne la donne pas
+			if tile.Width <= 0 || tile.Height <= 0 {
+				size, err := decodeImageSize(t.fsys, tile.Path)
+				if err != nil {
+					return fmt.Errorf("échec de la lecture de l'image %s: %v", tile.Path, err)
+				}
+				tile.Width, tile.Height = size.X, size.Y
+			}
+		}
+	}
+	return nil
+}
+
+// Fonction qui met à plat les couches de groupe, en transmettant leurs propriétés aux couches qu'elles contiennent
+func flattenLayers(layers []TilemapLayerJSON) []TilemapLayerJSON {
+	flat := make([]TilemapLayerJSON, 0, len(layers))
+	for _, layer := range layers {
+		if layer.Type != GroupLayer {
+			flat = append(flat, layer)
+			continue
+		}
+		for _, child := range flattenLayers(layer.Layers) {
+			child.Properties = append(append(Properties{}, child.Properties...), layer.Properties...)
+			flat = append(flat, child)
+		}
+	}
+	return flat
+}
+
+// Fonction qui lit un jeu de tiles externe au format JSON (.json, .tsj) ou XML (.tsx)
+func loadTilesetFile(fsys fs.FS, name string) (*TilesetJSON, error) {
+	contents, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(path.Ext(name), ".tsx") {
+		return parseTSX(contents)
+	}
+	var tileset TilesetJSON
+	if err := json.Unmarshal(contents, &tileset); err != nil {
+		return nil, err
+	}
+	return &tileset, nil
+}
+
+// Fonction qui décode les données d'une couche de tiles (CSV ou base64, éventuellement compressées en zlib ou gzip).
+// Les identifiants gardent leurs bits de retournement, masqués par TileID lors des recherches
+func decodeLayerData(data, encoding, compression string) ([]int, error) {
+	switch encoding {
+	case "csv":
+		var gids []int
+		for _, field := range strings.Split(data, ",") {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue
+			}
+			gid, err := strconv.ParseUint(field, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("donnée de couche invalide %q: %v", field, err)
+			}
+			gids = append(gids, int(gid))
+		}
+		return gids, nil
+
+	case "base64":
+		raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(data))
+		if err != nil {
+			return nil, err
+		}
+
+		var reader io.Reader = bytes.NewReader(raw)
+		switch compression {
+		case "":
+		case "zlib":
+			if reader, err = zlib.NewReader(reader); err != nil {
+				return nil, err
+			}
+		case "gzip":
+			if reader, err = gzip.NewReader(reader); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("compression %q non prise en charge", compression)
+		}
+		if raw, err = io.ReadAll(reader); err != nil {
+			return nil, err
+		}
+
+		gids := make([]int, len(raw)/4)
+		for i := range gids {
+			gids[i] = int(binary.LittleEndian.Uint32(raw[4*i:]))
+		}
+		return gids, nil
+
+	default:
+		return nil, fmt.Errorf("encodage %q non pris en charge", encoding)
+	}
+}
+
+// Fonction qui convertit un chemin TILED (éventuellement avec des séparateurs Windows) en chemin à barres obliques
+func slashPath(p string) string {
+	return strings.ReplaceAll(p, "\\", "/")
 }
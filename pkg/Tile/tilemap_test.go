@@ -0,0 +1,211 @@
+package tile
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"image"
+	"io"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+// Fonction qui encode des identifiants globaux comme TILED: entiers de 32 bits petit-boutistes, éventuellement compressés
+func encodeLayerData(t *testing.T, gids []int, compression string) string {
+	t.Helper()
+	raw := make([]byte, 4*len(gids))
+	for i, gid := range gids {
+		binary.LittleEndian.PutUint32(raw[4*i:], uint32(gid))
+	}
+
+	var buf bytes.Buffer
+	var writer io.WriteCloser
+	switch compression {
+	case "":
+		return base64.StdEncoding.EncodeToString(raw)
+	case "zlib":
+		writer = zlib.NewWriter(&buf)
+	case "gzip":
+		writer = gzip.NewWriter(&buf)
+	}
+	if _, err := writer.Write(raw); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func TestDecodeLayerData(t *testing.T) {
+	// Les bits de retournement sont conservés dans les données
+	gids := []int{1, 0, FlipHorizontal | 3, FlipVertical | FlipDiagonal | 12}
+	tests := []struct {
+		name        string
+		data        string
+		encoding    string
+		compression string
+	}{
+		{"csv", "1,0,\n2147483651,\n1610612748", "csv", ""},
+		{"base64", encodeLayerData(t, gids, ""), "base64", ""},
+		{"zlib", encodeLayerData(t, gids, "zlib"), "base64", "zlib"},
+		{"gzip", encodeLayerData(t, gids, "gzip"), "base64", "gzip"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeLayerData(tt.data, tt.encoding, tt.compression)
+			if err != nil {
+				t.Fatalf("erreur inattendue: %v", err)
+			}
+			if !slices.Equal(got, gids) {
+				t.Errorf("données %v, attendu %v", got, gids)
+			}
+		})
+	}
+
+	for _, tt := range []struct{ name, data, encoding, compression string }{
+		{"encodage inconnu", "AAAA", "base32", ""},
+		{"compression inconnue", "AAAA", "base64", "zstd"},
+		{"csv invalide", "1,a", "csv", ""},
+	} {
+		if _, err := decodeLayerData(tt.data, tt.encoding, tt.compression); err == nil {
+			t.Errorf("%s: pas d'erreur", tt.name)
+		}
+	}
+}
+
+func TestTileIDMasksFlips(t *testing.T) {
+	tests := []struct {
+		gid                            int
+		id                             int
+		horizontal, vertical, diagonal bool
+		size                           image.Point
+	}{
+		{gid: 7, id: 7, size: image.Pt(24, 48)},
+		{gid: FlipHorizontal | 7, id: 7, horizontal: true, size: image.Pt(24, 48)},
+		{gid: FlipVertical | 7, id: 7, vertical: true, size: image.Pt(24, 48)},
+		{gid: FlipDiagonal | FlipHorizontal | 7, id: 7, horizontal: true, diagonal: true, size: image.Pt(48, 24)},
+	}
+	for _, tt := range tests {
+		if id := TileID(tt.gid); id != tt.id {
+			t.Errorf("TileID(%#x) = %d, attendu %d", tt.gid, id, tt.id)
+		}
+		horizontal, vertical, diagonal := Flips(tt.gid)
+		if horizontal != tt.horizontal || vertical != tt.vertical || diagonal != tt.diagonal {
+			t.Errorf("Flips(%#x) = %v %v %v, attendu %v %v %v", tt.gid, horizontal, vertical, diagonal, tt.horizontal, tt.vertical, tt.diagonal)
+		}
+		if size := FlippedSize(tt.gid, image.Pt(24, 48)); size != tt.size {
+			t.Errorf("FlippedSize(%#x) = %v, attendu %v", tt.gid, size, tt.size)
+		}
+	}
+}
+
+func TestParseTilemapFlattensGroups(t *testing.T) {
+	contents := `{
+		"width": 2, "height": 1, "tilewidth": 24, "tileheight": 24,
+		"layers": [
+			{"type": "group", "name": "batiment", "properties": [{"name": "collider", "type": "bool", "value": true}],
+			 "layers": [
+				{"type": "tilelayer", "name": "murs", "width": 2, "height": 1, "data": [1, 2]},
+				{"type": "group", "name": "etage", "properties": [{"name": "spawn", "type": "bool", "value": false}],
+				 "layers": [{"name": "sol", "width": 2, "height": 1, "encoding": "base64", "data": "` + encodeLayerData(t, []int{FlipHorizontal | 1, 0}, "") + `"}]}
+			 ]},
+			{"type": "objectgroup", "name": "zones", "objects": []}
+		]
+	}`
+	tilemap, err := ParseTilemap(fstest.MapFS{}, "maps/carte.json", []byte(contents))
+	if err != nil {
+		t.Fatalf("erreur inattendue: %v", err)
+	}
+
+	var names []string
+	for _, layer := range tilemap.Layers {
+		names = append(names, layer.Name)
+	}
+	if want := []string{"murs", "sol", "zones"}; !slices.Equal(names, want) {
+		t.Fatalf("couches %v, attendu %v", names, want)
+	}
+
+	// Les propriétés des groupes sont transmises à leurs couches, le groupe le plus proche en premier
+	murs, sol := tilemap.Layers[0], tilemap.Layers[1]
+	if collider, _ := murs.Properties.Bool("collider"); !collider {
+		t.Errorf("murs: propriété collider du groupe non transmise")
+	}
+	if len(sol.Properties) != 2 || sol.Properties[0].Name != "spawn" || sol.Properties[1].Name != "collider" {
+		t.Errorf("sol: propriétés %v, attendu spawn puis collider", sol.Properties)
+	}
+	if sol.Type != TileLayer {
+		t.Errorf("sol: type %q, attendu %q par défaut", sol.Type, TileLayer)
+	}
+	if want := []int{FlipHorizontal | 1, 0}; !slices.Equal(sol.Data, want) {
+		t.Errorf("sol: données %v, attendu %v", sol.Data, want)
+	}
+}
+
+func TestPolygonObject(t *testing.T) {
+	// Triangle rectangle dont l'angle droit est en bas à gauche
+	triangle := ObjectJSON{X: 100, Y: 50, Polygon: []PointJSON{{0, 0}, {0, 48}, {48, 48}}}
+	if bounds, want := triangle.Bounds(), image.Rect(100, 50, 148, 98); bounds != want {
+		t.Errorf("Bounds = %v, attendu %v", bounds, want)
+	}
+
+	tests := []struct {
+		x, y float64
+		want bool
+	}{
+		{110, 90, true},
+		{140, 60, false},
+		{124, 80, true},
+		{90, 70, false},
+	}
+	for _, tt := range tests {
+		if got := triangle.Contains(tt.x, tt.y); got != tt.want {
+			t.Errorf("Contains(%.0f, %.0f) = %v, attendu %v", tt.x, tt.y, got, tt.want)
+		}
+	}
+}
+
+func TestExternalTSXTileset(t *testing.T) {
+	fsys := fstest.MapFS{
+		"maps/carte.json": {Data: []byte(`{
+			"width": 1, "height": 1, "tilewidth": 24, "tileheight": 24,
+			"layers": [{"type": "tilelayer", "name": "objets", "width": 1, "height": 1, "data": [2684354565]}],
+			"tilesets": [{"firstgid": 4, "source": "tilesets\\objets.tsx"}]
+		}`)},
+		"maps/tilesets/objets.tsx": {Data: []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<tileset name="objets" tilewidth="24" tileheight="48" tilecount="1" columns="0">
+ <tile id="1" class="statue">
+  <properties><property name="statue" type="bool" value="true"/></properties>
+  <image source="images/statue.png" width="24" height="48"/>
+ </tile>
+</tileset>`)},
+	}
+	tilemap, err := LoadTilemap(fsys, "maps/carte.json")
+	if err != nil {
+		t.Fatalf("erreur inattendue: %v", err)
+	}
+
+	tileset := tilemap.Tilesets[0]
+	if tileset.FirstGID != 4 || tileset.Name != "objets" || !tileset.IsCollection() {
+		t.Fatalf("jeu de tiles %+v mal chargé", tileset)
+	}
+
+	// La tile 5 (identifiant local 1) est retournée horizontalement et en diagonale
+	gid := tilemap.Layers[0].Data[0]
+	tile, ok := tilemap.Tile(gid)
+	if !ok {
+		t.Fatalf("tile %d introuvable", TileID(gid))
+	}
+	if tile.Path != "maps/tilesets/images/statue.png" || tile.Type != "statue" {
+		t.Errorf("tile %+v: chemin ou classe inattendus", tile)
+	}
+	if statue, _ := tilemap.TileProperties()[5].Bool(StatueRole); !statue {
+		t.Errorf("propriété statue de la tile non lue")
+	}
+	if size, ok := tilemap.TileSize(gid); !ok || size != image.Pt(48, 24) {
+		t.Errorf("TileSize = %v, attendu (48,24) une fois retournée", size)
+	}
+}
@@ -1,148 +1,131 @@
 package tile
 
 import (
-	"encoding/json"
 	"image"
-	"os"
-	"path/filepath"
-	"strings"
-
-	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	_ "image/png"
+	"io/fs"
 )
 
-// Chaque ensemble de tiles doit pouvoir donner une image à partir d'un identifiant
-type Tileset interface {
-	Img(id int) *ebiten.Image
-}
-
-// Des données du jeu de tiles désérialisées à partir d'un jeu de tiles standard à image unique
-type UniformTilesetJSON struct {
-	Path string `json:"image"`
+// Objet tile d'un jeu de tiles TILED: son image propre (jeux de tiles à collection d'images) et ses propriétés
+type TileJSON struct {
+	Id         int        `json:"id"`
+	Type       string     `json:"type"`
+	Path       string     `json:"image"`
+	Width      int        `json:"imagewidth"`
+	Height     int        `json:"imageheight"`
+	Properties Properties `json:"properties"`
 }
 
-// L'objet d'un jeu de tiles orienté vers l'avant utilisé pour les jeux de tiles à image unique
-type UniformTileset struct {
-	img *ebiten.Image
-	gid int
+// Jeu de tiles TILED, intégré à la carte ou lu depuis un fichier externe (.json ou .tsx) désigné par Source.
+// Une fois la carte chargée, les chemins des images sont ceux du système de fichiers de la carte
+type TilesetJSON struct {
+	FirstGID    int        `json:"firstgid"`
+	Source      string     `json:"source"`
+	Name        string     `json:"name"`
+	Path        string     `json:"image"`
+	ImageWidth  int        `json:"imagewidth"`
+	ImageHeight int        `json:"imageheight"`
+	TileWidth   int        `json:"tilewidth"`
+	TileHeight  int        `json:"tileheight"`
+	TileCount   int        `json:"tilecount"`
+	Columns     int        `json:"columns"`
+	Margin      int        `json:"margin"`
+	Spacing     int        `json:"spacing"`
+	Tiles       []TileJSON `json:"tiles"`
+	Properties  Properties `json:"properties"`
 }
 
-// Fonction qui renvoie une image à partir d'un identifiant
-func (u *UniformTileset) Img(id int) *ebiten.Image {
-	id -= u.gid
-
-	// Obtenir la position sur l'image où se trouve l'identifiant de la tile
-	srcX := id % 16
-	srcY := id / 16
-
-	// Convertir la position de la tile source en position source en pixels
-	srcX *= 24
-	srcY *= 24
-
-	return u.img.SubImage(
-		image.Rect(
-			srcX, srcY, srcX+24, srcY+24,
-		),
-	).(*ebiten.Image)
+// Fonction qui indique si le jeu de tiles est une collection d'images (une image par tile) plutôt qu'une image unique
+func (t *TilesetJSON) IsCollection() bool {
+	return t.Path == ""
 }
 
-// Objet tile à sérialiser en format JSON
-type TileJSON struct {
-	Id     int    `json:"id"`
-	Path   string `json:"image"`
-	Width  int    `json:"imagewidth"`
-	Height int    `json:"imageheight"`
+// Fonction qui renvoie le nombre de colonnes d'un jeu de tiles à image unique, calculé depuis l'image s'il n'est pas renseigné
+func (t *TilesetJSON) columns() int {
+	if t.Columns > 0 {
+		return t.Columns
+	}
+	if t.TileWidth+t.Spacing <= 0 {
+		return 1
+	}
+	return max(1, (t.ImageWidth-2*t.Margin+t.Spacing)/(t.TileWidth+t.Spacing))
 }
 
-// Objet de jeu de tiles à sérialiser en format JSON
-type DynTilesetJSON struct {
-	Tiles []*TileJSON `json:"tiles"`
+// Fonction qui renvoie la zone de l'image unique du jeu de tiles occupée par la tile d'identifiant local donné
+func (t *TilesetJSON) TileRect(id int) image.Rectangle {
+	columns := t.columns()
+	srcX := t.Margin + (id%columns)*(t.TileWidth+t.Spacing)
+	srcY := t.Margin + (id/columns)*(t.TileHeight+t.Spacing)
+	return image.Rect(srcX, srcY, srcX+t.TileWidth, srcY+t.TileHeight)
 }
 
-// Objet de jeu de tiles avec les images respectives et l'identifient du jeu de tiles
-type DynTileset struct {
-	imgs []*ebiten.Image
-	gid  int
+// Images décodées dont on peut extraire une partie (c'est le cas de tous les formats de la bibliothèque standard)
+type subImager interface {
+	SubImage(image.Rectangle) image.Image
 }
 
-// Fonction qui renvoie une image à partir d'un identifient pour un jeu de tiles donné
-func (d *DynTileset) Img(id int) *ebiten.Image {
-	id -= d.gid
-
-	return d.imgs[id]
+// Jeu de tiles décodé en images Go standard: l'affichage les convertit pour Ebiten, le rendu hors écran les dessine directement
+type ImageTileset struct {
+	img     image.Image         // image unique d'un jeu de tiles uniforme
+	imgs    map[int]image.Image // images d'un jeu de tiles à collection d'images
+	tileset *TilesetJSON
 }
 
-// Fonction de génération d'un jeu de tuiles
-func NewTileset(path string, gid int) (Tileset, error) {
-	// Lire le contenu du fichier
-	contents, err := os.ReadFile(path)
-	if err != nil {
-
-		return nil, err
+// Fonction qui renvoie l'image d'une tile à partir de son identifiant global, sans appliquer ses retournements
+func (t *ImageTileset) Image(id int) image.Image {
+	id = TileID(id) - t.tileset.FirstGID
+	if t.imgs != nil {
+		return t.imgs[id]
 	}
+	return t.img.(subImager).SubImage(t.tileset.TileRect(id))
+}
 
-	if strings.Contains(path, "obj") {
-		// Deserialization du contenu
-		var dynTilesetJSON DynTilesetJSON
-		err = json.Unmarshal(contents, &dynTilesetJSON)
-		if err != nil {
-
-			return nil, err
-		}
-
-		// Création du jeu de tiles
-		dynTileset := DynTileset{}
-		dynTileset.gid = gid
-		dynTileset.imgs = make([]*ebiten.Image, 0)
-
-		// On itère sur les données des tiles et on charge l'image pour chacune
-		for _, tileJSON := range dynTilesetJSON.Tiles {
-
-			// On rajoute un paramètre à l'objet JSON: isCollider (qui est un booléen)
-			tileJSONPath := tileJSON.Path
-			tileJSONPath = filepath.Clean(tileJSONPath)
-			tileJSONPath = strings.ReplaceAll(tileJSONPath, "\\", "/")
-			tileJSONPath = strings.TrimPrefix(tileJSONPath, "../")
-			tileJSONPath = strings.TrimPrefix(tileJSONPath, "../")
-			tileJSONPath = filepath.Join("assets/", tileJSONPath)
-
-			img, _, err := ebitenutil.NewImageFromFile(tileJSONPath)
+// Fonction de génération d'un jeu de tiles décodé en images Go standard
+func NewImageTileset(fsys fs.FS, tileset *TilesetJSON) (*ImageTileset, error) {
+	imageTileset := ImageTileset{tileset: tileset}
+	if tileset.IsCollection() {
+		imageTileset.imgs = make(map[int]image.Image, len(tileset.Tiles))
+		for _, tileJSON := range tileset.Tiles {
+			if tileJSON.Path == "" {
+				continue
+			}
+			img, err := decodeImage(fsys, tileJSON.Path)
 			if err != nil {
-
 				return nil, err
 			}
-
-			dynTileset.imgs = append(dynTileset.imgs, img)
+			imageTileset.imgs[tileJSON.Id] = img
 		}
-
-		return &dynTileset, nil
+		return &imageTileset, nil
 	}
-	// On retourne le jeu de tuiles uniforme
-	var uniformTilesetJSON UniformTilesetJSON
 
-	// Deserialization du contenu
-	err = json.Unmarshal(contents, &uniformTilesetJSON)
+	var err error
+	imageTileset.img, err = decodeImage(fsys, tileset.Path)
 	if err != nil {
 		return nil, err
 	}
+	return &imageTileset, nil
+}
 
-	uniformTileset := UniformTileset{}
+// Fonction qui renvoie la taille d'une image sans la décoder entièrement
+func decodeImageSize(fsys fs.FS, path string) (image.Point, error) {
+	file, err := fsys.Open(path)
+	if err != nil {
+		return image.Point{}, err
+	}
+	defer file.Close()
 
-	// On convertit le chemin relatif du jeu de tuiles en chemin relatif de la racine
-	tileJSONPath := uniformTilesetJSON.Path
-	tileJSONPath = filepath.Clean(tileJSONPath)
-	tileJSONPath = strings.ReplaceAll(tileJSONPath, "\\", "/")
-	tileJSONPath = strings.TrimPrefix(tileJSONPath, "../")
-	tileJSONPath = strings.TrimPrefix(tileJSONPath, "../")
-	tileJSONPath = filepath.Join("assets/", tileJSONPath)
+	config, _, err := image.DecodeConfig(file)
+	return image.Pt(config.Width, config.Height), err
+}
 
-	img, _, err := ebitenutil.NewImageFromFile(tileJSONPath)
+// Fonction qui décode une image depuis un fichier
+func decodeImage(fsys fs.FS, path string) (image.Image, error) {
+	file, err := fsys.Open(path)
 	if err != nil {
-
 		return nil, err
 	}
-	uniformTileset.img = img
-	uniformTileset.gid = gid
+	defer file.Close()
 
-	return &uniformTileset, nil
+	img, _, err := image.Decode(file)
+	return img, err
 }
@@ -0,0 +1,287 @@
+package tile
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Carte au format XML de TILED (.tmx). Les couches sont lues dans l'ordre du fichier, quel que soit leur type
+type tmxMap struct {
+	Width      int           `xml:"width,attr"`
+	Height     int           `xml:"height,attr"`
+	TileWidth  int           `xml:"tilewidth,attr"`
+	TileHeight int           `xml:"tileheight,attr"`
+	Infinite   bool          `xml:"infinite,attr"`
+	Tilesets   []tmxTileset  `xml:"tileset"`
+	Properties tmxProperties `xml:"properties"`
+	Layers     []tmxLayer    `xml:",any"`
+}
+
+// Couche XML: couche de tiles (<layer>), d'objets (<objectgroup>), d'image ou de groupe (<group>)
+type tmxLayer struct {
+	XMLName    xml.Name
+	Name       string        `xml:"name,attr"`
+	Class      string        `xml:"class,attr"`
+	Width      int           `xml:"width,attr"`
+	Height     int           `xml:"height,attr"`
+	Properties tmxProperties `xml:"properties"`
+	Data       tmxData       `xml:"data"`
+	Objects    []tmxObject   `xml:"object"`
+	Layers     []tmxLayer    `xml:",any"`
+}
+
+// Données d'une couche de tiles: texte encodé (CSV ou base64) ou une balise <tile> par case
+type tmxData struct {
+	Encoding    string `xml:"encoding,attr"`
+	Compression string `xml:"compression,attr"`
+	Text        string `xml:",chardata"`
+	Tiles       []struct {
+		GID uint32 `xml:"gid,attr"`
+	} `xml:"tile"`
+	Chunks []struct{} `xml:"chunk"`
+}
+
+// Objet XML; la forme est donnée par une balise enfant (<point/>, <ellipse/>, <polygon points="..."/>)
+type tmxObject struct {
+	Id         int           `xml:"id,attr"`
+	Name       string        `xml:"name,attr"`
+	Type       string        `xml:"type,attr"`
+	Class      string        `xml:"class,attr"`
+	X          float64       `xml:"x,attr"`
+	Y          float64       `xml:"y,attr"`
+	Width      float64       `xml:"width,attr"`
+	Height     float64       `xml:"height,attr"`
+	GID        uint32        `xml:"gid,attr"`
+	Properties tmxProperties `xml:"properties"`
+	Point      *struct{}     `xml:"point"`
+	Ellipse    *struct{}     `xml:"ellipse"`
+	Polygon    *struct {
+		Points string `xml:"points,attr"`
+	} `xml:"polygon"`
+}
+
+// Jeu de tiles XML, intégré à la carte ou dans un fichier .tsx
+type tmxTileset struct {
+	FirstGID   int           `xml:"firstgid,attr"`
+	Source     string        `xml:"source,attr"`
+	Name       string        `xml:"name,attr"`
+	TileWidth  int           `xml:"tilewidth,attr"`
+	TileHeight int           `xml:"tileheight,attr"`
+	TileCount  int           `xml:"tilecount,attr"`
+	Columns    int           `xml:"columns,attr"`
+	Margin     int           `xml:"margin,attr"`
+	Spacing    int           `xml:"spacing,attr"`
+	Image      tmxImage      `xml:"image"`
+	Properties tmxProperties `xml:"properties"`
+	Tiles      []struct {
+		Id         int           `xml:"id,attr"`
+		Type       string        `xml:"type,attr"`
+		Class      string        `xml:"class,attr"`
+		Image      tmxImage      `xml:"image"`
+		Properties tmxProperties `xml:"properties"`
+	} `xml:"tile"`
+}
+
+// Image d'un jeu de tiles ou d'une tile
+type tmxImage struct {
+	Source string `xml:"source,attr"`
+	Width  int    `xml:"width,attr"`
+	Height int    `xml:"height,attr"`
+}
+
+// Propriétés personnalisées XML: les valeurs sont des chaînes, converties selon leur type
+type tmxProperties struct {
+	Properties []struct {
+		Name  string `xml:"name,attr"`
+		Type  string `xml:"type,attr"`
+		Value string `xml:"value,attr"`
+		Text  string `xml:",chardata"`
+	} `xml:"property"`
+}
+
+// Fonction qui convertit des propriétés XML en propriétés au format JSON
+func (p tmxProperties) convert() Properties {
+	var properties Properties
+	for _, property := range p.Properties {
+		text := property.Value
+		if text == "" {
+			text = property.Text
+		}
+
+		var value any = text
+		switch property.Type {
+		case "bool":
+			value = text == "true"
+		case "int", "float", "object":
+			if number, err := strconv.ParseFloat(text, 64); err == nil {
+				value = number
+			}
+		}
+		properties = append(properties, PropertyJSON{Name: property.Name, Type: property.Type, Value: value})
+	}
+	return properties
+}
+
+// Fonction de lecture d'une carte au format TMX
+func parseTMX(contents []byte) (*TilemapJSON, error) {
+	var m tmxMap
+	if err := xml.Unmarshal(contents, &m); err != nil {
+		return nil, err
+	}
+
+	tilemap := TilemapJSON{
+		Width:      m.Width,
+		Height:     m.Height,
+		TileWidth:  m.TileWidth,
+		TileHeight: m.TileHeight,
+		Infinite:   m.Infinite,
+		Properties: m.Properties.convert(),
+	}
+	for _, tileset := range m.Tilesets {
+		tilemap.Tilesets = append(tilemap.Tilesets, tileset.convert())
+	}
+	var err error
+	tilemap.Layers, err = convertLayers(m.Layers)
+	if err != nil {
+		return nil, err
+	}
+	return &tilemap, nil
+}
+
+// Fonction de lecture d'un jeu de tiles au format TSX
+func parseTSX(contents []byte) (*TilesetJSON, error) {
+	var tileset tmxTileset
+	if err := xml.Unmarshal(contents, &tileset); err != nil {
+		return nil, err
+	}
+	converted := tileset.convert()
+	return &converted, nil
+}
+
+// Fonction qui convertit un jeu de tiles XML au format JSON
+func (t tmxTileset) convert() TilesetJSON {
+	tileset := TilesetJSON{
+		FirstGID:    t.FirstGID,
+		Source:      t.Source,
+		Name:        t.Name,
+		Path:        t.Image.Source,
+		ImageWidth:  t.Image.Width,
+		ImageHeight: t.Image.Height,
+		TileWidth:   t.TileWidth,
+		TileHeight:  t.TileHeight,
+		TileCount:   t.TileCount,
+		Columns:     t.Columns,
+		Margin:      t.Margin,
+		Spacing:     t.Spacing,
+		Properties:  t.Properties.convert(),
+	}
+	for _, tile := range t.Tiles {
+		kind := tile.Class
+		if kind == "" {
+			kind = tile.Type
+		}
+		tileset.Tiles = append(tileset.Tiles, TileJSON{
+			Id:         tile.Id,
+			Type:       kind,
+			Path:       tile.Image.Source,
+			Width:      tile.Image.Width,
+			Height:     tile.Image.Height,
+			Properties: tile.Properties.convert(),
+		})
+	}
+	return tileset
+}
+
+// Fonction qui convertit les couches XML au format JSON, en ignorant les balises qui ne sont pas des couches
+func convertLayers(layers []tmxLayer) ([]TilemapLayerJSON, error) {
+	var converted []TilemapLayerJSON
+	for _, layer := range layers {
+		tilemapLayer := TilemapLayerJSON{
+			Name:       layer.Name,
+			Class:      layer.Class,
+			Width:      layer.Width,
+			Height:     layer.Height,
+			Properties: layer.Properties.convert(),
+		}
+
+		switch layer.XMLName.Local {
+		case "layer":
+			tilemapLayer.Type = TileLayer
+			data, err := layer.Data.decode()
+			if err != nil {
+				return nil, fmt.Errorf("couche %q: %v", layer.Name, err)
+			}
+			tilemapLayer.Data = data
+		case "objectgroup":
+			tilemapLayer.Type = ObjectLayer
+			for _, object := range layer.Objects {
+				tilemapObject, err := object.convert()
+				if err != nil {
+					return nil, fmt.Errorf("couche %q: %v", layer.Name, err)
+				}
+				tilemapLayer.Objects = append(tilemapLayer.Objects, tilemapObject)
+			}
+		case "group":
+			tilemapLayer.Type = GroupLayer
+			children, err := convertLayers(layer.Layers)
+			if err != nil {
+				return nil, err
+			}
+			tilemapLayer.Layers = children
+		case "imagelayer":
+			tilemapLayer.Type = ImageLayer
+		default:
+			continue
+		}
+		converted = append(converted, tilemapLayer)
+	}
+	return converted, nil
+}
+
+// Fonction qui décode les identifiants des tiles d'une couche XML
+func (d tmxData) decode() ([]int, error) {
+	if len(d.Chunks) > 0 {
+		return nil, fmt.Errorf("les cartes infinies ne sont pas prises en charge")
+	}
+	if d.Encoding == "" {
+		gids := make([]int, len(d.Tiles))
+		for i, tile := range d.Tiles {
+			gids[i] = int(tile.GID)
+		}
+		return gids, nil
+	}
+	return decodeLayerData(d.Text, d.Encoding, d.Compression)
+}
+
+// Fonction qui convertit un objet XML au format JSON
+func (o tmxObject) convert() (ObjectJSON, error) {
+	object := ObjectJSON{
+		Id:         o.Id,
+		Name:       o.Name,
+		Type:       o.Type,
+		Class:      o.Class,
+		X:          o.X,
+		Y:          o.Y,
+		Width:      o.Width,
+		Height:     o.Height,
+		GID:        int(o.GID),
+		Point:      o.Point != nil,
+		Ellipse:    o.Ellipse != nil,
+		Properties: o.Properties.convert(),
+	}
+	if o.Polygon != nil {
+		// Points au format "x1,y1 x2,y2 ..."
+		for _, pair := range strings.Fields(o.Polygon.Points) {
+			x, y, found := strings.Cut(pair, ",")
+			px, errX := strconv.ParseFloat(x, 64)
+			py, errY := strconv.ParseFloat(y, 64)
+			if !found || errX != nil || errY != nil {
+				return ObjectJSON{}, fmt.Errorf("point de polygone invalide %q dans l'objet %d", pair, o.Id)
+			}
+			object.Polygon = append(object.Polygon, PointJSON{X: px, Y: py})
+		}
+	}
+	return object, nil
+}
@@ -0,0 +1,178 @@
+package trace
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	ag "github.com/Tmegaa/The-Gophecy/pkg/Agent"
+)
+
+// Version du format des traces
+const Version = 1
+
+// En-tête d'une trace: première ligne du fichier
+type Header struct {
+	Version int    `json:"version"`
+	Map     string `json:"map"`  // carte sur laquelle la simulation a été enregistrée
+	Seed    int64  `json:"seed"` // graine de la simulation enregistrée
+	TPS     int    `json:"tps"`  // nombre de ticks par seconde de la simulation enregistrée
+}
+
+// État d'un agent à un tick donné
+type AgentState struct {
+	Id      string  `json:"id"`
+	X       float64 `json:"x"`
+	Y       float64 `json:"y"`
+	Opinion float64 `json:"o"`
+	Type    string  `json:"t"`
+	SubType string  `json:"st,omitempty"`
+	Action  string  `json:"a,omitempty"`
+	Timer   int     `json:"dt,omitempty"` // temps restant de l'action en cours
+	With    string  `json:"w,omitempty"`  // partenaire de discussion
+}
+
+// État d'un ordinateur à un tick donné
+type ComputerState struct {
+	Id      string `json:"id"`
+	Program string `json:"p"`
+	Used    bool   `json:"u,omitempty"`
+}
+
+// Image de la simulation à un tick donné: une ligne du fichier par tick enregistré
+type Frame struct {
+	Tick      int64           `json:"tick"`
+	Agents    []AgentState    `json:"agents"`
+	Computers []ComputerState `json:"computers,omitempty"`
+}
+
+// Fonction qui capture l'état des agents et des ordinateurs à un tick donné
+func Capture(tick int64, agents []*ag.Agent, objs []ag.InterfaceObjet) Frame {
+	frame := Frame{Tick: tick, Agents: make([]AgentState, 0, len(agents))}
+	for _, agent := range agents {
+		state := AgentState{
+			Id:      string(agent.Id),
+			X:       agent.Position.X,
+			Y:       agent.Position.Y,
+			Opinion: agent.Opinion,
+			Type:    string(agent.TypeAgt),
+			SubType: string(agent.SubType),
+			Action:  string(agent.CurrentAction),
+			Timer:   agent.DialogTimer,
+		}
+		if agent.DiscussingWith != nil {
+			state.With = string(agent.DiscussingWith.Id)
+		}
+		frame.Agents = append(frame.Agents, state)
+	}
+	for _, obj := range objs {
+		if obj.GetType() != ag.ComputerType {
+			continue
+		}
+		frame.Computers = append(frame.Computers, ComputerState{
+			Id:      string(obj.ID()),
+			Program: string(obj.GetProgramm()),
+			Used:    obj.GetUse(),
+		})
+	}
+	return frame
+}
+
+// Écrivain de trace au format JSON Lines, compressé avec gzip si le fichier se termine par ".gz"
+type Writer struct {
+	file *os.File
+	gz   *gzip.Writer
+	buf  *bufio.Writer
+	enc  *json.Encoder
+}
+
+// Création d'un fichier de trace et écriture de son en-tête
+func NewWriter(path string, header Header) (*Writer, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("échec de la création de la trace: %v", err)
+	}
+
+	w := &Writer{file: file}
+	var out io.Writer = file
+	if strings.HasSuffix(path, ".gz") {
+		w.gz = gzip.NewWriter(file)
+		out = w.gz
+	}
+	w.buf = bufio.NewWriter(out)
+	w.enc = json.NewEncoder(w.buf)
+
+	header.Version = Version
+	if err := w.enc.Encode(header); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// Fonction qui écrit une image de la simulation
+func (w *Writer) WriteFrame(frame Frame) error {
+	return w.enc.Encode(frame)
+}
+
+// Fonction qui vide les tampons et ferme le fichier de trace
+func (w *Writer) Close() error {
+	if err := w.buf.Flush(); err != nil {
+		w.file.Close()
+		return err
+	}
+	if w.gz != nil {
+		if err := w.gz.Close(); err != nil {
+			w.file.Close()
+			return err
+		}
+	}
+	return w.file.Close()
+}
+
+// Fonction qui lit entièrement un fichier de trace
+func Read(path string) (Header, []Frame, error) {
+	var header Header
+
+	file, err := os.Open(path)
+	if err != nil {
+		return header, nil, fmt.Errorf("échec de la lecture de la trace: %v", err)
+	}
+	defer file.Close()
+
+	var in io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return header, nil, err
+		}
+		defer gz.Close()
+		in = gz
+	}
+
+	dec := json.NewDecoder(bufio.NewReader(in))
+	if err := dec.Decode(&header); err != nil {
+		return header, nil, fmt.Errorf("en-tête de trace invalide: %v", err)
+	}
+	if header.Version != Version {
+		return header, nil, fmt.Errorf("version de trace non supportée: %d (attendue: %d)", header.Version, Version)
+	}
+
+	frames := make([]Frame, 0)
+	for {
+		var frame Frame
+		err := dec.Decode(&frame)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return header, frames, fmt.Errorf("image de trace invalide: %v", err)
+		}
+		frames = append(frames, frame)
+	}
+	return header, frames, nil
+}
@@ -9,13 +9,6 @@ type Position struct {
 	Dy float64
 }
 
-const (
-	AssetsPath             = "assets/images/"
-	AgentBelieverImageFile = "ninja.png"
-	AgentScepticImageFile  = "sceptic.png"
-	AgentNeutralImageFile  = "neutre.png"
-)
-
 type Pair struct {
 	First  float64
 	Second float64
@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"math/rand/v2"
+	"sync"
+)
+
+// Générateur de nombres aléatoires partagé par les agents: il peut être utilisé par plusieurs goroutines
+// et son état peut être sauvegardé puis restauré
+type Rng struct {
+	mutex sync.Mutex
+	src   *rand.PCG
+	rand  *rand.Rand
+}
+
+// Création d'un nouveau générateur à partir d'une graine
+func NewRng(seed uint64) *Rng {
+	src := rand.NewPCG(seed, seed^0x9e3779b97f4a7c15)
+	return &Rng{src: src, rand: rand.New(src)}
+}
+
+// Fonction qui renvoie un flottant aléatoire dans [0, 1[
+func (r *Rng) Float64() float64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.rand.Float64()
+}
+
+// Fonction qui renvoie un entier aléatoire dans [0, n[
+func (r *Rng) IntN(n int) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.rand.IntN(n)
+}
+
+// Fonction qui mélange aléatoirement n éléments à l'aide de la fonction d'échange donnée
+func (r *Rng) Shuffle(n int, swap func(i, j int)) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.rand.Shuffle(n, swap)
+}
+
+// Fonction qui sérialise l'état du générateur
+func (r *Rng) MarshalBinary() ([]byte, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.src.MarshalBinary()
+}
+
+// Fonction qui restaure l'état du générateur
+func (r *Rng) UnmarshalBinary(data []byte) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.src.UnmarshalBinary(data)
+}